@@ -12,7 +12,9 @@ const (
 	MachineOperationKeyName      string = "machine.metal.ironcore.dev/operation"
 	MachineOperationRestart      string = "Restart"
 	MachineOperationForceRestart string = "ForceRestart"
-	MachineOperationForceOff     string = "ForceOff"
+	// MachineOperationForceOff powers a Machine off immediately. It is rejected while the power of the
+	// Machine is On, since the power would be restored right away.
+	MachineOperationForceOff string = "ForceOff"
 )
 
 // MachineSpec defines the desired state of Machine
@@ -22,6 +24,10 @@ type MachineSpec struct {
 
 	OOBRef v1.LocalObjectReference `json:"oobRef"`
 
+	// SystemID identifies the system of an OOB that manages more than one, such as a blade enclosure.
+	// +optional
+	SystemID string `json:"systemID,omitempty"`
+
 	InventoryRef *v1.LocalObjectReference `json:"inventoryRef,omitempty"`
 
 	// +optional
@@ -37,6 +43,11 @@ type MachineSpec struct {
 	// +optional
 	Power Power `json:"power,omitempty"`
 
+	// ShutdownTimeout is the time to wait for a graceful shutdown before powering off immediately.
+	// If unset, the default of the controller is used.
+	// +optional
+	ShutdownTimeout *metav1.Duration `json:"shutdownTimeout,omitempty"`
+
 	// +kubebuilder:validation:Enum=On;Off;Blinking
 	// +optional
 	LocatorLED LocatorLED `json:"locatorLED,omitempty"`
@@ -52,9 +63,9 @@ const (
 type LocatorLED string
 
 const (
-	LocatorLEDOn       Power = "On"
-	LocatorLEDOff      Power = "Off"
-	LocatorLEDBlinking Power = "Blinking"
+	LocatorLEDOn       LocatorLED = "On"
+	LocatorLEDOff      LocatorLED = "Off"
+	LocatorLEDBlinking LocatorLED = "Blinking"
 )
 
 // MachineStatus defines the observed state of Machine
@@ -113,6 +124,23 @@ const (
 	MachineStateError  MachineState = "Error"
 )
 
+const (
+	MachineConditionTypeReady         = "Ready"
+	MachineConditionTypePower         = "Power"
+	MachineConditionTypeLocatorLED    = "LocatorLED"
+	MachineConditionTypeOperation     = "Operation"
+	MachineConditionReasonReady       = "Ready"
+	MachineConditionReasonInProgress  = "InProgress"
+	MachineConditionReasonReconciled  = "Reconciled"
+	MachineConditionReasonCompleted   = "Completed"
+	MachineConditionReasonNoOOB       = "NoOOB"
+	MachineConditionReasonOOBNotReady = "OOBNotReady"
+	MachineConditionReasonUnsupported = "Unsupported"
+	MachineConditionReasonTimeout     = "Timeout"
+	MachineConditionReasonConflict    = "Conflict"
+	MachineConditionReasonError       = "Error"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
@@ -27,6 +27,8 @@ type OOBSpec struct {
 	// +optional
 	Protocol *Protocol `json:"protocol,omitempty"`
 
+	// Flags tune the BMC driver and override the flags of the MAC DB or OOBProfile. Unknown flags
+	// or invalid values put the OOB into the Error state.
 	// +optional
 	Flags map[string]string `json:"flags,omitempty"`
 
@@ -39,6 +41,7 @@ type Protocol struct {
 	Port int32        `json:"port"`
 }
 
+// +kubebuilder:validation:Enum=Redfish;IPMI;SSH
 type ProtocolName string
 
 const (
@@ -78,6 +81,32 @@ type OOBStatus struct {
 	// +optional
 	FirmwareVersion string `json:"firmwareVersion,omitempty"`
 
+	// Flags are the flags of the BMC, resolved from the MAC DB or the OOBProfile and the OOB. Other
+	// controllers use them to connect to the BMC with the same settings as the OOB controller.
+	// +optional
+	Flags map[string]string `json:"flags,omitempty"`
+
+	// TLSFingerprint is the SHA-256 fingerprint of the certificate of the BMC, pinned on first use if
+	// the TLS policy is pin. Remove it to pin a new certificate.
+	// +optional
+	TLSFingerprint string `json:"tlsFingerprint,omitempty"`
+
+	// PendingTLSFingerprint is the SHA-256 fingerprint of a certificate issued for the BMC that the
+	// BMC does not present yet. It is accepted besides TLSFingerprint and replaces it once the BMC
+	// presents the certificate.
+	// +optional
+	PendingTLSFingerprint string `json:"pendingTLSFingerprint,omitempty"`
+
+	// SSHHostKeyFingerprint is the SHA-256 fingerprint of the host key of a BMC accessed over SSH,
+	// pinned on first use. Remove it to pin a new host key.
+	// +optional
+	SSHHostKeyFingerprint string `json:"sshHostKeyFingerprint,omitempty"`
+
+	// CertificateNotAfter is the expiry time of the certificate issued for the BMC, if the flag
+	// redfish.certificate-ca-secret is set. The certificate is renewed before it expires.
+	// +optional
+	CertificateNotAfter *metav1.Time `json:"certificateNotAfter,omitempty"`
+
 	// +kubebuilder:validation:Enum=Ready;Unready;Ignored;Error
 	// +optional
 	State OOBState `json:"state,omitempty"`
@@ -106,11 +135,40 @@ const (
 )
 
 const (
-	OOBConditionTypeReady        = "Ready"
-	OOBConditionReasonInProgress = "InProgress"
-	OOBConditionReasonNoEndpoint = "NoEndpoint"
-	OOBConditionReasonIgnored    = "Ignored"
-	OOBConditionReasonError      = "Error"
+	OOBConditionTypeReady                  = "Ready"
+	OOBConditionReasonReady                = "Ready"
+	OOBConditionReasonInProgress           = "InProgress"
+	OOBConditionReasonRestarting           = "Restarting"
+	OOBConditionReasonNoEndpoint           = "NoEndpoint"
+	OOBConditionReasonNoProtocol           = "NoProtocol"
+	OOBConditionReasonIgnored              = "Ignored"
+	OOBConditionReasonError                = "Error"
+	OOBConditionReasonBadEndpoint          = "BadEndpoint"
+	OOBConditionReasonBadCredentials       = "BadCredentials"
+	OOBConditionReasonBadFlags             = "BadFlags"
+	OOBConditionReasonUnreachable          = "Unreachable"
+	OOBConditionReasonAuthFailed           = "AuthFailed"
+	OOBConditionReasonUnsupportedProtocol  = "UnsupportedProtocol"
+	OOBConditionReasonUnsupportedType      = "UnsupportedType"
+	OOBConditionReasonUntrustedCertificate = "UntrustedCertificate"
+	OOBConditionReasonCertificateChanged   = "CertificateChanged"
+	OOBConditionReasonHostKeyChanged       = "HostKeyChanged"
+	OOBConditionTypeProtocol               = "Protocol"
+	OOBConditionReasonDetected             = "Detected"
+	OOBConditionTypeMachine                = "Machine"
+	OOBConditionReasonOwned                = "Owned"
+	OOBConditionReasonNameConflict         = "NameConflict"
+	OOBConditionReasonUUIDConflict         = "UUIDConflict"
+	OOBConditionTypeCipherSuites           = "CipherSuites"
+	OOBConditionReasonSecure               = "Secure"
+	OOBConditionReasonWeakCipherSuites     = "WeakCipherSuites"
+	OOBConditionTypeCertificate            = "Certificate"
+	OOBConditionReasonIssued               = "Issued"
+	OOBConditionReasonInstalling           = "Installing"
+	OOBConditionReasonNoCertificateService = "NoCertificateService"
+	OOBConditionTypeOperation              = "Operation"
+	OOBConditionReasonCompleted            = "Completed"
+	OOBConditionReasonUnsupported          = "Unsupported"
 )
 
 // +kubebuilder:object:root=true
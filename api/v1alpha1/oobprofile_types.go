@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OOBProfileSpec defines the desired state of OOBProfile
+type OOBProfileSpec struct {
+	// MACPrefixes select the OOBs this profile applies to. If several profiles match an OOB, the one
+	// with the longest matching prefix is used.
+	// +kubebuilder:validation:MinItems=1
+	MACPrefixes []MACPrefix `json:"macPrefixes"`
+
+	// +optional
+	Ignore bool `json:"ignore,omitempty"`
+
+	// +optional
+	Protocol *Protocol `json:"protocol,omitempty"`
+
+	// Flags tune the BMC driver. They are overridden by the flags of the OOB.
+	// +optional
+	Flags map[string]string `json:"flags,omitempty"`
+
+	// DefaultCredentialsSecretRefs refer to Secrets of type kubernetes.io/basic-auth in the system
+	// namespace, which hold the default credentials of the BMCs.
+	// +optional
+	DefaultCredentialsSecretRefs []v1.LocalObjectReference `json:"defaultCredentialsSecretRefs,omitempty"`
+}
+
+// +kubebuilder:validation:Pattern=`^[0-9a-f]{1,12}$`
+type MACPrefix string
+
+// OOBProfileStatus defines the observed state of OOBProfile
+type OOBProfileStatus struct {
+	// +optional
+	MatchedOOBs []string `json:"matchedOOBs,omitempty"`
+
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+}
+
+const (
+	OOBProfileConditionTypeReady        = "Ready"
+	OOBProfileConditionReasonReady      = "Ready"
+	OOBProfileConditionReasonBadSecrets = "BadSecrets"
+	OOBProfileConditionReasonBadFlags   = "BadFlags"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Protocol",type=string,JSONPath=`.spec.protocol.name`
+// +kubebuilder:printcolumn:name="Ignore",type=boolean,JSONPath=`.spec.ignore`,priority=100
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimeStamp`
+// +genclient
+
+// OOBProfile is the Schema for the oobprofiles API
+type OOBProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OOBProfileSpec   `json:"spec,omitempty"`
+	Status OOBProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OOBProfileList contains a list of OOBProfile
+type OOBProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OOBProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OOBProfile{}, &OOBProfileList{})
+}
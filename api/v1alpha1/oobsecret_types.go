@@ -14,7 +14,15 @@ type OOBSecretSpec struct {
 
 	Username string `json:"username"`
 
-	Password string `json:"password"`
+	// Password is the password of the BMC user. Deprecated: the password is moved to the credential
+	// store, see PasswordRef.
+	// +optional
+	Password string `json:"password,omitempty"`
+
+	// PasswordRef references the password of the BMC user in the credential store of the controller.
+	// It takes precedence over Password.
+	// +optional
+	PasswordRef string `json:"passwordRef,omitempty"`
 
 	// +optional
 	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
@@ -22,12 +30,39 @@ type OOBSecretSpec struct {
 
 // OOBSecretStatus defines the observed state of OOBSecret
 type OOBSecretStatus struct {
+	// LastRotationTime is the time the credentials were last rotated.
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// PendingUsername is the BMC user that a rotation in progress creates. Its password is stored
+	// before the user is created, so that a retried rotation can remove the user and create it again.
+	// +optional
+	PendingUsername string `json:"pendingUsername,omitempty"`
+
+	// PendingPasswordRef references the password of PendingUsername in the credential store of the
+	// controller.
+	// +optional
+	PendingPasswordRef string `json:"pendingPasswordRef,omitempty"`
+
+	// RotatedPasswordRef is the PasswordRef whose previous passwords have been deleted from the
+	// credential store.
+	// +optional
+	RotatedPasswordRef string `json:"rotatedPasswordRef,omitempty"`
+
 	// +patchStrategy=merge
 	// +patchMergeKey=type
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 }
 
+const (
+	OOBSecretConditionTypeRotation      = "Rotation"
+	OOBSecretConditionReasonNotExpiring = "NotExpiring"
+	OOBSecretConditionReasonScheduled   = "Scheduled"
+	OOBSecretConditionReasonRotating    = "Rotating"
+	OOBSecretConditionReasonFailed      = "Failed"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
@@ -8,6 +8,7 @@ package v1alpha1
 import (
 	v1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MachineSpecApplyConfiguration represents an declarative configuration of the MachineSpec type for use
@@ -15,11 +16,13 @@ import (
 type MachineSpecApplyConfiguration struct {
 	UUID               *string                  `json:"uuid,omitempty"`
 	OOBRef             *v1.LocalObjectReference `json:"oobRef,omitempty"`
+	SystemID           *string                  `json:"systemID,omitempty"`
 	InventoryRef       *v1.LocalObjectReference `json:"inventoryRef,omitempty"`
 	MachineClaimRef    *v1.ObjectReference      `json:"machineClaimRef,omitempty"`
 	LoopbackAddressRef *v1.LocalObjectReference `json:"loopbackAddressRef,omitempty"`
 	ASN                *string                  `json:"asn,omitempty"`
 	Power              *v1alpha1.Power          `json:"power,omitempty"`
+	ShutdownTimeout    *metav1.Duration         `json:"shutdownTimeout,omitempty"`
 	LocatorLED         *v1alpha1.LocatorLED     `json:"locatorLED,omitempty"`
 }
 
@@ -45,6 +48,14 @@ func (b *MachineSpecApplyConfiguration) WithOOBRef(value v1.LocalObjectReference
 	return b
 }
 
+// WithSystemID sets the SystemID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SystemID field is set to the value of the last call.
+func (b *MachineSpecApplyConfiguration) WithSystemID(value string) *MachineSpecApplyConfiguration {
+	b.SystemID = &value
+	return b
+}
+
 // WithInventoryRef sets the InventoryRef field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the InventoryRef field is set to the value of the last call.
@@ -85,6 +96,14 @@ func (b *MachineSpecApplyConfiguration) WithPower(value v1alpha1.Power) *Machine
 	return b
 }
 
+// WithShutdownTimeout sets the ShutdownTimeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ShutdownTimeout field is set to the value of the last call.
+func (b *MachineSpecApplyConfiguration) WithShutdownTimeout(value metav1.Duration) *MachineSpecApplyConfiguration {
+	b.ShutdownTimeout = &value
+	return b
+}
+
 // WithLocatorLED sets the LocatorLED field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the LocatorLED field is set to the value of the last call.
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// OOBProfileSpecApplyConfiguration represents an declarative configuration of the OOBProfileSpec type for use
+// with apply.
+type OOBProfileSpecApplyConfiguration struct {
+	MACPrefixes                  []v1alpha1.MACPrefix        `json:"macPrefixes,omitempty"`
+	Ignore                       *bool                       `json:"ignore,omitempty"`
+	Protocol                     *ProtocolApplyConfiguration `json:"protocol,omitempty"`
+	Flags                        map[string]string           `json:"flags,omitempty"`
+	DefaultCredentialsSecretRefs []v1.LocalObjectReference   `json:"defaultCredentialsSecretRefs,omitempty"`
+}
+
+// OOBProfileSpecApplyConfiguration constructs an declarative configuration of the OOBProfileSpec type for use with
+// apply.
+func OOBProfileSpec() *OOBProfileSpecApplyConfiguration {
+	return &OOBProfileSpecApplyConfiguration{}
+}
+
+// WithMACPrefixes adds the given value to the MACPrefixes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the MACPrefixes field.
+func (b *OOBProfileSpecApplyConfiguration) WithMACPrefixes(values ...v1alpha1.MACPrefix) *OOBProfileSpecApplyConfiguration {
+	for i := range values {
+		b.MACPrefixes = append(b.MACPrefixes, values[i])
+	}
+	return b
+}
+
+// WithIgnore sets the Ignore field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ignore field is set to the value of the last call.
+func (b *OOBProfileSpecApplyConfiguration) WithIgnore(value bool) *OOBProfileSpecApplyConfiguration {
+	b.Ignore = &value
+	return b
+}
+
+// WithProtocol sets the Protocol field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Protocol field is set to the value of the last call.
+func (b *OOBProfileSpecApplyConfiguration) WithProtocol(value *ProtocolApplyConfiguration) *OOBProfileSpecApplyConfiguration {
+	b.Protocol = value
+	return b
+}
+
+// WithFlags puts the entries into the Flags field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Flags field,
+// overwriting an existing map entries in Flags field with the same key.
+func (b *OOBProfileSpecApplyConfiguration) WithFlags(entries map[string]string) *OOBProfileSpecApplyConfiguration {
+	if b.Flags == nil && len(entries) > 0 {
+		b.Flags = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Flags[k] = v
+	}
+	return b
+}
+
+// WithDefaultCredentialsSecretRefs adds the given value to the DefaultCredentialsSecretRefs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the DefaultCredentialsSecretRefs field.
+func (b *OOBProfileSpecApplyConfiguration) WithDefaultCredentialsSecretRefs(values ...v1.LocalObjectReference) *OOBProfileSpecApplyConfiguration {
+	for i := range values {
+		b.DefaultCredentialsSecretRefs = append(b.DefaultCredentialsSecretRefs, values[i])
+	}
+	return b
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OOBProfileStatusApplyConfiguration represents an declarative configuration of the OOBProfileStatus type for use
+// with apply.
+type OOBProfileStatusApplyConfiguration struct {
+	MatchedOOBs []string       `json:"matchedOOBs,omitempty"`
+	Conditions  []v1.Condition `json:"conditions,omitempty"`
+}
+
+// OOBProfileStatusApplyConfiguration constructs an declarative configuration of the OOBProfileStatus type for use with
+// apply.
+func OOBProfileStatus() *OOBProfileStatusApplyConfiguration {
+	return &OOBProfileStatusApplyConfiguration{}
+}
+
+// WithMatchedOOBs adds the given value to the MatchedOOBs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the MatchedOOBs field.
+func (b *OOBProfileStatusApplyConfiguration) WithMatchedOOBs(values ...string) *OOBProfileStatusApplyConfiguration {
+	for i := range values {
+		b.MatchedOOBs = append(b.MatchedOOBs, values[i])
+	}
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *OOBProfileStatusApplyConfiguration) WithConditions(values ...v1.Condition) *OOBProfileStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
@@ -15,6 +15,7 @@ type OOBSecretSpecApplyConfiguration struct {
 	MACAddress     *string  `json:"macAddress,omitempty"`
 	Username       *string  `json:"username,omitempty"`
 	Password       *string  `json:"password,omitempty"`
+	PasswordRef    *string  `json:"passwordRef,omitempty"`
 	ExpirationTime *v1.Time `json:"expirationTime,omitempty"`
 }
 
@@ -48,6 +49,14 @@ func (b *OOBSecretSpecApplyConfiguration) WithPassword(value string) *OOBSecretS
 	return b
 }
 
+// WithPasswordRef sets the PasswordRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PasswordRef field is set to the value of the last call.
+func (b *OOBSecretSpecApplyConfiguration) WithPasswordRef(value string) *OOBSecretSpecApplyConfiguration {
+	b.PasswordRef = &value
+	return b
+}
+
 // WithExpirationTime sets the ExpirationTime field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the ExpirationTime field is set to the value of the last call.
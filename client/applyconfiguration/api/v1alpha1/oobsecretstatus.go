@@ -12,7 +12,11 @@ import (
 // OOBSecretStatusApplyConfiguration represents an declarative configuration of the OOBSecretStatus type for use
 // with apply.
 type OOBSecretStatusApplyConfiguration struct {
-	Conditions []v1.Condition `json:"conditions,omitempty"`
+	LastRotationTime   *v1.Time       `json:"lastRotationTime,omitempty"`
+	PendingUsername    *string        `json:"pendingUsername,omitempty"`
+	PendingPasswordRef *string        `json:"pendingPasswordRef,omitempty"`
+	RotatedPasswordRef *string        `json:"rotatedPasswordRef,omitempty"`
+	Conditions         []v1.Condition `json:"conditions,omitempty"`
 }
 
 // OOBSecretStatusApplyConfiguration constructs an declarative configuration of the OOBSecretStatus type for use with
@@ -21,6 +25,38 @@ func OOBSecretStatus() *OOBSecretStatusApplyConfiguration {
 	return &OOBSecretStatusApplyConfiguration{}
 }
 
+// WithLastRotationTime sets the LastRotationTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastRotationTime field is set to the value of the last call.
+func (b *OOBSecretStatusApplyConfiguration) WithLastRotationTime(value v1.Time) *OOBSecretStatusApplyConfiguration {
+	b.LastRotationTime = &value
+	return b
+}
+
+// WithPendingUsername sets the PendingUsername field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PendingUsername field is set to the value of the last call.
+func (b *OOBSecretStatusApplyConfiguration) WithPendingUsername(value string) *OOBSecretStatusApplyConfiguration {
+	b.PendingUsername = &value
+	return b
+}
+
+// WithPendingPasswordRef sets the PendingPasswordRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PendingPasswordRef field is set to the value of the last call.
+func (b *OOBSecretStatusApplyConfiguration) WithPendingPasswordRef(value string) *OOBSecretStatusApplyConfiguration {
+	b.PendingPasswordRef = &value
+	return b
+}
+
+// WithRotatedPasswordRef sets the RotatedPasswordRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RotatedPasswordRef field is set to the value of the last call.
+func (b *OOBSecretStatusApplyConfiguration) WithRotatedPasswordRef(value string) *OOBSecretStatusApplyConfiguration {
+	b.RotatedPasswordRef = &value
+	return b
+}
+
 // WithConditions adds the given value to the Conditions field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, values provided by each call will be appended to the Conditions field.
@@ -13,13 +13,18 @@ import (
 // OOBStatusApplyConfiguration represents an declarative configuration of the OOBStatus type for use
 // with apply.
 type OOBStatusApplyConfiguration struct {
-	Type            *v1alpha1.OOBType  `json:"type,omitempty"`
-	Manufacturer    *string            `json:"manufacturer,omitempty"`
-	SKU             *string            `json:"sku,omitempty"`
-	SerialNumber    *string            `json:"serialNumber,omitempty"`
-	FirmwareVersion *string            `json:"firmwareVersion,omitempty"`
-	State           *v1alpha1.OOBState `json:"state,omitempty"`
-	Conditions      []v1.Condition     `json:"conditions,omitempty"`
+	Type                  *v1alpha1.OOBType  `json:"type,omitempty"`
+	Manufacturer          *string            `json:"manufacturer,omitempty"`
+	SKU                   *string            `json:"sku,omitempty"`
+	SerialNumber          *string            `json:"serialNumber,omitempty"`
+	FirmwareVersion       *string            `json:"firmwareVersion,omitempty"`
+	Flags                 map[string]string  `json:"flags,omitempty"`
+	TLSFingerprint        *string            `json:"tlsFingerprint,omitempty"`
+	PendingTLSFingerprint *string            `json:"pendingTLSFingerprint,omitempty"`
+	SSHHostKeyFingerprint *string            `json:"sshHostKeyFingerprint,omitempty"`
+	CertificateNotAfter   *v1.Time           `json:"certificateNotAfter,omitempty"`
+	State                 *v1alpha1.OOBState `json:"state,omitempty"`
+	Conditions            []v1.Condition     `json:"conditions,omitempty"`
 }
 
 // OOBStatusApplyConfiguration constructs an declarative configuration of the OOBStatus type for use with
@@ -68,6 +73,52 @@ func (b *OOBStatusApplyConfiguration) WithFirmwareVersion(value string) *OOBStat
 	return b
 }
 
+// WithFlags puts the entries into the Flags field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Flags field,
+// overwriting an existing map entries in Flags field with the same key.
+func (b *OOBStatusApplyConfiguration) WithFlags(entries map[string]string) *OOBStatusApplyConfiguration {
+	if b.Flags == nil && len(entries) > 0 {
+		b.Flags = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Flags[k] = v
+	}
+	return b
+}
+
+// WithTLSFingerprint sets the TLSFingerprint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TLSFingerprint field is set to the value of the last call.
+func (b *OOBStatusApplyConfiguration) WithTLSFingerprint(value string) *OOBStatusApplyConfiguration {
+	b.TLSFingerprint = &value
+	return b
+}
+
+// WithPendingTLSFingerprint sets the PendingTLSFingerprint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PendingTLSFingerprint field is set to the value of the last call.
+func (b *OOBStatusApplyConfiguration) WithPendingTLSFingerprint(value string) *OOBStatusApplyConfiguration {
+	b.PendingTLSFingerprint = &value
+	return b
+}
+
+// WithSSHHostKeyFingerprint sets the SSHHostKeyFingerprint field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SSHHostKeyFingerprint field is set to the value of the last call.
+func (b *OOBStatusApplyConfiguration) WithSSHHostKeyFingerprint(value string) *OOBStatusApplyConfiguration {
+	b.SSHHostKeyFingerprint = &value
+	return b
+}
+
+// WithCertificateNotAfter sets the CertificateNotAfter field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CertificateNotAfter field is set to the value of the last call.
+func (b *OOBStatusApplyConfiguration) WithCertificateNotAfter(value v1.Time) *OOBStatusApplyConfiguration {
+	b.CertificateNotAfter = &value
+	return b
+}
+
 // WithState sets the State field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the State field is set to the value of the last call.
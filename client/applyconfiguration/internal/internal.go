@@ -163,6 +163,12 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: power
       type:
         scalar: string
+    - name: shutdownTimeout
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Duration
+    - name: systemID
+      type:
+        scalar: string
     - name: uuid
       type:
         scalar: string
@@ -226,6 +232,70 @@ var schemaYAML = typed.YAMLObject(`types:
       type:
         namedType: com.github.ironcore-dev.metal.api.v1alpha1.OOBStatus
       default: {}
+- name: com.github.ironcore-dev.metal.api.v1alpha1.OOBProfile
+  map:
+    fields:
+    - name: apiVersion
+      type:
+        scalar: string
+    - name: kind
+      type:
+        scalar: string
+    - name: metadata
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta
+      default: {}
+    - name: spec
+      type:
+        namedType: com.github.ironcore-dev.metal.api.v1alpha1.OOBProfileSpec
+      default: {}
+    - name: status
+      type:
+        namedType: com.github.ironcore-dev.metal.api.v1alpha1.OOBProfileStatus
+      default: {}
+- name: com.github.ironcore-dev.metal.api.v1alpha1.OOBProfileSpec
+  map:
+    fields:
+    - name: defaultCredentialsSecretRefs
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.api.core.v1.LocalObjectReference
+          elementRelationship: atomic
+    - name: flags
+      type:
+        map:
+          elementType:
+            scalar: string
+    - name: ignore
+      type:
+        scalar: boolean
+    - name: macPrefixes
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
+    - name: protocol
+      type:
+        namedType: com.github.ironcore-dev.metal.api.v1alpha1.Protocol
+- name: com.github.ironcore-dev.metal.api.v1alpha1.OOBProfileStatus
+  map:
+    fields:
+    - name: conditions
+      type:
+        list:
+          elementType:
+            namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Condition
+          elementRelationship: associative
+          keys:
+          - type
+    - name: matchedOOBs
+      type:
+        list:
+          elementType:
+            scalar: string
+          elementRelationship: atomic
 - name: com.github.ironcore-dev.metal.api.v1alpha1.OOBSecret
   map:
     fields:
@@ -260,7 +330,9 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: password
       type:
         scalar: string
-      default: ""
+    - name: passwordRef
+      type:
+        scalar: string
     - name: username
       type:
         scalar: string
@@ -276,6 +348,18 @@ var schemaYAML = typed.YAMLObject(`types:
           elementRelationship: associative
           keys:
           - type
+    - name: lastRotationTime
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
+    - name: pendingPasswordRef
+      type:
+        scalar: string
+    - name: pendingUsername
+      type:
+        scalar: string
+    - name: rotatedPasswordRef
+      type:
+        scalar: string
 - name: com.github.ironcore-dev.metal.api.v1alpha1.OOBSpec
   map:
     fields:
@@ -303,6 +387,9 @@ var schemaYAML = typed.YAMLObject(`types:
 - name: com.github.ironcore-dev.metal.api.v1alpha1.OOBStatus
   map:
     fields:
+    - name: certificateNotAfter
+      type:
+        namedType: io.k8s.apimachinery.pkg.apis.meta.v1.Time
     - name: conditions
       type:
         list:
@@ -314,18 +401,32 @@ var schemaYAML = typed.YAMLObject(`types:
     - name: firmwareVersion
       type:
         scalar: string
+    - name: flags
+      type:
+        map:
+          elementType:
+            scalar: string
     - name: manufacturer
       type:
         scalar: string
+    - name: pendingTLSFingerprint
+      type:
+        scalar: string
     - name: serialNumber
       type:
         scalar: string
     - name: sku
       type:
         scalar: string
+    - name: sshHostKeyFingerprint
+      type:
+        scalar: string
     - name: state
       type:
         scalar: string
+    - name: tlsFingerprint
+      type:
+        scalar: string
     - name: type
       type:
         scalar: string
@@ -399,6 +500,8 @@ var schemaYAML = typed.YAMLObject(`types:
       type:
         scalar: string
       default: ""
+- name: io.k8s.apimachinery.pkg.apis.meta.v1.Duration
+  scalar: string
 - name: io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1
   map:
     elementType:
@@ -36,6 +36,12 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &apiv1alpha1.MachineStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("OOB"):
 		return &apiv1alpha1.OOBApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OOBProfile"):
+		return &apiv1alpha1.OOBProfileApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OOBProfileSpec"):
+		return &apiv1alpha1.OOBProfileSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("OOBProfileStatus"):
+		return &apiv1alpha1.OOBProfileStatusApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("OOBSecret"):
 		return &apiv1alpha1.OOBSecretApplyConfiguration{}
 	case v1alpha1.SchemeGroupVersion.WithKind("OOBSecretSpec"):
@@ -33,6 +33,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/ironcore-dev/metal/api/v1alpha1.MachineStatus":                schema_ironcore_dev_metal_api_v1alpha1_MachineStatus(ref),
 		"github.com/ironcore-dev/metal/api/v1alpha1.OOB":                          schema_ironcore_dev_metal_api_v1alpha1_OOB(ref),
 		"github.com/ironcore-dev/metal/api/v1alpha1.OOBList":                      schema_ironcore_dev_metal_api_v1alpha1_OOBList(ref),
+		"github.com/ironcore-dev/metal/api/v1alpha1.OOBProfile":                   schema_ironcore_dev_metal_api_v1alpha1_OOBProfile(ref),
+		"github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileList":               schema_ironcore_dev_metal_api_v1alpha1_OOBProfileList(ref),
+		"github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileSpec":               schema_ironcore_dev_metal_api_v1alpha1_OOBProfileSpec(ref),
+		"github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileStatus":             schema_ironcore_dev_metal_api_v1alpha1_OOBProfileStatus(ref),
 		"github.com/ironcore-dev/metal/api/v1alpha1.OOBSecret":                    schema_ironcore_dev_metal_api_v1alpha1_OOBSecret(ref),
 		"github.com/ironcore-dev/metal/api/v1alpha1.OOBSecretList":                schema_ironcore_dev_metal_api_v1alpha1_OOBSecretList(ref),
 		"github.com/ironcore-dev/metal/api/v1alpha1.OOBSecretSpec":                schema_ironcore_dev_metal_api_v1alpha1_OOBSecretSpec(ref),
@@ -697,6 +701,13 @@ func schema_ironcore_dev_metal_api_v1alpha1_MachineSpec(ref common.ReferenceCall
 							Ref:     ref("k8s.io/api/core/v1.LocalObjectReference"),
 						},
 					},
+					"systemID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SystemID identifies the system of an OOB that manages more than one, such as a blade enclosure.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"inventoryRef": {
 						SchemaProps: spec.SchemaProps{
 							Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
@@ -724,6 +735,12 @@ func schema_ironcore_dev_metal_api_v1alpha1_MachineSpec(ref common.ReferenceCall
 							Format: "",
 						},
 					},
+					"shutdownTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ShutdownTimeout is the time to wait for a graceful shutdown before powering off immediately. If unset, the default of the controller is used.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 					"locatorLED": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
@@ -735,7 +752,7 @@ func schema_ironcore_dev_metal_api_v1alpha1_MachineSpec(ref common.ReferenceCall
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.ObjectReference"},
+			"k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.ObjectReference", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -923,6 +940,222 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBList(ref common.ReferenceCallback
 	}
 }
 
+func schema_ironcore_dev_metal_api_v1alpha1_OOBProfile(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OOBProfile is the Schema for the oobprofiles API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileSpec", "github.com/ironcore-dev/metal/api/v1alpha1.OOBProfileStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_ironcore_dev_metal_api_v1alpha1_OOBProfileList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OOBProfileList contains a list of OOBProfile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/ironcore-dev/metal/api/v1alpha1.OOBProfile"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ironcore-dev/metal/api/v1alpha1.OOBProfile", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_ironcore_dev_metal_api_v1alpha1_OOBProfileSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OOBProfileSpec defines the desired state of OOBProfile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"macPrefixes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MACPrefixes select the OOBs this profile applies to. If several profiles match an OOB, the one with the longest matching prefix is used.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"ignore": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"protocol": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/ironcore-dev/metal/api/v1alpha1.Protocol"),
+						},
+					},
+					"flags": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flags tune the BMC driver. They are overridden by the flags of the OOB.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"defaultCredentialsSecretRefs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultCredentialsSecretRefs refer to Secrets of type kubernetes.io/basic-auth in the system namespace, which hold the default credentials of the BMCs.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"macPrefixes"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ironcore-dev/metal/api/v1alpha1.Protocol", "k8s.io/api/core/v1.LocalObjectReference"},
+	}
+}
+
+func schema_ironcore_dev_metal_api_v1alpha1_OOBProfileStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OOBProfileStatus defines the observed state of OOBProfile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"matchedOOBs": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-patch-merge-key": "type",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Condition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition"},
+	}
+}
+
 func schema_ironcore_dev_metal_api_v1alpha1_OOBSecret(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1042,9 +1275,16 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBSecretSpec(ref common.ReferenceCa
 					},
 					"password": {
 						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+							Description: "Password is the password of the BMC user. Deprecated: the password is moved to the credential store, see PasswordRef.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordRef references the password of the BMC user in the credential store of the controller. It takes precedence over Password.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 					"expirationTime": {
@@ -1053,7 +1293,7 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBSecretSpec(ref common.ReferenceCa
 						},
 					},
 				},
-				Required: []string{"macAddress", "username", "password"},
+				Required: []string{"macAddress", "username"},
 			},
 		},
 		Dependencies: []string{
@@ -1068,6 +1308,33 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBSecretStatus(ref common.Reference
 				Description: "OOBSecretStatus defines the observed state of OOBSecret",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
+					"lastRotationTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastRotationTime is the time the credentials were last rotated.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"pendingUsername": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PendingUsername is the BMC user that a rotation in progress creates. Its password is stored before the user is created, so that a retried rotation can remove the user and create it again.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pendingPasswordRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PendingPasswordRef references the password of PendingUsername in the credential store of the controller.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rotatedPasswordRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RotatedPasswordRef is the PasswordRef whose previous passwords have been deleted from the credential store.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"conditions": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -1091,7 +1358,7 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBSecretStatus(ref common.Reference
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -1126,7 +1393,8 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBSpec(ref common.ReferenceCallback
 					},
 					"flags": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"object"},
+							Description: "Flags tune the BMC driver and override the flags of the MAC DB or OOBProfile. Unknown flags or invalid values put the OOB into the Error state.",
+							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
@@ -1190,6 +1458,49 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBStatus(ref common.ReferenceCallba
 							Format: "",
 						},
 					},
+					"flags": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flags are the flags of the BMC, resolved from the MAC DB or the OOBProfile and the OOB. Other controllers use them to connect to the BMC with the same settings as the OOB controller.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"tlsFingerprint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLSFingerprint is the SHA-256 fingerprint of the certificate of the BMC, pinned on first use if the TLS policy is pin. Remove it to pin a new certificate.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pendingTLSFingerprint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PendingTLSFingerprint is the SHA-256 fingerprint of a certificate issued for the BMC that the BMC does not present yet. It is accepted besides TLSFingerprint and replaces it once the BMC presents the certificate.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sshHostKeyFingerprint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SSHHostKeyFingerprint is the SHA-256 fingerprint of the host key of a BMC accessed over SSH, pinned on first use. Remove it to pin a new host key.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"certificateNotAfter": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CertificateNotAfter is the expiry time of the certificate issued for the BMC, if the flag redfish.certificate-ca-secret is set. The certificate is renewed before it expires.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 					"state": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
@@ -1219,7 +1530,7 @@ func schema_ironcore_dev_metal_api_v1alpha1_OOBStatus(ref common.ReferenceCallba
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-logr/logr"
 	ipamv1alpha1 "github.com/ironcore-dev/ipam/api/ipam/v1alpha1"
@@ -25,11 +26,14 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/bmc"
 	"github.com/ironcore-dev/metal/internal/controller"
+	"github.com/ironcore-dev/metal/internal/credstore"
 	"github.com/ironcore-dev/metal/internal/log"
 	"github.com/ironcore-dev/metal/internal/namespace"
 	//+kubebuilder:scaffold:imports
@@ -45,13 +49,24 @@ type params struct {
 	kubeconfig                   string
 	systemNamespace              string
 	enableMachineController      bool
+	machineShutdownTimeout       time.Duration
 	enableMachineClaimController bool
 	enableOOBController          bool
 	oobIpLabelSelector           string
 	oobMacDB                     string
+	oobMacDBConfigMap            string
+	oobMacDBSecret               string
 	oobUsernamePrefix            string
 	oobTemporaryPasswordSecret   string
+	oobResyncInterval            time.Duration
+	oobDetectionTimeout          time.Duration
+	oobCredentialStore           string
+	oobCredentialStoreSealKey    string
+	redfishSessionCacheSize      int
+	redfishSessionIdleTimeout    time.Duration
 	enableOOBSecretController    bool
+	oobSecretRotationWindow      time.Duration
+	enableOOBProfileController   bool
 }
 
 func parseCmdLine() params {
@@ -68,13 +83,24 @@ func parseCmdLine() params {
 	pflag.String("kubeconfig", "", "Use a kubeconfig to run out of cluster.")
 	pflag.String("system-namespace", "", "Use a specific namespace for controller state. If blank, use the in-cluster namespace. Required if running out of cluster.")
 	pflag.Bool("enable-machine-controller", true, "Enable the Machine controller.")
+	pflag.Duration("machine-shutdown-timeout", 5*time.Minute, "Machine: Wait this long for a graceful shutdown before powering off immediately. Can be overridden per Machine.")
 	pflag.Bool("enable-machineclaim-controller", true, "Enable the MachineClaim controller.")
 	pflag.Bool("enable-oob-controller", true, "Enable the OOB controller.")
 	pflag.String("oob-ip-label-selector", "", "OOB: Filter IP objects by labels.")
 	pflag.String("oob-mac-db", "", "OOB: Load MAC DB from file.")
+	pflag.String("oob-mac-db-configmap", "", "OOB: Load MAC DB from a ConfigMap in the system namespace and reload it on changes.")
+	pflag.String("oob-mac-db-secret", "", "OOB: Load MAC DB from a Secret in the system namespace and reload it on changes.")
 	pflag.String("oob-username-prefix", "metal-", "OOB: Use a prefix when creating BMC users. Cannot be empty.")
 	pflag.String("oob-temporary-password-secret", "bmc-temporary-password", "OOB: Secret to store a temporary password in. Will be generated if it does not exist.")
+	pflag.Duration("oob-protocol-detection-timeout", 5*time.Second, "OOB: Probe BMCs without a configured protocol, waiting this long for each protocol. Zero disables detection.")
+	pflag.Duration("oob-resync-interval", 10*time.Minute, "OOB: Refresh ready OOBs at this interval. Also limits the backoff for OOBs that are not ready.")
+	pflag.String("oob-credential-store", "secret", "OOB: Store BMC passwords in Secrets in the system namespace (secret), in files in a directory shared by all replicas (file:///path), or in a Vault KV version 2 secrets engine (vault+https://host:port/mount/prefix?tokenFile=/path&caFile=/path).")
+	pflag.String("oob-credential-store-seal-key", "", "OOB: Encrypt BMC passwords with the 32-byte key in this file before storing them.")
+	pflag.Int("redfish-session-cache-size", bmc.DefaultRedfishSessionCacheSize, "Keep this many Redfish sessions open to reuse them across reconciles. Zero disables the cache.")
+	pflag.Duration("redfish-session-idle-timeout", bmc.DefaultRedfishSessionIdleTimeout, "Log out of cached Redfish sessions that have not been used for this long.")
 	pflag.Bool("enable-oobsecret-controller", true, "Enable the OOBSecret controller.")
+	pflag.Duration("oobsecret-rotation-window", 7*24*time.Hour, "OOBSecret: Rotate expiring BMC credentials this long before they expire, but not before half of their lifetime.")
+	pflag.Bool("enable-oobprofile-controller", true, "Enable the OOBProfile controller.")
 
 	var help bool
 	pflag.BoolVarP(&help, "help", "h", false, "Show this help message.")
@@ -102,13 +128,24 @@ func parseCmdLine() params {
 		kubeconfig:                   viper.GetString("kubeconfig"),
 		systemNamespace:              viper.GetString("system-namespace"),
 		enableMachineController:      viper.GetBool("enable-machine-controller"),
+		machineShutdownTimeout:       viper.GetDuration("machine-shutdown-timeout"),
 		enableMachineClaimController: viper.GetBool("enable-machineclaim-controller"),
 		enableOOBController:          viper.GetBool("enable-oob-controller"),
 		oobIpLabelSelector:           viper.GetString("oob-ip-label-selector"),
 		oobMacDB:                     viper.GetString("oob-mac-db"),
+		oobMacDBConfigMap:            viper.GetString("oob-mac-db-configmap"),
+		oobMacDBSecret:               viper.GetString("oob-mac-db-secret"),
 		oobUsernamePrefix:            viper.GetString("oob-username-prefix"),
 		oobTemporaryPasswordSecret:   viper.GetString("oob-temporary-password-secret"),
+		oobResyncInterval:            viper.GetDuration("oob-resync-interval"),
+		oobDetectionTimeout:          viper.GetDuration("oob-protocol-detection-timeout"),
+		oobCredentialStore:           viper.GetString("oob-credential-store"),
+		oobCredentialStoreSealKey:    viper.GetString("oob-credential-store-seal-key"),
+		redfishSessionCacheSize:      viper.GetInt("redfish-session-cache-size"),
+		redfishSessionIdleTimeout:    viper.GetDuration("redfish-session-idle-timeout"),
 		enableOOBSecretController:    viper.GetBool("enable-oobsecret-controller"),
+		oobSecretRotationWindow:      viper.GetDuration("oobsecret-rotation-window"),
+		enableOOBProfileController:   viper.GetBool("enable-oobprofile-controller"),
 	}
 }
 
@@ -216,6 +253,16 @@ func main() {
 		return
 	}
 
+	bmc.ConfigureRedfishSessionCache(p.redfishSessionCacheSize, p.redfishSessionIdleTimeout)
+	for _, c := range bmc.Collectors() {
+		err = metrics.Registry.Register(c)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot register metrics: %w", err))
+			exitCode = 1
+			return
+		}
+	}
+
 	err = controller.CreateIndexes(ctx, mgr)
 	if err != nil {
 		log.Error(ctx, fmt.Errorf("cannot create indexes: %w", err))
@@ -223,9 +270,32 @@ func main() {
 		return
 	}
 
+	var store credstore.Store
+	store, err = credstore.Open(p.oobCredentialStore, mgr.GetClient(), p.systemNamespace)
+	if err != nil {
+		log.Error(ctx, fmt.Errorf("cannot open credential store: %w", err))
+		exitCode = 1
+		return
+	}
+	if p.oobCredentialStoreSealKey != "" {
+		var key []byte
+		key, err = os.ReadFile(p.oobCredentialStoreSealKey)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot read credential store seal key: %w", err))
+			exitCode = 1
+			return
+		}
+		store, err = credstore.Seal(store, key)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot open credential store: %w", err))
+			exitCode = 1
+			return
+		}
+	}
+
 	if p.enableMachineController {
 		var machineReconciler *controller.MachineReconciler
-		machineReconciler, err = controller.NewMachineReconciler()
+		machineReconciler, err = controller.NewMachineReconciler(p.systemNamespace, p.machineShutdownTimeout, store)
 		if err != nil {
 			log.Error(ctx, fmt.Errorf("cannot create controller: %w", err), "controller", "Machine")
 			exitCode = 1
@@ -259,7 +329,7 @@ func main() {
 
 	if p.enableOOBController {
 		var oobReconciler *controller.OOBReconciler
-		oobReconciler, err = controller.NewOOBReconciler(p.systemNamespace, p.oobIpLabelSelector, p.oobMacDB, p.oobUsernamePrefix, p.oobTemporaryPasswordSecret)
+		oobReconciler, err = controller.NewOOBReconciler(p.systemNamespace, p.oobIpLabelSelector, p.oobMacDB, p.oobMacDBConfigMap, p.oobMacDBSecret, p.oobUsernamePrefix, p.oobTemporaryPasswordSecret, p.oobResyncInterval, p.oobDetectionTimeout, store)
 		if err != nil {
 			log.Error(ctx, fmt.Errorf("cannot create controller: %w", err), "controller", "OOB")
 			exitCode = 1
@@ -276,7 +346,7 @@ func main() {
 
 	if p.enableOOBSecretController {
 		var oobSecretReconciler *controller.OOBSecretReconciler
-		oobSecretReconciler, err = controller.NewOOBSecretReconciler()
+		oobSecretReconciler, err = controller.NewOOBSecretReconciler(p.systemNamespace, p.oobUsernamePrefix, p.oobTemporaryPasswordSecret, p.oobSecretRotationWindow, store)
 		if err != nil {
 			log.Error(ctx, fmt.Errorf("cannot create controller: %w", err), "controller", "OOBSecret")
 			exitCode = 1
@@ -291,6 +361,23 @@ func main() {
 		}
 	}
 
+	if p.enableOOBProfileController {
+		var oobProfileReconciler *controller.OOBProfileReconciler
+		oobProfileReconciler, err = controller.NewOOBProfileReconciler(p.systemNamespace)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot create controller: %w", err), "controller", "OOBProfile")
+			exitCode = 1
+			return
+		}
+
+		err = oobProfileReconciler.SetupWithManager(mgr)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot create controller: %w", err), "controller", "OOBProfile")
+			exitCode = 1
+			return
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	err = mgr.AddHealthzCheck("health", healthz.Ping)
@@ -5,8 +5,12 @@ package bmc
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ironcore-dev/metal/internal/log"
@@ -36,6 +40,76 @@ type ResetControl interface {
 	Reset(ctx context.Context, immediate bool) error
 }
 
+type ManagerResetControl interface {
+	ResetManager(ctx context.Context) error
+}
+
+// SystemSelector is implemented by BMCs that can manage more than one system, such as blade
+// enclosures. Info, power, reset and LED operations act on the selected system, or on the first
+// system if none is selected.
+type SystemSelector interface {
+	SelectSystem(id string)
+}
+
+// TLSControl is implemented by BMCs that are accessed over TLS. The certificate of the BMC is
+// verified according to the TLS policy of the flags, see RedfishTLSPolicy.
+type TLSControl interface {
+	SetTLSTrust(trust TLSTrust)
+	// CertificateFingerprint returns the fingerprint of the certificate the BMC presents, without
+	// verifying it.
+	CertificateFingerprint(ctx context.Context) (string, error)
+}
+
+// TLSTrust holds what the TLS policy needs to verify the certificate of a BMC.
+type TLSTrust struct {
+	// CA holds the PEM encoded CA certificates of the ca policy.
+	CA []byte
+	// Fingerprint is the pinned fingerprint of the pin policy, see CertificateFingerprint.
+	Fingerprint string
+	// PendingFingerprint is the fingerprint of a certificate that is being installed on the BMC. The
+	// pin policy accepts it besides Fingerprint.
+	PendingFingerprint string
+}
+
+// HostKeyControl is implemented by BMCs that are accessed over SSH. The host key of the BMC must
+// match the pinned fingerprint.
+type HostKeyControl interface {
+	SetHostKey(fingerprint string)
+	// HostKeyFingerprint returns the fingerprint of the host key the BMC presents, without verifying
+	// it.
+	HostKeyFingerprint(ctx context.Context) (string, error)
+}
+
+// CertificateControl is implemented by BMCs whose HTTPS certificate can be replaced. The BMC
+// generates the key, so that it never leaves the BMC.
+type CertificateControl interface {
+	// Certificate returns the certificate the BMC presents, without verifying it.
+	Certificate(ctx context.Context) (*x509.Certificate, error)
+	// GenerateCSR makes the BMC generate a new key and returns a PEM encoded CSR for it.
+	GenerateCSR(ctx context.Context, commonName string, alternativeNames []string) ([]byte, error)
+	// InstallCertificate replaces the HTTPS certificate of the BMC with a PEM encoded certificate
+	// for the key of the last CSR.
+	InstallCertificate(ctx context.Context, cert []byte) error
+}
+
+// CipherSuiteControl applies the cipher suite policy of the flags to the BMC and returns the weak
+// cipher suites that remain enabled.
+type CipherSuiteControl interface {
+	EnsureCipherSuites(ctx context.Context) ([]int, error)
+}
+
+var (
+	ErrUnreachable          = errors.New("BMC is unreachable")
+	ErrAuthFailed           = errors.New("BMC authentication failed")
+	ErrUnsupportedProtocol  = errors.New("BMC protocol is not supported")
+	ErrUnsupported          = errors.New("BMC does not support the requested setting")
+	ErrUntrustedCertificate = errors.New("BMC certificate is not trusted")
+	ErrCertificateChanged   = errors.New("BMC certificate does not match the pinned fingerprint")
+	ErrNoCertificateService = errors.New("BMC does not support certificate management")
+	ErrUntrustedHostKey     = errors.New("BMC host key is not trusted")
+	ErrHostKeyChanged       = errors.New("BMC host key does not match the pinned fingerprint")
+)
+
 type newBMCFunc func(tags map[string]string, host string, port int, creds Credentials, exp time.Time) BMC
 
 var (
@@ -49,12 +123,84 @@ func registerBMC(newFunc newBMCFunc) {
 func NewBMC(typ string, tags map[string]string, host string, port int, creds Credentials, exp time.Time) (BMC, error) {
 	newFunc, ok := bmcs[typ]
 	if !ok {
-		return nil, fmt.Errorf("BMC of type %s is not supported", typ)
+		return nil, fmt.Errorf("BMC of type %s is not supported: %w", typ, ErrUnsupportedProtocol)
+	}
+
+	err := ValidateFlags(tags)
+	if err != nil {
+		return nil, err
 	}
 
 	return newFunc(tags, host, port, creds, exp), nil
 }
 
+// redactedError hides secrets that a BMC or a remote command echoed into an error message. It only
+// keeps the redacted message, and wraps the sentinel errors the original error matched instead of
+// the original error, so that errors.Is keeps working without exposing the secrets.
+type redactedError struct {
+	msg  string
+	errs []error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() []error {
+	return e.errs
+}
+
+// redactedSentinels are the errors that errors.Is still matches after redaction.
+var redactedSentinels = []error{
+	ErrUnreachable,
+	ErrAuthFailed,
+	ErrUnsupportedProtocol,
+	ErrUnsupported,
+	ErrUntrustedCertificate,
+	ErrCertificateChanged,
+	ErrNoCertificateService,
+	ErrUntrustedHostKey,
+	ErrHostKeyChanged,
+	ErrInvalidFlags,
+	context.Canceled,
+	context.DeadlineExceeded,
+}
+
+func redact(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+	// Longer secrets are replaced first, in a single pass, so that no secret is partially replaced.
+	secrets = slices.DeleteFunc(slices.Clone(secrets), func(s string) bool {
+		return s == ""
+	})
+	slices.SortFunc(secrets, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	oldnew := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		oldnew = append(oldnew, s, "<redacted>")
+	}
+	msg := strings.NewReplacer(oldnew...).Replace(err.Error())
+
+	var errs []error
+	for _, s := range redactedSentinels {
+		if errors.Is(err, s) {
+			errs = append(errs, s)
+		}
+	}
+	return &redactedError{msg: msg, errs: errs}
+}
+
+// passwords returns the passwords of all credentials and the extra passwords, to redact them.
+func passwords(creds []Credentials, extra ...string) []string {
+	pws := make([]string, 0, len(creds)+len(extra))
+	for _, c := range creds {
+		pws = append(pws, c.Password)
+	}
+	return append(pws, extra...)
+}
+
 type Credentials struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
@@ -73,6 +219,13 @@ type Info struct {
 	OSReason     string
 	Console      string
 	FWVersion    string
+	// Systems lists all systems of the BMC, if it can manage more than one.
+	Systems []SystemInfo
+}
+
+type SystemInfo struct {
+	ID   string
+	UUID string
 }
 
 func must(ctx context.Context, err error) {
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redaction", func() {
+	It("should not keep the secrets anywhere in the error chain", func() {
+		err := fmt.Errorf("cannot connect: %w", fmt.Errorf("%w: password s3cr3t rejected", ErrAuthFailed))
+		err = fmt.Errorf("cannot create user: %w", redact(err, "s3cr3t", "", "unused"))
+
+		Expect(err).To(MatchError("cannot create user: cannot connect: BMC authentication failed: password <redacted> rejected"))
+		Expect(err).To(MatchError(ErrAuthFailed))
+		Expect(err).NotTo(MatchError(ErrUnreachable))
+		for e := errors.Unwrap(err); e != nil; e = errors.Unwrap(e) {
+			Expect(e.Error()).NotTo(ContainSubstring("s3cr3t"))
+		}
+		var rerr *redactedError
+		Expect(errors.As(err, &rerr)).To(BeTrue())
+		Expect(rerr.Unwrap()).To(ConsistOf(ErrAuthFailed))
+	})
+
+	It("should redact the passwords of all credentials", func() {
+		err := redact(errors.New("admin:calvin root:calvin2 ADMIN:temporary"), passwords([]Credentials{
+			{Username: "admin", Password: "calvin"},
+			{Username: "root", Password: "calvin2"},
+		}, "temporary")...)
+		Expect(err).To(MatchError("admin:<redacted> root:<redacted> ADMIN:<redacted>"))
+	})
+})
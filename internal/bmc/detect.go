@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ironcore-dev/metal/internal/ipmi"
+	"github.com/ironcore-dev/metal/internal/log"
+)
+
+const (
+	detectRedfishPort = 443
+	detectIPMIPort    = ipmiDefaultPort
+)
+
+var ErrNoProtocolDetected = errors.New("no supported BMC protocol detected")
+
+// Detection describes a protocol that was found by probing a BMC.
+type Detection struct {
+	Type   string
+	Port   int
+	Reason string
+}
+
+// DetectProtocol probes a BMC without credentials. It tries the Redfish service root first, then IPMI
+// v2.0 (lanplus) channel authentication capabilities, and returns the first protocol that answers.
+// The timeout applies to each probe.
+func DetectProtocol(ctx context.Context, host string, timeout time.Duration) (Detection, error) {
+	return detectProtocol(ctx, host, detectRedfishPort, detectIPMIPort, timeout)
+}
+
+func detectProtocol(ctx context.Context, host string, redfishPort, ipmiPort int, timeout time.Duration) (Detection, error) {
+	rerr := redfishProbe(ctx, host, redfishPort, timeout)
+	if rerr == nil {
+		return Detection{
+			Type:   "Redfish",
+			Port:   redfishPort,
+			Reason: fmt.Sprintf("Redfish service root found on port %d", redfishPort),
+		}, nil
+	}
+	log.Debug(ctx, "Redfish not detected", "error", rerr)
+
+	ierr := ipmiProbe(ctx, host, ipmiPort, timeout)
+	if ierr == nil {
+		return Detection{
+			Type:   "IPMI",
+			Port:   ipmiPort,
+			Reason: fmt.Sprintf("IPMI v2.0 found on port %d", ipmiPort),
+		}, nil
+	}
+	log.Debug(ctx, "IPMI not detected", "error", ierr)
+
+	return Detection{}, fmt.Errorf("%w: redfish: %w, ipmi: %w", ErrNoProtocolDetected, rerr, ierr)
+}
+
+func redfishProbe(ctx context.Context, host string, port int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("https://%s/redfish/v1/", net.JoinHostPort(host, strconv.Itoa(port)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create request: %w", err)
+	}
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			// The BMC certificate cannot be trusted before the BMC is taken over.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		},
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot get service root: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get service root: %s", resp.Status)
+	}
+
+	var root struct {
+		RedfishVersion string `json:"RedfishVersion"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&root)
+	if err != nil {
+		return fmt.Errorf("cannot decode service root: %w", err)
+	}
+	if root.RedfishVersion == "" {
+		return fmt.Errorf("service root has no Redfish version")
+	}
+
+	return nil
+}
+
+// ipmiProbe sends a session-less Get Channel Authentication Capabilities request and checks that
+// the BMC supports IPMI v2.0 connections.
+func ipmiProbe(ctx context.Context, host string, port int, timeout time.Duration) error {
+	return ipmi.Ping(ctx, net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+}
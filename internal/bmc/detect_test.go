@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ironcore-dev/metal/internal/ipmi"
+)
+
+var _ = Describe("Protocol detection", func() {
+	It("should detect Redfish from the service root", func(ctx SpecContext) {
+		By("Starting a Redfish service root")
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/redfish/v1/" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(`{"RedfishVersion": "1.6.0"}`))
+		}))
+		DeferCleanup(srv.Close)
+
+		By("Expecting Redfish to be detected")
+		d, err := detectProtocol(ctx, "127.0.0.1", serverPort(srv), closedUDPPort(), time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Type).To(Equal("Redfish"))
+		Expect(d.Port).To(Equal(serverPort(srv)))
+	})
+
+	It("should fall back to IPMI", func(ctx SpecContext) {
+		By("Starting an IPMI responder")
+		port := startIPMIResponder()
+
+		By("Expecting IPMI to be detected")
+		d, err := detectProtocol(ctx, "127.0.0.1", closedTCPPort(), port, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Type).To(Equal("IPMI"))
+		Expect(d.Port).To(Equal(port))
+	})
+
+	It("should fail if no protocol answers", func(ctx SpecContext) {
+		_, err := detectProtocol(ctx, "127.0.0.1", closedTCPPort(), closedUDPPort(), time.Second)
+		Expect(err).To(MatchError(ErrNoProtocolDetected))
+	})
+})
+
+func serverPort(srv *httptest.Server) int {
+	u, err := url.Parse(srv.URL)
+	Expect(err).NotTo(HaveOccurred())
+	port, err := strconv.Atoi(u.Port())
+	Expect(err).NotTo(HaveOccurred())
+	return port
+}
+
+func closedTCPPort() int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(l.Close()).To(Succeed())
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func closedUDPPort() int {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(c.Close()).To(Succeed())
+	return c.LocalAddr().(*net.UDPAddr).Port
+}
+
+// startIPMIResponder starts a fake IPMI v2.0 BMC.
+func startIPMIResponder() int {
+	r, err := ipmi.NewResponder("admin", "password")
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(r.Close)
+
+	return r.Port()
+}
@@ -0,0 +1,272 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ironcore-dev/metal/internal/ipmi"
+)
+
+// Flags tune a driver for a vendor or model. They are set in the MAC DB, in an OOBProfile or in
+// OOB.Spec.Flags, and are passed to the driver as tags. Flags of other protocols are ignored.
+const (
+	// FlagRedfishTLSVerify verifies the certificate of the BMC against the system CAs. Deprecated: use
+	// FlagRedfishTLSPolicy, which takes precedence.
+	FlagRedfishTLSVerify = "redfish.tls-verify"
+	// FlagRedfishTLSPolicy selects how the certificate of the BMC is verified.
+	FlagRedfishTLSPolicy = "redfish.tls-policy"
+	// FlagRedfishTLSCASecret names a Secret in the system namespace that holds the CA certificates of
+	// the ca policy in the key ca.crt.
+	FlagRedfishTLSCASecret = "redfish.tls-ca-secret"
+	// FlagRedfishCertificateCASecret names a Secret of type kubernetes.io/tls in the system namespace
+	// with a CA. If it is set, the certificate of the BMC is replaced with one issued by the CA for a
+	// key generated on the BMC, and renewed after two thirds of its validity.
+	FlagRedfishCertificateCASecret = "redfish.certificate-ca-secret"
+	// FlagRedfishCertificateValidity sets the validity of the certificates issued for the BMC.
+	FlagRedfishCertificateValidity = "redfish.certificate-validity"
+	// FlagRedfishAuth selects session or basic authentication.
+	FlagRedfishAuth = "redfish.auth"
+	// FlagRedfishTimeout limits the duration of a single request, 0 disables the limit.
+	FlagRedfishTimeout = "redfish.timeout"
+	// FlagIPMICipherSuite sets the cipher suite ID used for lanplus sessions.
+	FlagIPMICipherSuite = "ipmi.cipher-suite"
+	// FlagIPMIPrivilege sets the privilege level requested for sessions.
+	FlagIPMIPrivilege = "ipmi.privilege"
+	// FlagIPMIDisableWeakCipherSuites disables weak cipher suites on the BMC.
+	FlagIPMIDisableWeakCipherSuites = "ipmi.disable-weak-cipher-suites"
+	// FlagSSHType sets the device type, which cannot be discovered over SSH.
+	FlagSSHType = "ssh.type"
+)
+
+// TLS policies of FlagRedfishTLSPolicy.
+const (
+	// TLSPolicyInsecure accepts any certificate.
+	TLSPolicyInsecure = "insecure"
+	// TLSPolicySystem verifies the certificate against the system CAs.
+	TLSPolicySystem = "system"
+	// TLSPolicyCA verifies the certificate against the CAs of FlagRedfishTLSCASecret.
+	TLSPolicyCA = "ca"
+	// TLSPolicyPin trusts the first certificate the BMC presents and rejects any other one later.
+	TLSPolicyPin = "pin"
+)
+
+var ErrInvalidFlags = errors.New("BMC flags are invalid")
+
+// Flag describes a flag accepted by a driver.
+type Flag struct {
+	Name        string
+	Protocol    string
+	Default     string
+	Description string
+	validate    func(string) error
+}
+
+var flags = map[string]Flag{}
+
+func registerFlag(f Flag) {
+	flags[f.Name] = f
+}
+
+func init() {
+	registerFlag(Flag{
+		Name:        FlagRedfishTLSVerify,
+		Protocol:    "Redfish",
+		Default:     "false",
+		Description: "Verify the certificate of the BMC against the system CAs. Deprecated, use redfish.tls-policy.",
+		validate:    validateBool,
+	})
+	registerFlag(Flag{
+		Name:        FlagRedfishTLSPolicy,
+		Protocol:    "Redfish",
+		Description: "Verify the certificate of the BMC: accept any (insecure), against the system CAs (system), against the CAs of redfish.tls-ca-secret (ca), or pin it on first use (pin). Defaults to system if redfish.tls-verify is true, else insecure.",
+		validate:    validateOneOf(TLSPolicyInsecure, TLSPolicySystem, TLSPolicyCA, TLSPolicyPin),
+	})
+	registerFlag(Flag{
+		Name:        FlagRedfishTLSCASecret,
+		Protocol:    "Redfish",
+		Description: "Name of a Secret in the system namespace with the CA certificates of the ca policy in the key ca.crt.",
+		validate:    validateName,
+	})
+	registerFlag(Flag{
+		Name:        FlagRedfishCertificateCASecret,
+		Protocol:    "Redfish",
+		Description: "Name of a Secret of type kubernetes.io/tls in the system namespace with a CA that issues the certificate of the BMC.",
+		validate:    validateName,
+	})
+	registerFlag(Flag{
+		Name:        FlagRedfishCertificateValidity,
+		Protocol:    "Redfish",
+		Default:     "8760h",
+		Description: "Validity of the certificates issued for the BMC, they are renewed after two thirds of it.",
+		validate:    validatePositiveDuration,
+	})
+	registerFlag(Flag{
+		Name:        FlagRedfishAuth,
+		Protocol:    "Redfish",
+		Default:     "session",
+		Description: "Authenticate with a session token (session) or with every request (basic).",
+		validate:    validateOneOf("session", "basic"),
+	})
+	registerFlag(Flag{
+		Name:        FlagRedfishTimeout,
+		Protocol:    "Redfish",
+		Default:     "0s",
+		Description: "Limit the duration of a single request, 0s disables the limit.",
+		validate:    validateDuration,
+	})
+	registerFlag(Flag{
+		Name:        FlagIPMICipherSuite,
+		Protocol:    "IPMI",
+		Description: "Use the cipher suite with this ID (1, 2, 3, 15, 16 or 17) instead of the strongest one the BMC supports.",
+		validate:    validateCipherSuite,
+	})
+	registerFlag(Flag{
+		Name:        FlagIPMIPrivilege,
+		Protocol:    "IPMI",
+		Default:     ipmiPrivilegeAdministrator,
+		Description: "Request this privilege level (ADMINISTRATOR, OPERATOR or USER) for sessions.",
+		validate:    validateOneOf(ipmiPrivilegeAdministrator, ipmiPrivilegeOperator, ipmiPrivilegeUser),
+	})
+	registerFlag(Flag{
+		Name:        FlagIPMIDisableWeakCipherSuites,
+		Protocol:    "IPMI",
+		Default:     "false",
+		Description: "Disable all cipher suites but 3 and 17 on the BMC, as they lack integrity protection or encryption.",
+		validate:    validateBool,
+	})
+	registerFlag(Flag{
+		Name:        FlagSSHType,
+		Protocol:    "SSH",
+		Default:     "Switch",
+		Description: "Report the device as a Switch or a Router.",
+		validate:    validateOneOf("Switch", "Router"),
+	})
+}
+
+// Flags returns all known flags sorted by name.
+func Flags() []Flag {
+	fs := make([]Flag, 0, len(flags))
+	for _, f := range flags {
+		fs = append(fs, f)
+	}
+	sort.Slice(fs, func(i, j int) bool {
+		return fs[i].Name < fs[j].Name
+	})
+	return fs
+}
+
+// ValidateFlags checks that all flags are known and have valid values.
+func ValidateFlags(tags map[string]string) error {
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	for _, k := range names {
+		f, ok := flags[k]
+		if !ok {
+			return fmt.Errorf("%w: unknown flag %s", ErrInvalidFlags, k)
+		}
+		err := f.validate(tags[k])
+		if err != nil {
+			return fmt.Errorf("%w: flag %s: %w", ErrInvalidFlags, k, err)
+		}
+	}
+	return nil
+}
+
+// flagValue returns the value of a flag, or its default if it is not set. Flags are validated in
+// NewBMC, so drivers can parse values without checking for errors.
+func flagValue(tags map[string]string, name string) string {
+	v, ok := tags[name]
+	if !ok {
+		return flags[name].Default
+	}
+	return v
+}
+
+// RedfishTLSPolicy returns the TLS policy of the flags.
+func RedfishTLSPolicy(tags map[string]string) string {
+	policy := flagValue(tags, FlagRedfishTLSPolicy)
+	if policy != "" {
+		return policy
+	}
+	if boolFlag(tags, FlagRedfishTLSVerify) {
+		return TLSPolicySystem
+	}
+	return TLSPolicyInsecure
+}
+
+// RedfishCertificateValidity returns the validity of the certificates issued for the BMC.
+func RedfishCertificateValidity(tags map[string]string) time.Duration {
+	return durationFlag(tags, FlagRedfishCertificateValidity)
+}
+
+func boolFlag(tags map[string]string, name string) bool {
+	b, _ := strconv.ParseBool(flagValue(tags, name))
+	return b
+}
+
+func durationFlag(tags map[string]string, name string) time.Duration {
+	d, _ := time.ParseDuration(flagValue(tags, name))
+	return d
+}
+
+func validateBool(v string) error {
+	_, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("invalid boolean: %s", v)
+	}
+	return nil
+}
+
+func validateDuration(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid duration: %s", v)
+	}
+	return nil
+}
+
+func validatePositiveDuration(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid positive duration: %s", v)
+	}
+	return nil
+}
+
+func validateCipherSuite(v string) error {
+	id, err := strconv.Atoi(v)
+	if err != nil || !slices.Contains(ipmi.SupportedCipherSuites(), id) {
+		return fmt.Errorf("invalid value %s, must be one of %v", v, ipmi.SupportedCipherSuites())
+	}
+	return nil
+}
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+func validateName(v string) error {
+	if len(v) > 253 || !nameRegex.MatchString(v) {
+		return fmt.Errorf("invalid name: %s", v)
+	}
+	return nil
+}
+
+func validateOneOf(values ...string) func(string) error {
+	return func(v string) error {
+		if !slices.Contains(values, v) {
+			return fmt.Errorf("invalid value %s, must be one of %v", v, values)
+		}
+		return nil
+	}
+}
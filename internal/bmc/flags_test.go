@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ironcore-dev/metal/internal/ipmi"
+)
+
+var _ = Describe("Flags", func() {
+	It("should accept valid flags", func() {
+		Expect(ValidateFlags(map[string]string{
+			FlagRedfishTLSVerify:           "true",
+			FlagRedfishTLSPolicy:           "ca",
+			FlagRedfishTLSCASecret:         "bmc-ca",
+			FlagRedfishCertificateCASecret: "bmc-issuer",
+			FlagRedfishCertificateValidity: "2160h",
+			FlagRedfishAuth:                "basic",
+			FlagRedfishTimeout:             "30s",
+			FlagIPMICipherSuite:            "17",
+			FlagIPMIPrivilege:              "OPERATOR",
+			FlagSSHType:                    "Router",
+		})).To(Succeed())
+	})
+
+	It("should reject unknown flags and invalid values", func() {
+		Expect(ValidateFlags(map[string]string{"redfish.unknown": "true"})).To(MatchError(ErrInvalidFlags))
+		Expect(ValidateFlags(map[string]string{FlagRedfishTimeout: "-1s"})).To(MatchError(ErrInvalidFlags))
+		Expect(ValidateFlags(map[string]string{FlagIPMICipherSuite: "0"})).To(MatchError(ErrInvalidFlags))
+		Expect(ValidateFlags(map[string]string{FlagIPMIPrivilege: "ROOT"})).To(MatchError(ErrInvalidFlags))
+		Expect(ValidateFlags(map[string]string{FlagRedfishTLSPolicy: "tofu"})).To(MatchError(ErrInvalidFlags))
+		Expect(ValidateFlags(map[string]string{FlagRedfishTLSCASecret: "BMC_CA"})).To(MatchError(ErrInvalidFlags))
+		Expect(ValidateFlags(map[string]string{FlagRedfishCertificateValidity: "0s"})).To(MatchError(ErrInvalidFlags))
+
+		_, err := NewBMC("Redfish", map[string]string{FlagRedfishAuth: "token"}, "127.0.0.1", 0, Credentials{}, time.Time{})
+		Expect(err).To(MatchError(ErrInvalidFlags))
+	})
+
+	It("should fall back to defaults", func() {
+		Expect(boolFlag(nil, FlagRedfishTLSVerify)).To(BeFalse())
+		Expect(RedfishTLSPolicy(nil)).To(Equal(TLSPolicyInsecure))
+		Expect(RedfishTLSPolicy(map[string]string{FlagRedfishTLSVerify: "true"})).To(Equal(TLSPolicySystem))
+		Expect(RedfishTLSPolicy(map[string]string{FlagRedfishTLSVerify: "true", FlagRedfishTLSPolicy: "pin"})).To(Equal(TLSPolicyPin))
+		Expect(flagValue(nil, FlagRedfishAuth)).To(Equal("session"))
+		Expect(RedfishCertificateValidity(nil)).To(Equal(365 * 24 * time.Hour))
+		Expect(durationFlag(map[string]string{FlagRedfishTimeout: "1m"}, FlagRedfishTimeout)).To(Equal(time.Minute))
+	})
+
+	It("should use the IPMI flags for sessions", func(ctx SpecContext) {
+		r, err := ipmi.NewResponder("admin", "password")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(r.Close)
+		r.SetCipherSuites(17)
+
+		creds := Credentials{Username: "admin", Password: "password"}
+		Expect(ipmiping(ctx, map[string]string{FlagIPMICipherSuite: "3"}, "127.0.0.1", r.Port(), creds)).NotTo(Succeed())
+		Expect(ipmiping(ctx, map[string]string{
+			FlagIPMICipherSuite: "17",
+			FlagIPMIPrivilege:   "OPERATOR",
+		}, "127.0.0.1", r.Port(), creds)).To(Succeed())
+	})
+})
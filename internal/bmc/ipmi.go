@@ -4,19 +4,17 @@
 package bmc
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"net"
 	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 
+	"github.com/ironcore-dev/metal/internal/ipmi"
 	"github.com/ironcore-dev/metal/internal/log"
 )
 
@@ -50,10 +48,32 @@ func (b *IPMIBMC) ResetControl() ResetControl {
 	return b
 }
 
+func (b *IPMIBMC) ManagerResetControl() ManagerResetControl {
+	return b
+}
+
+func (b *IPMIBMC) CipherSuiteControl() CipherSuiteControl {
+	return b
+}
+
 func (b *IPMIBMC) Credentials() (Credentials, time.Time) {
 	return b.creds, b.exp
 }
 
+const (
+	ipmiDefaultPort = 623
+
+	ipmiPrivilegeAdministrator = "ADMINISTRATOR"
+	ipmiPrivilegeOperator      = "OPERATOR"
+	ipmiPrivilegeUser          = "USER"
+)
+
+var ipmiPrivileges = map[string]ipmi.PrivilegeLevel{
+	ipmiPrivilegeAdministrator: ipmi.PrivilegeAdministrator,
+	ipmiPrivilegeOperator:      ipmi.PrivilegeOperator,
+	ipmiPrivilegeUser:          ipmi.PrivilegeUser,
+}
+
 type IPMIBMC struct {
 	tags  map[string]string
 	host  string
@@ -63,65 +83,104 @@ type IPMIBMC struct {
 }
 
 type IPMIUser struct {
-	id       string
+	id       byte
 	username string
 	enabled  bool
-	//available bool
 }
 
-// TODO: ipmi ciphers, should this also be tested?
+func ipmiConnect(ctx context.Context, tags map[string]string, host string, port int, creds Credentials) (*ipmi.Session, error) {
+	log.Debug(ctx, "Connecting", "host", host, "user", creds.Username)
 
-func outputmap(ml string, mi *map[string]string) {
-	for _, line := range strings.Split(ml, "\n") {
-		colon := strings.Index(line, ":")
-		if colon == -1 {
-			continue
-		}
-		k := strings.TrimSpace(line[:colon])
-		v := strings.TrimSpace(line[colon+1:])
-		if k == "" {
-			continue
-		}
-		(*mi)[k] = v
+	if port == 0 {
+		port = ipmiDefaultPort
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	cipherSuite, err := ipmiCipherSuite(ctx, tags, addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot choose a cipher suite: %w", ipmiClassifyError(err))
+	}
+	s, err := ipmi.Open(ctx, addr, ipmi.Config{
+		Username:    creds.Username,
+		Password:    creds.Password,
+		CipherSuite: cipherSuite,
+		Privilege:   ipmiPrivileges[flagValue(tags, FlagIPMIPrivilege)],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect: %w", ipmiClassifyError(err))
 	}
+	return s, nil
 }
 
-func outputmapspace(ml string, mi *map[string]string) {
-	for _, line := range strings.Split(ml, "\n") {
-		line = strings.Join(strings.Fields(strings.TrimSpace(line)), " ")
-		if strings.HasPrefix(line, "#") {
-			continue
-		}
-		space := strings.Index(line, " ")
-		var k, v string
-		if space == -1 {
-			k = line
-		} else {
-			k = strings.TrimSpace(line[:space])
-			v = strings.TrimSpace(line[space+1:])
-		}
-		if k == "" {
-			continue
+// ipmiCipherSuite returns the cipher suite of the flags, or the strongest one the BMC supports.
+func ipmiCipherSuite(ctx context.Context, tags map[string]string, addr string) (int, error) {
+	if v := flagValue(tags, FlagIPMICipherSuite); v != "" {
+		return strconv.Atoi(v)
+	}
+
+	ids, err := ipmi.GetChannelCipherSuites(ctx, addr, 0)
+	if err != nil {
+		var cerr *ipmi.CompletionCodeError
+		if errors.As(err, &cerr) {
+			log.Debug(ctx, "Cannot list cipher suites, using the default", "error", err)
+			return ipmi.DefaultCipherSuite, nil
 		}
-		(*mi)[k] = v
+		return 0, err
+	}
+	suite, err := ipmi.StrongestCipherSuite(ids)
+	if err != nil {
+		return 0, err
+	}
+	log.Debug(ctx, "Chose cipher suite", "suite", suite, "available", ids)
+	return suite, nil
+}
+
+func ipmiClose(ctx context.Context, s *ipmi.Session) {
+	err := s.Close(ctx)
+	if err != nil {
+		log.Debug(ctx, "Cannot close IPMI session", "error", err)
 	}
 }
 
-func ipmiFindWorkingCredentials(ctx context.Context, host string, port int, defaultCreds []Credentials, tempPassword string) (Credentials, error) {
+func ipmiClassifyError(err error) error {
+	switch {
+	case errors.Is(err, ipmi.ErrAuthentication):
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+	case errors.Is(err, ipmi.ErrNoResponse):
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	return err
+}
+
+func ipmiping(ctx context.Context, tags map[string]string, host string, port int, creds Credentials) error {
+	s, err := ipmiConnect(ctx, tags, host, port, creds)
+	if err != nil {
+		return err
+	}
+	ipmiClose(ctx, s)
+	return nil
+}
+
+func ipmiFindWorkingCredentials(ctx context.Context, tags map[string]string, host string, port int, defaultCreds []Credentials, tempPassword string) (Credentials, error) {
 	if len(defaultCreds) == 0 {
 		return Credentials{}, fmt.Errorf("no default credentials to try")
 	}
 
 	var merr error
 	for _, creds := range defaultCreds {
-		err := ipmiping(ctx, host, port, creds)
+		err := ipmiping(ctx, tags, host, port, creds)
 		if err == nil {
 			return creds, nil
 		}
 		merr = multierror.Append(merr, err)
 	}
 	for _, creds := range defaultCreds {
-		err := ipmiping(ctx, host, port, Credentials{creds.Username, tempPassword})
+		creds.Password = tempPassword
+		err := ipmiping(ctx, tags, host, port, creds)
 		if err == nil {
 			return creds, nil
 		}
@@ -131,9 +190,9 @@ func ipmiFindWorkingCredentials(ctx context.Context, host string, port int, defa
 }
 
 func (b *IPMIBMC) EnsureInitialCredentials(ctx context.Context, defaultCreds []Credentials, tempPassword string) error {
-	creds, err := ipmiFindWorkingCredentials(ctx, b.host, b.port, defaultCreds, tempPassword)
+	creds, err := ipmiFindWorkingCredentials(ctx, b.tags, b.host, b.port, defaultCreds, tempPassword)
 	if err != nil {
-		return err
+		return redact(err, passwords(defaultCreds, tempPassword)...)
 	}
 
 	b.creds = creds
@@ -141,198 +200,166 @@ func (b *IPMIBMC) EnsureInitialCredentials(ctx context.Context, defaultCreds []C
 }
 
 func (b *IPMIBMC) Connect(ctx context.Context) error {
-	return ipmiping(ctx, b.host, b.port, b.creds)
+	return ipmiping(ctx, b.tags, b.host, b.port, b.creds)
 }
 
 func (b *IPMIBMC) ReadInfo(ctx context.Context) (Info, error) {
 	log.Debug(ctx, "Reading BMC info", "host", b.host)
-	info := make(map[string]string)
-
-	out, serr, err := ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "fru", "list", "0")
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
 	if err != nil {
-		return Info{}, fmt.Errorf("cannot get fru info, stderr: %s: %w", serr, err)
+		return Info{}, err
 	}
-	outputmap(out, &info)
+	defer ipmiClose(ctx, s)
 
-	out, serr, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "mc", "guid")
+	data, err := s.ReadFRU(ctx, 0)
 	if err != nil {
-		return Info{}, fmt.Errorf("cannot get mc info, stderr: %s: %w", serr, err)
+		return Info{}, fmt.Errorf("cannot read FRU: %w", err)
 	}
-	outputmap(out, &info)
-
-	out, serr, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmi-chassis", "--get-chassis-status")
+	fru, err := ipmi.ParseFRU(data)
 	if err != nil {
-		return Info{}, fmt.Errorf("cannot get chassis status, stderr: %s: %w", serr, err)
+		return Info{}, fmt.Errorf("cannot parse FRU: %w", err)
 	}
-	outputmap(out, &info)
 
-	out, serr, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "bmc", "info")
+	uuid, err := s.GetSystemGUID(ctx)
 	if err != nil {
-		return Info{}, fmt.Errorf("cannot get bmc info, stderr: %s: %w", serr, err)
+		return Info{}, fmt.Errorf("cannot get system GUID: %w", err)
 	}
-	outputmap(out, &info)
 
-	uuid, ok := info["System GUID"]
-	if !ok {
-		return Info{}, fmt.Errorf("cannot determine uuid for machine")
+	capabilities := []string{"credentials", "power"}
+	status, err := s.GetChassisStatus(ctx)
+	if err != nil {
+		return Info{}, fmt.Errorf("cannot get chassis status: %w", err)
 	}
-	uuid = strings.ToLower(uuid)
-	powerstate, ok := info["System Power"]
-	if !ok {
-		return Info{}, fmt.Errorf("cannot determine the power state for machine")
+	power := "Off"
+	if status.PowerOn {
+		power = "On"
 	}
-	serial := info["Product Serial"]
-	sku := info["Product SKU"]
-	manufacturer := info["Manufacturer"]
-	//TODO: currently we can't handle this correctly as we can't read the state on most hardware
-	//led, ok := info["Chassis Identify State"]
 	led := ""
-	fw := info["Firmware Revision"]
+	if status.IdentifySupported {
+		capabilities = append(capabilities, "led")
+		led = ipmiLocatorLED(status.Identify)
+	}
+
+	console := ""
+	sol, err := s.GetSOLEnabled(ctx)
+	if err != nil {
+		var cerr *ipmi.CompletionCodeError
+		if !errors.As(err, &cerr) {
+			return Info{}, fmt.Errorf("cannot get SOL configuration: %w", err)
+		}
+		log.Debug(ctx, "SOL is not supported", "error", err)
+	}
+	if sol {
+		capabilities = append(capabilities, "console")
+		console = "ipmi"
+	}
+
+	id, err := s.GetDeviceID(ctx)
+	if err != nil {
+		return Info{}, fmt.Errorf("cannot get device ID: %w", err)
+	}
 
-	//TODO: properly detect if sol is supported
 	return Info{
 		UUID:         uuid,
 		Type:         "BMC",
-		Capabilities: []string{"credentials", "power", "led", "console"},
-		SerialNumber: serial,
-		SKU:          sku,
-		Manufacturer: manufacturer,
+		Capabilities: capabilities,
+		SerialNumber: firstNonEmpty(fru.ProductSerialNumber, fru.BoardSerialNumber),
+		SKU:          firstNonEmpty(fru.ProductPartNumber, fru.BoardPartNumber),
+		Manufacturer: firstNonEmpty(fru.ProductManufacturer, fru.BoardManufacturer),
 		LocatorLED:   led,
-		Power:        cases.Title(language.English).String(powerstate),
-		Console:      "ipmi",
-		FWVersion:    fw,
+		Power:        power,
+		Console:      console,
+		FWVersion:    id.FirmwareRevision(),
 	}, nil
 }
 
-func ipmiGenerateCommand(ctx context.Context, host string, port int, creds Credentials, cmd ...string) ([]string, error) {
-	if port == 0 {
-		port = 623
-	}
-
-	var command []string
-	actualCmd := cmd[0]
-	params := cmd[1:]
-	log.Debug(ctx, "Executing IPMI command", "host", host, "command", actualCmd)
-	if actualCmd == "ipmitool" {
-		command = append(command, "/usr/bin/ipmitool", "-I", "lanplus", "-H", host, "-U", creds.Username, "-P", creds.Password, "-p", strconv.Itoa(port))
-		command = append(command, params...)
-	} else if actualCmd == "ipmi-chassis" || actualCmd == "ipmi-config" {
-		// TODO: check how to provide custom port
-		command = append(command, "/usr/sbin/"+actualCmd, "-D", "LAN_2_0", "-h", host, "-u", creds.Username, "-p", creds.Password)
-		command = append(command, params...)
-	} else {
-		return command, fmt.Errorf("ipmi command not supported at the moment")
+func ipmiLocatorLED(state ipmi.IdentifyState) string {
+	if state == ipmi.IdentifyOff {
+		return "Off"
 	}
-
-	return command, nil
+	return "On"
 }
 
-func ipmiExecuteCommand(ctx context.Context, host string, port int, creds Credentials, cmd ...string) (string, string, error) {
-	command, err := ipmiGenerateCommand(ctx, host, port, creds, cmd...)
-	if err != nil {
-		return "", "", err
-	}
-	actualCmd := command[0]
-	params := command[1:]
-	actualCmd, err = exec.LookPath(actualCmd)
-	if err != nil {
-		return "", "", fmt.Errorf("%s not found in the PATH: %w", actualCmd, err)
-	}
-	c := exec.Command(actualCmd, params...)
-
-	var stdout, stderr bytes.Buffer
-	c.Stdout = &stdout
-	c.Stderr = &stderr
-
-	// TODO: add timeout
-	err = c.Run()
-	if err != nil {
-		return "", "", fmt.Errorf("cannot excute command: %s, got: %w", cmd[0], err)
+func firstNonEmpty(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
 	}
-
-	return stdout.String(), stderr.String(), nil
+	return ""
 }
 
-func ipmiping(ctx context.Context, host string, port int, creds Credentials) error {
-	// TODO: figure out a better way to test credentials
-	_, _, err := ipmiExecuteCommand(ctx, host, port, creds, "ipmitool", "lan", "print", "1")
+func ipmigetusers(ctx context.Context, s *ipmi.Session) ([]IPMIUser, error) {
+	access, err := s.GetUserAccess(ctx, 1)
 	if err != nil {
-		return fmt.Errorf("cannot use credentials for ipmi: %w", err)
+		return nil, fmt.Errorf("cannot get IPMI user slots: %w", err)
 	}
-	return nil
-}
 
-func ipmigetusers(ctx context.Context, host string, port int, creds Credentials) ([]IPMIUser, error) {
-	users := make([]IPMIUser, 0)
-	// TODO: determine amount of max slots before iterating
-	for i := 2; i <= 12; i++ {
-		info := make(map[string]string)
-		user := IPMIUser{}
-		id := strconv.Itoa(i)
-		out, _, err := ipmiExecuteCommand(ctx, host, port, creds, "ipmi-config", "--category=core", "--checkout", "-S", "User"+id)
+	users := make([]IPMIUser, 0, access.MaxUsers)
+	// User 1 is the anonymous user with a fixed empty name.
+	for id := byte(2); id <= access.MaxUsers; id++ {
+		username, err := s.GetUserName(ctx, id)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get IPMI users: %w", err)
-		}
-		outputmapspace(out, &info)
-		username, ok := info["Username"]
-		if !ok {
-			// TODO: maybe better to error out
-			continue
-		} else {
-			user.username = username
-		}
-		enabled, ok := info["Enable_User"]
-		if !ok {
-			return []IPMIUser{}, fmt.Errorf("cannot populate fields for user")
+			return nil, fmt.Errorf("cannot get IPMI user %d: %w", id, err)
 		}
-		switch enabled {
-		case "Yes":
-			user.enabled = true
-		case "No":
-			user.enabled = false
-		default:
-			return []IPMIUser{}, fmt.Errorf("cannot populate fields for user / invalid Enable_User")
+		access, err = s.GetUserAccess(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get IPMI user %d: %w", id, err)
 		}
-		user.id = id
-		users = append(users, user)
+		users = append(users, IPMIUser{
+			id:       id,
+			username: username,
+			enabled:  access.Enabled,
+		})
 	}
 	return users, nil
 }
 
-func ipmiGetFreeSlot(ctx context.Context, host string, port int, creds Credentials) (string, error) {
-	users, err := ipmigetusers(ctx, host, port, creds)
+func ipmiGetFreeSlot(ctx context.Context, s *ipmi.Session) (byte, error) {
+	users, err := ipmigetusers(ctx, s)
 	if err != nil {
-		return "", fmt.Errorf("cannot determine an empty slot: %w", err)
+		return 0, fmt.Errorf("cannot determine an empty slot: %w", err)
 	}
 	for _, user := range users {
 		if user.username == "" || !user.enabled {
 			return user.id, nil
 		}
 	}
-	return "", fmt.Errorf("no empty slot available")
-
+	return 0, fmt.Errorf("no empty slot available")
 }
+
 func (b *IPMIBMC) CreateUser(ctx context.Context, creds Credentials, _ string) error {
+	pw := b.creds.Password
+	return redact(b.createUser(ctx, creds), pw, creds.Password)
+}
+
+func (b *IPMIBMC) createUser(ctx context.Context, creds Credentials) error {
 	log.Debug(ctx, "Creating user", "host", b.host, "user", creds.Username)
-	slot, err := ipmiGetFreeSlot(ctx, b.host, b.port, b.creds)
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
+	if err != nil {
+		return err
+	}
+	defer ipmiClose(ctx, s)
+
+	slot, err := ipmiGetFreeSlot(ctx, s)
 	if err != nil {
 		return fmt.Errorf("cannot create a new user: %w", err)
 	}
-	_, stderr, err := ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "user", "set", "name", slot, creds.Username)
+	err = s.SetUserName(ctx, slot, creds.Username)
 	if err != nil {
-		return fmt.Errorf("cannot rename user: %s, %w", stderr, err)
+		return fmt.Errorf("cannot rename user: %w", err)
 	}
-	_, stderr, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "user", "set", "password", slot, creds.Password)
+	err = s.SetUserPassword(ctx, slot, creds.Password)
 	if err != nil {
-		return fmt.Errorf("cannot set user password: %s, %w", stderr, err)
+		return fmt.Errorf("cannot set user password: %w", err)
 	}
-	_, stderr, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "channel", "setaccess", "1", slot, "link=on", "callin=on", "privilege=4")
+	err = s.SetUserAccess(ctx, slot, ipmi.PrivilegeAdministrator)
 	if err != nil {
-		return fmt.Errorf("cannot set user privileges: %s, %w", stderr, err)
+		return fmt.Errorf("cannot set user privileges: %w", err)
 	}
-	_, stderr, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "user", "enable", slot)
+	err = s.EnableUser(ctx, slot, true)
 	if err != nil {
-		return fmt.Errorf("cannot enable user: %s, %w", stderr, err)
+		return fmt.Errorf("cannot enable user: %w", err)
 	}
 
 	b.creds = creds
@@ -341,7 +368,13 @@ func (b *IPMIBMC) CreateUser(ctx context.Context, creds Credentials, _ string) e
 }
 
 func (b *IPMIBMC) DeleteUsers(ctx context.Context, regex *regexp.Regexp) error {
-	users, err := ipmigetusers(ctx, b.host, b.port, b.creds)
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
+	if err != nil {
+		return err
+	}
+	defer ipmiClose(ctx, s)
+
+	users, err := ipmigetusers(ctx, s)
 	if err != nil {
 		return fmt.Errorf("cannot delete users, %w", err)
 	}
@@ -349,11 +382,11 @@ func (b *IPMIBMC) DeleteUsers(ctx context.Context, regex *regexp.Regexp) error {
 	for _, user := range users {
 		if user.username != b.creds.Username && regex.MatchString(user.username) {
 			log.Debug(ctx, "Deleting user", "user", user.username)
-			_, _, err := ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "user", "disable", user.id)
+			err = s.EnableUser(ctx, user.id, false)
 			if err != nil {
 				return fmt.Errorf("unable to disable user %s on host %s: %w", user.username, b.host, err)
 			}
-			_, _, err = ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "user", "set", "name", user.id, "")
+			err = s.SetUserName(ctx, user.id, "")
 			if err != nil {
 				return fmt.Errorf("unable to reset the name of the user %s on host %s: %w", user.username, b.host, err)
 			}
@@ -362,9 +395,89 @@ func (b *IPMIBMC) DeleteUsers(ctx context.Context, regex *regexp.Regexp) error {
 	return nil
 }
 
+func (b *IPMIBMC) SetLocatorLED(ctx context.Context, state string) (string, error) {
+	log.Debug(ctx, "Setting the LED on the machine")
+	var on bool
+	switch state {
+	case "On":
+		on = true
+	case "Off":
+		on = false
+	default:
+		return "", fmt.Errorf("unable to set LED state to %s: %w", state, ErrUnsupported)
+	}
+
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
+	if err != nil {
+		return "", err
+	}
+	defer ipmiClose(ctx, s)
+
+	err = s.ChassisIdentify(ctx, on)
+	if err != nil {
+		return "", fmt.Errorf("unable to set the LED: %w", err)
+	}
+	return state, nil
+}
+
+func (b *IPMIBMC) EnsureCipherSuites(ctx context.Context) ([]int, error) {
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
+	if err != nil {
+		return nil, err
+	}
+	defer ipmiClose(ctx, s)
+
+	csps, err := s.GetCipherSuitePrivileges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get cipher suites: %w", err)
+	}
+
+	var weak []int
+	secure := false
+	for _, csp := range csps {
+		if csp.MaxPrivilege == ipmi.PrivilegeUnspecified {
+			continue
+		}
+		if ipmi.WeakCipherSuite(csp.ID) {
+			weak = append(weak, csp.ID)
+		} else {
+			secure = true
+		}
+	}
+	if len(weak) == 0 || !boolFlag(b.tags, FlagIPMIDisableWeakCipherSuites) {
+		return weak, nil
+	}
+	// Disabling all cipher suites would lock out every client, including this one.
+	if !secure {
+		return weak, fmt.Errorf("cannot disable weak cipher suites %v, no secure cipher suite is enabled", weak)
+	}
+
+	log.Info(ctx, "Disabling weak cipher suites", "suites", weak)
+	for i := range csps {
+		if ipmi.WeakCipherSuite(csps[i].ID) {
+			csps[i].MaxPrivilege = ipmi.PrivilegeUnspecified
+		}
+	}
+	err = s.SetCipherSuitePrivileges(ctx, csps)
+	if err != nil {
+		return weak, fmt.Errorf("cannot disable weak cipher suites: %w", err)
+	}
+	return nil, nil
+}
+
+func (b *IPMIBMC) chassisControl(ctx context.Context, c ipmi.ChassisControl) error {
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
+	if err != nil {
+		return err
+	}
+	defer ipmiClose(ctx, s)
+
+	return s.ChassisControl(ctx, c)
+}
+
 func (b *IPMIBMC) PowerOn(ctx context.Context) error {
 	log.Debug(ctx, "Powering on the machine")
-	_, _, err := ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "chassis", "power", "on")
+	err := b.chassisControl(ctx, ipmi.ChassisPowerUp)
 	if err != nil {
 		return fmt.Errorf("unable to power on the server %s: %w", b.host, err)
 	}
@@ -378,22 +491,36 @@ func (b *IPMIBMC) Reset(ctx context.Context, immediate bool) error {
 		return fmt.Errorf("unable to reset the server gracefully")
 	}
 
-	_, _, err := ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "chassis", "power", "reset")
+	err := b.chassisControl(ctx, ipmi.ChassisHardReset)
 	if err != nil {
 		return fmt.Errorf("unable to reset the server %s: %w", b.host, err)
 	}
 	return nil
 }
 
+func (b *IPMIBMC) ResetManager(ctx context.Context) error {
+	log.Debug(ctx, "Resetting the manager")
+	s, err := ipmiConnect(ctx, b.tags, b.host, b.port, b.creds)
+	if err != nil {
+		return err
+	}
+	// The session ends with the reset.
+	err = s.ColdReset(ctx)
+	if err != nil {
+		ipmiClose(ctx, s)
+		return fmt.Errorf("unable to reset the BMC %s: %w", b.host, err)
+	}
+
+	return nil
+}
+
 func (b *IPMIBMC) PowerOff(ctx context.Context, immediate bool) error {
 	log.Debug(ctx, "Powering off the machine")
-	how := ""
+	c := ipmi.ChassisSoftShutdown
 	if immediate {
-		how = "off"
-	} else {
-		how = "soft"
+		c = ipmi.ChassisPowerDown
 	}
-	_, _, err := ipmiExecuteCommand(ctx, b.host, b.port, b.creds, "ipmitool", "chassis", "power", how)
+	err := b.chassisControl(ctx, c)
 	if err != nil {
 		return fmt.Errorf("unable to power off the system: %w", err)
 	}
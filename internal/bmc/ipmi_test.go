@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ironcore-dev/metal/internal/ipmi"
+)
+
+var _ = Describe("IPMI BMC", func() {
+	var r *ipmi.Responder
+	var b BMC
+
+	BeforeEach(func() {
+		By("Starting a BMC")
+		var err error
+		r, err = ipmi.NewResponder("ADMIN", "ADMIN")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(r.Close)
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{}, time.Time{})
+	})
+
+	It("should find the default credentials", func(ctx SpecContext) {
+		Expect(b.EnsureInitialCredentials(ctx, []Credentials{
+			{Username: "root", Password: "calvin"},
+			{Username: "ADMIN", Password: "ADMIN"},
+		}, "temporary")).To(Succeed())
+		creds, _ := b.Credentials()
+		Expect(creds).To(Equal(Credentials{Username: "ADMIN", Password: "ADMIN"}))
+		Expect(b.Connect(ctx)).To(Succeed())
+	})
+
+	It("should report failed authentication", func(ctx SpecContext) {
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "wrong"}, time.Time{})
+		Expect(b.Connect(ctx)).To(MatchError(ErrAuthFailed))
+	})
+
+	It("should create and delete users", func(ctx SpecContext) {
+		Expect(b.EnsureInitialCredentials(ctx, []Credentials{{Username: "ADMIN", Password: "ADMIN"}}, "temporary")).To(Succeed())
+
+		By("Creating a user")
+		Expect(b.CreateUser(ctx, Credentials{Username: "metal-abc", Password: "secret"}, "")).To(Succeed())
+		creds, _ := b.Credentials()
+		Expect(creds).To(Equal(Credentials{Username: "metal-abc", Password: "secret"}))
+		Expect(b.Connect(ctx)).To(Succeed())
+		Expect(r.Users()[2]).To(Equal(ipmi.ResponderUser{
+			Name:      "metal-abc",
+			Password:  "secret",
+			Enabled:   true,
+			Privilege: ipmi.PrivilegeAdministrator,
+		}))
+
+		By("Creating another user")
+		Expect(b.CreateUser(ctx, Credentials{Username: "metal-def", Password: "secret2"}, "")).To(Succeed())
+
+		By("Deleting all users but the current one")
+		Expect(b.DeleteUsers(ctx, regexp.MustCompile(`^metal-`))).To(Succeed())
+		var names []string
+		for _, u := range r.Users() {
+			if u.Name != "" {
+				names = append(names, u.Name)
+			}
+		}
+		Expect(names).To(ConsistOf("ADMIN", "metal-def"))
+	})
+
+	It("should read the BMC info", func(ctx SpecContext) {
+		r.SetFRU(ipmi.FRU{
+			BoardManufacturer:   "Supermicro",
+			BoardSerialNumber:   "BSN-1",
+			ProductPartNumber:   "SYS-1029U",
+			ProductSerialNumber: "SN-1",
+		})
+		r.SetPower(true)
+
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.UUID).To(Equal("01234567-89ab-cdef-0123-456789abcdef"))
+		Expect(info.Manufacturer).To(Equal("Supermicro"))
+		Expect(info.SKU).To(Equal("SYS-1029U"))
+		Expect(info.SerialNumber).To(Equal("SN-1"))
+		Expect(info.Power).To(Equal("On"))
+		Expect(info.FWVersion).To(Equal("1.23"))
+		Expect(info.Capabilities).To(ConsistOf("credentials", "power", "led", "console"))
+		Expect(info.LocatorLED).To(Equal("Off"))
+		Expect(info.Console).To(Equal("ipmi"))
+	})
+
+	It("should only report the capabilities of the BMC", func(ctx SpecContext) {
+		r.SetIdentifySupported(false)
+		r.SetSOL(false)
+
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Capabilities).To(ConsistOf("credentials", "power"))
+		Expect(info.LocatorLED).To(BeEmpty())
+		Expect(info.Console).To(BeEmpty())
+	})
+
+	It("should control the locator LED", func(ctx SpecContext) {
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		lc, ok := b.(LEDControl)
+		Expect(ok).To(BeTrue())
+
+		Expect(lc.SetLocatorLED(ctx, "On")).To(Equal("On"))
+		Expect(r.Identify()).To(Equal(ipmi.IdentifyIndefinite))
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.LocatorLED).To(Equal("On"))
+
+		Expect(lc.SetLocatorLED(ctx, "Off")).To(Equal("Off"))
+		Expect(r.Identify()).To(Equal(ipmi.IdentifyOff))
+
+		_, err = lc.SetLocatorLED(ctx, "Blinking")
+		Expect(err).To(MatchError(ErrUnsupported))
+	})
+
+	It("should report and disable weak cipher suites", func(ctx SpecContext) {
+		r.SetCipherSuites(0, 1, 3, 17)
+
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		csc, ok := b.(CipherSuiteControl)
+		Expect(ok).To(BeTrue())
+		Expect(csc.EnsureCipherSuites(ctx)).To(Equal([]int{0, 1}))
+
+		b = ipmiBMC(map[string]string{FlagIPMIDisableWeakCipherSuites: "true"}, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		csc, ok = b.(CipherSuiteControl)
+		Expect(ok).To(BeTrue())
+		Expect(csc.EnsureCipherSuites(ctx)).To(BeEmpty())
+		Expect(r.CipherSuites()).To(Equal([]ipmi.CipherSuitePrivilege{
+			{ID: 0, MaxPrivilege: ipmi.PrivilegeUnspecified},
+			{ID: 1, MaxPrivilege: ipmi.PrivilegeUnspecified},
+			{ID: 3, MaxPrivilege: ipmi.PrivilegeAdministrator},
+			{ID: 17, MaxPrivilege: ipmi.PrivilegeAdministrator},
+		}))
+	})
+
+	It("should not disable the only enabled cipher suites", func(ctx SpecContext) {
+		r.SetCipherSuites(0, 2)
+
+		b = ipmiBMC(map[string]string{FlagIPMIDisableWeakCipherSuites: "true"}, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		weak, err := b.(CipherSuiteControl).EnsureCipherSuites(ctx)
+		Expect(err).To(MatchError(ContainSubstring("no secure cipher suite is enabled")))
+		Expect(weak).To(Equal([]int{0, 2}))
+		Expect(r.CipherSuites()).To(HaveEach(HaveField("MaxPrivilege", ipmi.PrivilegeAdministrator)))
+	})
+
+	It("should control the power", func(ctx SpecContext) {
+		b = ipmiBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "ADMIN", Password: "ADMIN"}, time.Time{})
+		p, ok := b.(interface{ PowerControl() PowerControl })
+		Expect(ok).To(BeTrue())
+
+		Expect(p.PowerControl().PowerOn(ctx)).To(Succeed())
+		Expect(r.Power()).To(BeTrue())
+		Expect(p.PowerControl().PowerOff(ctx, true)).To(Succeed())
+		Expect(r.Power()).To(BeFalse())
+	})
+})
@@ -5,14 +5,19 @@ package bmc
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -40,11 +45,13 @@ func redfishBMC(tags map[string]string, host string, port int, creds Credentials
 }
 
 type RedfishBMC struct {
-	tags  map[string]string
-	host  string
-	port  int
-	creds Credentials
-	exp   time.Time
+	tags   map[string]string
+	host   string
+	port   int
+	creds  Credentials
+	exp    time.Time
+	system string
+	trust  TLSTrust
 }
 
 func (b *RedfishBMC) Type() string {
@@ -67,10 +74,117 @@ func (b *RedfishBMC) ResetControl() ResetControl {
 	return b
 }
 
+func (b *RedfishBMC) ManagerResetControl() ManagerResetControl {
+	return b
+}
+
 func (b *RedfishBMC) Credentials() (Credentials, time.Time) {
 	return b.creds, b.exp
 }
 
+func (b *RedfishBMC) SelectSystem(id string) {
+	b.system = id
+}
+
+func (b *RedfishBMC) SetTLSTrust(trust TLSTrust) {
+	b.trust = trust
+}
+
+func (b *RedfishBMC) CertificateFingerprint(ctx context.Context) (string, error) {
+	cert, err := b.Certificate(ctx)
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(cert), nil
+}
+
+func (b *RedfishBMC) Certificate(ctx context.Context) (*x509.Certificate, error) {
+	d := tls.Dialer{
+		NetDialer: &net.Dialer{
+			Timeout: 10 * time.Second,
+		},
+		Config: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+		},
+	}
+	conn, err := d.DialContext(ctx, "tcp", strings.TrimPrefix(redfishEndpoint(b.host, b.port), "https://"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect: %w", redfishClassifyError(err))
+	}
+	defer func() { must(ctx, conn.Close()) }()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("BMC presents no certificate")
+	}
+	return certs[0], nil
+}
+
+// redfishSystem returns the system with the given ID, or the first system if the ID is empty. All
+// systems are returned sorted by ID, as gofish reads the members of a collection concurrently.
+func redfishSystem(c *gofish.APIClient, id string) (*redfish.ComputerSystem, []*redfish.ComputerSystem, error) {
+	systems, err := c.Service.Systems()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get systems: %w", err)
+	}
+	slices.SortFunc(systems, func(a, b *redfish.ComputerSystem) int {
+		return redfishCompareIDs(a.ID, b.ID)
+	})
+	if len(systems) == 0 {
+		return nil, nil, fmt.Errorf("BMC has no systems")
+	}
+	if id == "" {
+		return systems[0], systems, nil
+	}
+	i := slices.IndexFunc(systems, func(s *redfish.ComputerSystem) bool {
+		return s.ID == id
+	})
+	if i < 0 {
+		return nil, nil, fmt.Errorf("BMC has no system %s", id)
+	}
+	return systems[i], systems, nil
+}
+
+// redfishCompareIDs orders numeric IDs by their value, so that system 10 follows system 2, and all
+// other IDs as strings after them.
+func redfishCompareIDs(a, b string) int {
+	na, erra := strconv.ParseUint(a, 10, 64)
+	nb, errb := strconv.ParseUint(b, 10, 64)
+	switch {
+	case erra == nil && errb == nil:
+		return cmp.Or(cmp.Compare(na, nb), strings.Compare(a, b))
+	case erra == nil:
+		return -1
+	case errb == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// redfishManager returns the first manager of the system, or the first manager of the BMC if the
+// system does not link to one. It returns nil if the BMC has no managers.
+func redfishManager(c *gofish.APIClient, sys *redfish.ComputerSystem) (*redfish.Manager, error) {
+	managers, err := c.Service.Managers()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get managers: %w", err)
+	}
+	if len(managers) == 0 {
+		return nil, nil
+	}
+	slices.SortFunc(managers, func(a, b *redfish.Manager) int {
+		return redfishCompareIDs(a.ID, b.ID)
+	})
+	if sys != nil {
+		for _, m := range managers {
+			if slices.Contains(sys.ManagedBy, m.ODataID) {
+				return m, nil
+			}
+		}
+	}
+	return managers[0], nil
+}
+
 var (
 	userIdRegex = regexp.MustCompile(`/redfish/v1/AccountService/Accounts/([0-9]{1,2})`)
 )
@@ -98,27 +212,129 @@ type redfishUserOEM struct {
 	} `json:"Hp,omitempty"`
 }
 
-func redfishConnect(ctx context.Context, host string, port int, creds Credentials) (*gofish.APIClient, error) {
+func redfishConnect(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, creds Credentials) (*gofish.APIClient, error) {
 	log.Debug(ctx, "Connecting", "host", host, "user", creds.Username)
 
-	if port == 0 {
-		port = 443
+	hc, err := redfishHTTPClient(tags, trust)
+	if err != nil {
+		return nil, err
 	}
-
-	hostAndPort := net.JoinHostPort(host, strconv.Itoa(port))
 	config := gofish.ClientConfig{
-		Endpoint: fmt.Sprintf("https://%s", hostAndPort),
-		Username: creds.Username,
-		Password: creds.Password,
-		Insecure: true,
+		Endpoint:   redfishEndpoint(host, port),
+		Username:   creds.Username,
+		Password:   creds.Password,
+		HTTPClient: hc,
+		BasicAuth:  flagValue(tags, FlagRedfishAuth) == "basic",
 	}
 	c, err := gofish.Connect(config)
 	if err != nil {
-		return nil, fmt.Errorf("cannot connect: %w", err)
+		return nil, fmt.Errorf("cannot connect: %w", redfishClassifyError(err))
 	}
 	return c, nil
 }
 
+func redfishEndpoint(host string, port int) string {
+	if port == 0 {
+		port = 443
+	}
+	return fmt.Sprintf("https://%s", net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+func redfishHTTPClient(tags map[string]string, trust TLSTrust) (*http.Client, error) {
+	tlsConfig, err := redfishTLSConfig(tags, trust)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSHandshakeTimeout: 10 * time.Second,
+			TLSClientConfig:     tlsConfig,
+		},
+		Timeout: durationFlag(tags, FlagRedfishTimeout),
+	}, nil
+}
+
+// redfishTLSConfig verifies the certificate of the BMC according to the TLS policy of the flags.
+func redfishTLSConfig(tags map[string]string, trust TLSTrust) (*tls.Config, error) {
+	switch RedfishTLSPolicy(tags) {
+	case TLSPolicySystem:
+		return &tls.Config{}, nil
+	case TLSPolicyCA:
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(trust.CA) {
+			return nil, fmt.Errorf("%w: no valid CA certificates", ErrUntrustedCertificate)
+		}
+		return &tls.Config{
+			RootCAs: pool,
+		}, nil
+	case TLSPolicyPin:
+		if trust.Fingerprint == "" {
+			return nil, fmt.Errorf("%w: no certificate is pinned", ErrUntrustedCertificate)
+		}
+		return &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				if len(cs.PeerCertificates) == 0 {
+					return ErrCertificateChanged
+				}
+				fp := Fingerprint(cs.PeerCertificates[0])
+				if fp != trust.Fingerprint && (trust.PendingFingerprint == "" || fp != trust.PendingFingerprint) {
+					return ErrCertificateChanged
+				}
+				return nil
+			},
+		}, nil
+	default:
+		return &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+		}, nil
+	}
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate in hex, as pinned by the pin policy.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// redfishStatusCode returns the HTTP status of an error returned by gofish, or 0. Errors of the
+// members of a collection are reported in a CollectionError.
+func redfishStatusCode(err error) int {
+	var rerr *common.Error
+	if errors.As(err, &rerr) {
+		return rerr.HTTPReturnedStatusCode
+	}
+	var cerr *common.CollectionError
+	if errors.As(err, &cerr) {
+		for _, e := range cerr.Failures {
+			code := redfishStatusCode(e)
+			if code != 0 {
+				return code
+			}
+		}
+	}
+	return 0
+}
+
+func redfishClassifyError(err error) error {
+	if redfishStatusCode(err) == 401 {
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+	}
+	var verr *tls.CertificateVerificationError
+	if errors.As(err, &verr) {
+		return fmt.Errorf("%w: %w", ErrUntrustedCertificate, err)
+	}
+	if errors.Is(err, ErrUntrustedCertificate) || errors.Is(err, ErrCertificateChanged) {
+		return err
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	return err
+}
+
 func redfishGetUserIdFromError(rerr *common.Error) string {
 	for _, info := range rerr.ExtendedInfos {
 		for _, arg := range info.MessageArgs {
@@ -131,7 +347,7 @@ func redfishGetUserIdFromError(rerr *common.Error) string {
 	return ""
 }
 
-func redfishFindWorkingCredentials(ctx context.Context, host string, port int, defaultCreds []Credentials, tempPassword string) (Credentials, string, error) {
+func redfishFindWorkingCredentials(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, defaultCreds []Credentials, tempPassword string) (Credentials, string, error) {
 	if len(defaultCreds) == 0 {
 		return Credentials{}, "", fmt.Errorf("no default credentials to try")
 	}
@@ -139,7 +355,7 @@ func redfishFindWorkingCredentials(ctx context.Context, host string, port int, d
 	var merr error
 	var rerr *common.Error
 	for _, creds := range defaultCreds {
-		c, err := redfishConnect(ctx, host, port, creds)
+		c, err := redfishConnect(ctx, tags, trust, host, port, creds)
 		if err == nil {
 			// TODO: optimize this
 			// try now to get service accounts
@@ -156,7 +372,7 @@ func redfishFindWorkingCredentials(ctx context.Context, host string, port int, d
 		merr = multierror.Append(merr, err)
 	}
 	for _, creds := range defaultCreds {
-		c, err := redfishConnect(ctx, host, port, Credentials{creds.Username, tempPassword})
+		c, err := redfishConnect(ctx, tags, trust, host, port, Credentials{creds.Username, tempPassword})
 		if err == nil {
 			c.Logout()
 			return creds, "", nil
@@ -168,7 +384,11 @@ func redfishFindWorkingCredentials(ctx context.Context, host string, port int, d
 	return Credentials{}, "", fmt.Errorf("cannot connect using any predefined credentials: %w", merr)
 }
 
-func redfishChangePasswordRaw(ctx context.Context, host string, port int, id, user, oldPassword, newPassword string) error {
+func redfishChangePasswordRaw(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, id, user, oldPassword, newPassword string) error {
+	return redact(redfishChangePassword(ctx, tags, trust, host, port, id, user, oldPassword, newPassword), oldPassword, newPassword)
+}
+
+func redfishChangePassword(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, id, user, oldPassword, newPassword string) error {
 	if port == 0 {
 		port = 443
 	}
@@ -189,10 +409,13 @@ func redfishChangePasswordRaw(ctx context.Context, host string, port int, id, us
 	req.Header.Set("Accept", "*/*")
 	req.Header.Set("User-Agent", "gofish/1.0")
 
-	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
-	response, err := client.Do(req)
+	hc, err := redfishHTTPClient(tags, trust)
+	if err != nil {
+		return err
+	}
+	response, err := hc.Do(req)
 	if err != nil {
-		return fmt.Errorf("cannot perform PATCH request: %w", err)
+		return fmt.Errorf("cannot perform PATCH request: %w", redfishClassifyError(err))
 	}
 	defer func() { must(ctx, response.Body.Close()) }()
 	if response.StatusCode != 200 {
@@ -203,14 +426,14 @@ func redfishChangePasswordRaw(ctx context.Context, host string, port int, id, us
 }
 
 func (b *RedfishBMC) EnsureInitialCredentials(ctx context.Context, defaultCreds []Credentials, tempPassword string) error {
-	creds, pwChangeID, err := redfishFindWorkingCredentials(ctx, b.host, b.port, defaultCreds, tempPassword)
+	creds, pwChangeID, err := redfishFindWorkingCredentials(ctx, b.tags, b.trust, b.host, b.port, defaultCreds, tempPassword)
 	if err != nil {
-		return fmt.Errorf("cannot obtain initial credentials: %w", err)
+		return fmt.Errorf("cannot obtain initial credentials: %w", redact(err, passwords(defaultCreds, tempPassword)...))
 	}
 
 	if pwChangeID != "" {
 		log.Debug(ctx, "Initial password change required", "user", creds.Username)
-		err := redfishChangePasswordRaw(ctx, b.host, b.port, pwChangeID, creds.Username, creds.Password, tempPassword)
+		err := redfishChangePasswordRaw(ctx, b.tags, b.trust, b.host, b.port, pwChangeID, creds.Username, creds.Password, tempPassword)
 		if err != nil {
 			return fmt.Errorf("cannot change password for user %s: %w", creds.Username, err)
 		}
@@ -223,12 +446,15 @@ func (b *RedfishBMC) EnsureInitialCredentials(ctx context.Context, defaultCreds
 }
 
 func (b *RedfishBMC) Connect(ctx context.Context) error {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
-	if err != nil {
-		return err
-	}
-	c.Logout()
-	return nil
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		// A cached session may have expired on the BMC, so it has to be used to check the credentials.
+		response, err := c.Get("/redfish/v1/Systems")
+		if err != nil {
+			return fmt.Errorf("cannot connect: %w", redfishClassifyError(err))
+		}
+		must(ctx, response.Body.Close())
+		return nil
+	})
 }
 
 func redfishGetAccounts(c *gofish.APIClient) ([]*redfish.ManagerAccount, error) {
@@ -254,7 +480,7 @@ func redfishGetUserID(accounts []*redfish.ManagerAccount, user string) string {
 	return ""
 }
 
-func redfishWaitForUser(ctx context.Context, c *gofish.APIClient, host string, port int, creds Credentials, id string) (string, error) {
+func redfishWaitForUser(ctx context.Context, c *gofish.APIClient, tags map[string]string, trust TLSTrust, host string, port int, creds Credentials, id string) (string, error) {
 	var sCreds []Credentials
 	sCreds = append(sCreds, creds)
 
@@ -287,7 +513,7 @@ func redfishWaitForUser(ctx context.Context, c *gofish.APIClient, host string, p
 	}
 
 	for t := 0; t < TIMEOUT; t = t + 5 {
-		_, pwChangeID, err := redfishFindWorkingCredentials(ctx, host, port, sCreds, creds.Password)
+		_, pwChangeID, err := redfishFindWorkingCredentials(ctx, tags, trust, host, port, sCreds, creds.Password)
 		if err != nil {
 			time.Sleep(5 * time.Second)
 			continue
@@ -413,13 +639,17 @@ func redfishCreateUserPatch(ctx context.Context, c *gofish.APIClient, slot strin
 //	return fmt.Errorf("user %s does not exist", user)
 //}
 
-func redfishGetPasswordExpirationRaw(ctx context.Context, host string, port int, creds Credentials, id string) (time.Time, error) {
-	c, err := redfishConnect(ctx, host, port, creds)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("cannot connect: %w", err)
-	}
-	defer c.Logout()
+func redfishGetPasswordExpirationRaw(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, creds Credentials, id string) (time.Time, error) {
+	var exp time.Time
+	err := redfishDo(ctx, tags, trust, host, port, creds, func(c *gofish.APIClient) error {
+		var err error
+		exp, err = redfishGetPasswordExpiration(ctx, c, id)
+		return err
+	})
+	return exp, err
+}
 
+func redfishGetPasswordExpiration(ctx context.Context, c *gofish.APIClient, id string) (time.Time, error) {
 	endpoint := fmt.Sprintf("/redfish/v1/AccountService/Accounts/%s", id)
 	log.Debug(ctx, "Getting password expiration", "endpoint", endpoint)
 
@@ -478,68 +708,21 @@ func redfishGetPasswordExpirationRaw(ctx context.Context, host string, port int,
 	return time.Time{}, nil
 }
 
-//func redfishGetPasswordExpiration(ctx context.Context, c *gofish.APIClient, id string) (time.Time, error) {
-//	endpoint := fmt.Sprintf("/redfish/v1/AccountService/Accounts/%s", id)
-//	log.Debug(ctx, "Getting password expiration", "endpoint", endpoint)
-//
-//	t := time.Now()
-//
-//	response, err := c.Get(endpoint)
-//	if err != nil {
-//		return time.Time{}, fmt.Errorf("cannot perform GET request: %w", err)
-//	}
-//	defer func() { must(ctx, response.Body.Close()) }()
-//
-//	var expUser struct {
-//		PasswordExpiration *string
-//	}
-//	err = json.NewDecoder(response.Body).Decode(&expUser)
-//	if err != nil {
-//		return time.Time{}, fmt.Errorf("cannot decode response body: %w", err)
-//	}
-//
-//	if expUser.PasswordExpiration != nil {
-//		return time.Time{}, fmt.Errorf("implement parsing of password expiration and remove this error: %s", *expUser.PasswordExpiration)
-//	}
-//
-//	// check if there is a general expiration set that can't be read from the user itself
-//	endpoint = "/redfish/v1/AccountService"
-//	log.Debug(ctx, "Getting password expiration", "endpoint", endpoint)
-//	response, err = c.Get(endpoint)
-//	if err != nil {
-//		return time.Time{}, fmt.Errorf("cannot perform GET request: %w", err)
-//	}
-//	defer func() { must(ctx, response.Body.Close()) }()
-//
-//	var expOem struct {
-//		Oem struct {
-//			Lenovo struct {
-//				PasswordExpirationPeriodDays *int
-//			}
-//		}
-//	}
-//	err = json.NewDecoder(response.Body).Decode(&expOem)
-//	if err != nil {
-//		return time.Time{}, fmt.Errorf("cannot decode response body: %w", err)
-//	}
-//
-//	if expOem.Oem.Lenovo.PasswordExpirationPeriodDays != nil {
-//		if *expOem.Oem.Lenovo.PasswordExpirationPeriodDays == 0 {
-//			return time.Time{}, nil
-//		}
-//		return t.AddDate(0, 0, *expOem.Oem.Lenovo.PasswordExpirationPeriodDays), nil
-//	}
-//
-//	return time.Time{}, nil
-//}
-
 func (b *RedfishBMC) CreateUser(ctx context.Context, creds Credentials, tempPassword string) error {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
+	prev := b.creds
+	err := redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.createUser(ctx, c, creds, tempPassword)
+	})
 	if err != nil {
-		return fmt.Errorf("cannot connect: %w", err)
+		return redact(err, prev.Password, creds.Password, tempPassword)
 	}
-	defer c.Logout()
 
+	// The previous user is deleted once the new credentials are stored, so its session is not reused.
+	redfishSessions.evict(ctx, b.tags, b.trust, b.host, b.port, prev)
+	return nil
+}
+
+func (b *RedfishBMC) createUser(ctx context.Context, c *gofish.APIClient, creds Credentials, tempPassword string) error {
 	accounts, err := redfishGetAccounts(c)
 	if err != nil {
 		return fmt.Errorf("cannot generate the list of accounts: %w", err)
@@ -567,7 +750,7 @@ func (b *RedfishBMC) CreateUser(ctx context.Context, creds Credentials, tempPass
 		}
 	}
 
-	pwChangeID, err := redfishWaitForUser(ctx, c, b.host, b.port, creds, id)
+	pwChangeID, err := redfishWaitForUser(ctx, c, b.tags, b.trust, b.host, b.port, creds, id)
 	if err != nil {
 		var merr error
 		merr = multierror.Append(merr, err)
@@ -575,7 +758,7 @@ func (b *RedfishBMC) CreateUser(ctx context.Context, creds Credentials, tempPass
 	}
 
 	if pwChangeID != "" {
-		err := redfishChangePasswordRaw(ctx, b.host, b.port, pwChangeID, creds.Username, creds.Password, tempPassword)
+		err := redfishChangePasswordRaw(ctx, b.tags, b.trust, b.host, b.port, pwChangeID, creds.Username, creds.Password, tempPassword)
 		if err != nil {
 			return fmt.Errorf("cannot change password for user %s: %w", creds.Username, err)
 		}
@@ -583,7 +766,7 @@ func (b *RedfishBMC) CreateUser(ctx context.Context, creds Credentials, tempPass
 		creds.Password = tempPassword
 	}
 
-	exp, err := redfishGetPasswordExpirationRaw(ctx, b.host, b.port, creds, id)
+	exp, err := redfishGetPasswordExpirationRaw(ctx, b.tags, b.trust, b.host, b.port, creds, id)
 	if err != nil {
 		return fmt.Errorf("cannot determine password expiration: %w", err)
 	}
@@ -594,29 +777,38 @@ func (b *RedfishBMC) CreateUser(ctx context.Context, creds Credentials, tempPass
 }
 
 func (b *RedfishBMC) ReadInfo(ctx context.Context) (Info, error) {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
-	if err != nil {
-		return Info{}, fmt.Errorf("cannot connect: %w", err)
-	}
-	defer c.Logout()
+	var res Info
+	err := redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		var err error
+		res, err = b.readInfo(ctx, c)
+		return err
+	})
+	return res, err
+}
 
+func (b *RedfishBMC) readInfo(ctx context.Context, c *gofish.APIClient) (Info, error) {
 	log.Debug(ctx, "Reading BMC info")
-	systems, err := c.Service.Systems()
+	sys, systems, err := redfishSystem(c, b.system)
 	if err != nil {
 		return Info{}, fmt.Errorf("cannot get systems information: %w", err)
 	}
-	if len(systems) == 0 {
-		return Info{}, fmt.Errorf("cannot get systems information")
-	}
 
-	uuid := systems[0].UUID
+	uuid := sys.UUID
 	if uuid == "" {
 		return Info{}, fmt.Errorf("BMC has no UUID attribute")
 	}
 	uuid = strings.ToLower(uuid)
 
+	sysInfos := make([]SystemInfo, 0, len(systems))
+	for _, s := range systems {
+		sysInfos = append(sysInfos, SystemInfo{
+			ID:   s.ID,
+			UUID: strings.ToLower(s.UUID),
+		})
+	}
+
 	var led string
-	switch led = string(systems[0].IndicatorLED); led {
+	switch led = string(sys.IndicatorLED); led {
 	case "Blinking":
 		led = "Blinking"
 	case "Lit":
@@ -629,50 +821,47 @@ func (b *RedfishBMC) ReadInfo(ctx context.Context) (Info, error) {
 
 	// Reading the OS state is supported only on Lenovo hardware
 	var os, osReason string
-	if len(systems) > 0 {
-		sys := systems[0]
-		sysRaw, err := c.Get(sys.ODataID)
-		if err != nil {
-			return Info{}, fmt.Errorf("cannot get systems (raw): %w", err)
-		}
-		osStatus := struct {
-			OEM struct {
-				Lenovo struct {
-					SystemStatus *string `json:"SystemStatus"`
-				} `json:"Lenovo"`
-			} `json:"OEM"`
-		}{}
-
-		decoder := json.NewDecoder(sysRaw.Body)
-		err = decoder.Decode(&osStatus)
-		if err != nil {
-			return Info{}, fmt.Errorf("cannot decode information for OS status: %w", err)
-		}
+	sysRaw, err := c.Get(sys.ODataID)
+	if err != nil {
+		return Info{}, fmt.Errorf("cannot get systems (raw): %w", err)
+	}
+	osStatus := struct {
+		OEM struct {
+			Lenovo struct {
+				SystemStatus *string `json:"SystemStatus"`
+			} `json:"Lenovo"`
+		} `json:"OEM"`
+	}{}
 
-		if osStatus.OEM.Lenovo.SystemStatus != nil {
-			if sys.PowerState == redfish.OffPowerState {
-				osReason = "PoweredOff"
-			} else if state := *osStatus.OEM.Lenovo.SystemStatus; state == "OSBooted" || state == "BootingOSOrInUndetectedOS" {
-				os = "Ok"
-				osReason = state
-			} else {
-				osReason = state
-			}
+	decoder := json.NewDecoder(sysRaw.Body)
+	err = decoder.Decode(&osStatus)
+	if err != nil {
+		return Info{}, fmt.Errorf("cannot decode information for OS status: %w", err)
+	}
+
+	if osStatus.OEM.Lenovo.SystemStatus != nil {
+		if sys.PowerState == redfish.OffPowerState {
+			osReason = "PoweredOff"
+		} else if state := *osStatus.OEM.Lenovo.SystemStatus; state == "OSBooted" || state == "BootingOSOrInUndetectedOS" {
+			os = "Ok"
+			osReason = state
+		} else {
+			osReason = state
 		}
 	}
 
-	manufacturer := systems[0].Manufacturer
+	manufacturer := sys.Manufacturer
 	capabilities := []string{"credentials", "power", "led"}
 	console := ""
 	fw := ""
 
-	mgr, err := c.Service.Managers()
+	mgr, err := redfishManager(c, sys)
 	if err != nil {
-		return Info{}, fmt.Errorf("cannot get managers: %w", err)
+		return Info{}, err
 	}
-	if len(mgr) > 0 {
-		consoleList := mgr[0].SerialConsole.ConnectTypesSupported
-		if mgr[0].SerialConsole.ServiceEnabled {
+	if mgr != nil {
+		consoleList := mgr.SerialConsole.ConnectTypesSupported
+		if mgr.SerialConsole.ServiceEnabled {
 			if strings.ToLower(manufacturer) == "lenovo" && isConsoleTypeSupported(consoleList, redfish.SSHSerialConnectTypesSupported) {
 				capabilities = append(capabilities, "console")
 				console = "ssh-lenovo"
@@ -680,7 +869,7 @@ func (b *RedfishBMC) ReadInfo(ctx context.Context) (Info, error) {
 				capabilities = append(capabilities, "console")
 				console = "ipmi"
 			}
-			fw = mgr[0].FirmwareVersion
+			fw = mgr.FirmwareVersion
 		}
 	}
 
@@ -688,15 +877,16 @@ func (b *RedfishBMC) ReadInfo(ctx context.Context) (Info, error) {
 		UUID:         uuid,
 		Type:         "BMC",
 		Capabilities: capabilities,
-		SerialNumber: systems[0].SerialNumber,
-		SKU:          systems[0].SKU,
+		SerialNumber: sys.SerialNumber,
+		SKU:          sys.SKU,
 		Manufacturer: manufacturer,
 		LocatorLED:   led,
-		Power:        fmt.Sprintf("%v", systems[0].PowerState),
+		Power:        fmt.Sprintf("%v", sys.PowerState),
 		OS:           os,
 		OSReason:     osReason,
 		Console:      console,
 		FWVersion:    fw,
+		Systems:      sysInfos,
 	}, nil
 }
 
@@ -710,18 +900,21 @@ func isConsoleTypeSupported(consoleList []redfish.SerialConnectTypesSupported, c
 }
 
 func (b *RedfishBMC) SetLocatorLED(ctx context.Context, state string) (string, error) {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
-	if err != nil {
-		return "", fmt.Errorf("cannot connect: %w", err)
-	}
-	defer c.Logout()
+	var res string
+	err := redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		var err error
+		res, err = b.setLocatorLED(ctx, c, state)
+		return err
+	})
+	return res, err
+}
 
+func (b *RedfishBMC) setLocatorLED(ctx context.Context, c *gofish.APIClient, state string) (string, error) {
 	log.Debug(ctx, "Setting the LED on the machine")
 
-	systems, err := c.Service.Systems()
-
+	sys, _, err := redfishSystem(c, b.system)
 	if err != nil {
-		return "", fmt.Errorf("unable to get the systems: %w", err)
+		return "", fmt.Errorf("unable to get the system: %w", err)
 	}
 
 	var ledState common.IndicatorLED
@@ -733,11 +926,11 @@ func (b *RedfishBMC) SetLocatorLED(ctx context.Context, state string) (string, e
 	case "Off":
 		ledState = common.OffIndicatorLED
 	default:
-		return "", fmt.Errorf("unable to set LED state to unknown")
+		return "", fmt.Errorf("unable to set LED state to %s: %w", state, ErrUnsupported)
 	}
 
-	systems[0].IndicatorLED = ledState
-	err = systems[0].Update()
+	sys.IndicatorLED = ledState
+	err = sys.Update()
 
 	if err != nil {
 		return "", fmt.Errorf("unable to set the LED: %w", err)
@@ -747,21 +940,20 @@ func (b *RedfishBMC) SetLocatorLED(ctx context.Context, state string) (string, e
 }
 
 func (b *RedfishBMC) PowerOn(ctx context.Context) error {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
-	if err != nil {
-		return fmt.Errorf("cannot connect: %w", err)
-	}
-	defer c.Logout()
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.powerOn(ctx, c)
+	})
+}
 
+func (b *RedfishBMC) powerOn(ctx context.Context, c *gofish.APIClient) error {
 	log.Debug(ctx, "Powering on the machine")
 
-	systems, err := c.Service.Systems()
-
+	sys, _, err := redfishSystem(c, b.system)
 	if err != nil {
-		return fmt.Errorf("unable to get the systems: %w", err)
+		return fmt.Errorf("unable to get the system: %w", err)
 	}
 
-	err = systems[0].Reset(redfish.OnResetType)
+	err = sys.Reset(redfish.OnResetType)
 	if err != nil {
 		return fmt.Errorf("unable to power on the system: %w", err)
 	}
@@ -770,24 +962,23 @@ func (b *RedfishBMC) PowerOn(ctx context.Context) error {
 }
 
 func (b *RedfishBMC) Reset(ctx context.Context, immediate bool) error {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
-	if err != nil {
-		return fmt.Errorf("cannot connect: %w", err)
-	}
-	defer c.Logout()
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.reset(ctx, c, immediate)
+	})
+}
 
+func (b *RedfishBMC) reset(ctx context.Context, c *gofish.APIClient, immediate bool) error {
 	log.Debug(ctx, "Resetting the machine")
 
-	systems, err := c.Service.Systems()
-
+	sys, _, err := redfishSystem(c, b.system)
 	if err != nil {
-		return fmt.Errorf("unable to get the systems: %w", err)
+		return fmt.Errorf("unable to get the system: %w", err)
 	}
 
 	if immediate {
-		err = systems[0].Reset(redfish.ForceRestartResetType)
+		err = sys.Reset(redfish.ForceRestartResetType)
 	} else {
-		err = systems[0].Reset(redfish.GracefulRestartResetType)
+		err = sys.Reset(redfish.GracefulRestartResetType)
 	}
 	if err != nil {
 		return fmt.Errorf("unable to reset the system: %w", err)
@@ -796,25 +987,61 @@ func (b *RedfishBMC) Reset(ctx context.Context, immediate bool) error {
 	return nil
 }
 
-func (b *RedfishBMC) PowerOff(ctx context.Context, immediate bool) error {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
+func (b *RedfishBMC) ResetManager(ctx context.Context) error {
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.resetManager(ctx, c)
+	})
+}
+
+func (b *RedfishBMC) resetManager(ctx context.Context, c *gofish.APIClient) error {
+	log.Debug(ctx, "Resetting the manager")
+
+	var sys *redfish.ComputerSystem
+	var err error
+	if b.system != "" {
+		sys, _, err = redfishSystem(c, b.system)
+		if err != nil {
+			return fmt.Errorf("unable to get the system: %w", err)
+		}
+	}
+	mgr, err := redfishManager(c, sys)
 	if err != nil {
-		return fmt.Errorf("cannot connect: %w", err)
+		return fmt.Errorf("unable to get the managers: %w", err)
+	}
+	if mgr == nil {
+		return fmt.Errorf("BMC has no managers")
 	}
-	defer c.Logout()
 
-	log.Debug(ctx, "Powering off the machine")
+	resetType := redfish.ForceRestartResetType
+	if slices.Contains(mgr.SupportedResetTypes, redfish.GracefulRestartResetType) {
+		resetType = redfish.GracefulRestartResetType
+	}
+	err = mgr.Reset(resetType)
+	if err != nil {
+		return fmt.Errorf("unable to reset the manager: %w", err)
+	}
 
-	systems, err := c.Service.Systems()
+	return nil
+}
+
+func (b *RedfishBMC) PowerOff(ctx context.Context, immediate bool) error {
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.powerOff(ctx, c, immediate)
+	})
+}
 
+func (b *RedfishBMC) powerOff(ctx context.Context, c *gofish.APIClient, immediate bool) error {
+	log.Debug(ctx, "Powering off the machine")
+
+	sys, _, err := redfishSystem(c, b.system)
 	if err != nil {
-		return fmt.Errorf("unable to get the systems: %w", err)
+		return fmt.Errorf("unable to get the system: %w", err)
 	}
 
 	if immediate {
-		err = systems[0].Reset(redfish.ForceOffResetType)
+		err = sys.Reset(redfish.ForceOffResetType)
 	} else {
-		err = systems[0].Reset(redfish.GracefulShutdownResetType)
+		err = sys.Reset(redfish.GracefulShutdownResetType)
 	}
 	if err != nil {
 		return fmt.Errorf("unable to power off the system: %w", err)
@@ -824,11 +1051,12 @@ func (b *RedfishBMC) PowerOff(ctx context.Context, immediate bool) error {
 }
 
 func (b *RedfishBMC) DeleteUsers(ctx context.Context, regex *regexp.Regexp) error {
-	c, err := redfishConnect(ctx, b.host, b.port, b.creds)
-	if err != nil {
-		return fmt.Errorf("cannot connect: %w", err)
-	}
-	defer c.Logout()
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.deleteUsers(ctx, c, regex)
+	})
+}
+
+func (b *RedfishBMC) deleteUsers(ctx context.Context, c *gofish.APIClient, regex *regexp.Regexp) error {
 	svc, err := c.Service.AccountService()
 	if err != nil {
 		return fmt.Errorf("cannot get account service: %w", err)
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+
+	"github.com/ironcore-dev/metal/internal/log"
+)
+
+// gofish has no certificate service and does not expose the certificates of the HTTPS protocol of
+// a manager, so the certificate service is used with raw requests.
+
+const redfishCertificateServicePath = "/redfish/v1/CertificateService"
+
+type redfishCertificateService struct {
+	Actions struct {
+		GenerateCSR struct {
+			Target string `json:"target"`
+		} `json:"#CertificateService.GenerateCSR"`
+		ReplaceCertificate struct {
+			Target string `json:"target"`
+		} `json:"#CertificateService.ReplaceCertificate"`
+	}
+}
+
+func (b *RedfishBMC) GenerateCSR(ctx context.Context, commonName string, alternativeNames []string) ([]byte, error) {
+	var csr []byte
+	err := redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		var err error
+		csr, err = b.generateCSR(ctx, c, commonName, alternativeNames)
+		return err
+	})
+	return csr, err
+}
+
+func (b *RedfishBMC) generateCSR(ctx context.Context, c *gofish.APIClient, commonName string, alternativeNames []string) ([]byte, error) {
+	svc, err := redfishGetCertificateService(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if svc.Actions.GenerateCSR.Target == "" {
+		return nil, fmt.Errorf("%w: certificate service cannot generate a CSR", ErrNoCertificateService)
+	}
+
+	coll, err := b.httpsCertificates(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug(ctx, "Generating CSR", "collection", coll)
+	response, err := c.Post(svc.Actions.GenerateCSR.Target, map[string]any{
+		"CertificateCollection": map[string]string{"@odata.id": coll},
+		"CommonName":            commonName,
+		"AlternativeNames":      alternativeNames,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot perform POST request: %w", err)
+	}
+	defer func() { must(ctx, response.Body.Close()) }()
+
+	var csr struct {
+		CSRString string
+	}
+	err = json.NewDecoder(response.Body).Decode(&csr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode response body: %w", err)
+	}
+	if csr.CSRString == "" {
+		return nil, fmt.Errorf("BMC returned no CSR")
+	}
+
+	return []byte(csr.CSRString), nil
+}
+
+func (b *RedfishBMC) InstallCertificate(ctx context.Context, cert []byte) error {
+	return redfishDo(ctx, b.tags, b.trust, b.host, b.port, b.creds, func(c *gofish.APIClient) error {
+		return b.installCertificate(ctx, c, cert)
+	})
+}
+
+// installCertificate replaces the first HTTPS certificate of the manager. If the manager has no
+// certificate yet, the certificate is added to the collection.
+func (b *RedfishBMC) installCertificate(ctx context.Context, c *gofish.APIClient, cert []byte) error {
+	svc, err := redfishGetCertificateService(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	coll, err := b.httpsCertificates(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var certs common.LinksCollection
+	err = redfishGetRaw(ctx, c, coll, &certs)
+	if err != nil {
+		return err
+	}
+
+	var response *http.Response
+	if len(certs.Members) > 0 && svc.Actions.ReplaceCertificate.Target != "" {
+		log.Debug(ctx, "Replacing certificate", "certificate", certs.Members[0].String())
+		response, err = c.Post(svc.Actions.ReplaceCertificate.Target, map[string]any{
+			"CertificateString": string(cert),
+			"CertificateType":   "PEM",
+			"CertificateUri":    map[string]string{"@odata.id": certs.Members[0].String()},
+		})
+	} else {
+		log.Debug(ctx, "Adding certificate", "collection", coll)
+		response, err = c.Post(coll, map[string]any{
+			"CertificateString": string(cert),
+			"CertificateType":   "PEM",
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("cannot perform POST request: %w", err)
+	}
+	must(ctx, response.Body.Close())
+
+	return nil
+}
+
+// httpsCertificates returns the path of the HTTPS certificate collection of the manager of the
+// selected system.
+func (b *RedfishBMC) httpsCertificates(ctx context.Context, c *gofish.APIClient) (string, error) {
+	sys, _, err := redfishSystem(c, b.system)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the system: %w", err)
+	}
+
+	mgr, err := redfishManager(c, sys)
+	if err != nil {
+		return "", err
+	}
+	if mgr == nil {
+		return "", fmt.Errorf("%w: BMC has no manager", ErrNoCertificateService)
+	}
+
+	var m struct {
+		NetworkProtocol common.Link
+	}
+	err = redfishGetRaw(ctx, c, mgr.ODataID, &m)
+	if err != nil {
+		return "", err
+	}
+	if m.NetworkProtocol == "" {
+		return "", fmt.Errorf("%w: manager %s has no network protocol", ErrNoCertificateService, mgr.ID)
+	}
+
+	var p struct {
+		HTTPS struct {
+			Certificates common.Link
+		}
+	}
+	err = redfishGetRaw(ctx, c, m.NetworkProtocol.String(), &p)
+	if err != nil {
+		return "", err
+	}
+	if p.HTTPS.Certificates == "" {
+		return "", fmt.Errorf("%w: manager %s has no HTTPS certificates", ErrNoCertificateService, mgr.ID)
+	}
+
+	return p.HTTPS.Certificates.String(), nil
+}
+
+func redfishGetCertificateService(ctx context.Context, c *gofish.APIClient) (redfishCertificateService, error) {
+	var svc redfishCertificateService
+	err := redfishGetRaw(ctx, c, redfishCertificateServicePath, &svc)
+	if redfishStatusCode(err) == http.StatusNotFound {
+		return svc, fmt.Errorf("%w: %w", ErrNoCertificateService, err)
+	}
+	return svc, err
+}
+
+func redfishGetRaw(ctx context.Context, c *gofish.APIClient, endpoint string, v any) error {
+	response, err := c.Get(endpoint)
+	if err != nil {
+		return fmt.Errorf("cannot perform GET request: %w", err)
+	}
+	defer func() { must(ctx, response.Body.Close()) }()
+
+	err = json.NewDecoder(response.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("cannot decode response body: %w", err)
+	}
+	return nil
+}
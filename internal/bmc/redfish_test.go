@@ -0,0 +1,666 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Redfish BMC", func() {
+	var r *redfishService
+	var b BMC
+
+	BeforeEach(func() {
+		By("Starting a Redfish service with two systems")
+		r = newRedfishService("admin", "password")
+		r.addManager("BMC-1", "1.10")
+		r.addManager("BMC-2", "2.20")
+		r.addSystem("1", "2A1B3C4D-0000-0000-0000-000000000001", "BMC-1")
+		r.addSystem("2", "2A1B3C4D-0000-0000-0000-000000000002", "BMC-2")
+		DeferCleanup(r.Close)
+		b = redfishBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+	})
+
+	It("should report failed authentication", func(ctx SpecContext) {
+		b = redfishBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "wrong"}, time.Time{})
+		Expect(b.Connect(ctx)).To(MatchError(ErrAuthFailed))
+	})
+
+	It("should read the info of the first system by default", func(ctx SpecContext) {
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.UUID).To(Equal("2a1b3c4d-0000-0000-0000-000000000001"))
+		Expect(info.FWVersion).To(Equal("1.10"))
+		Expect(info.Systems).To(Equal([]SystemInfo{
+			{ID: "1", UUID: "2a1b3c4d-0000-0000-0000-000000000001"},
+			{ID: "2", UUID: "2a1b3c4d-0000-0000-0000-000000000002"},
+		}))
+	})
+
+	It("should order numeric system IDs by their value", func(ctx SpecContext) {
+		r.addSystem("10", "2A1B3C4D-0000-0000-0000-000000000010", "BMC-1")
+
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Systems).To(HaveExactElements(
+			HaveField("ID", "1"),
+			HaveField("ID", "2"),
+			HaveField("ID", "10"),
+		))
+		Expect(redfishCompareIDs("System.Embedded.1", "2")).To(BeNumerically(">", 0))
+		Expect(redfishCompareIDs("Blade10", "Blade2")).To(BeNumerically("<", 0))
+	})
+
+	It("should act on the selected system", func(ctx SpecContext) {
+		ss, ok := b.(SystemSelector)
+		Expect(ok).To(BeTrue())
+		ss.SelectSystem("2")
+
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.UUID).To(Equal("2a1b3c4d-0000-0000-0000-000000000002"))
+		Expect(info.FWVersion).To(Equal("2.20"))
+
+		Expect(b.(PowerControl).PowerOn(ctx)).To(Succeed())
+		Expect(b.(LEDControl).SetLocatorLED(ctx, "On")).To(Equal("On"))
+		Expect(r.Resets()).To(Equal([]string{"Systems/2: On"}))
+		Expect(r.System("1").IndicatorLED).To(Equal("Off"))
+		Expect(r.System("2").IndicatorLED).To(Equal("Lit"))
+
+		Expect(b.(ManagerResetControl).ResetManager(ctx)).To(Succeed())
+		Expect(r.Resets()).To(ContainElement("Managers/BMC-2: GracefulRestart"))
+	})
+
+	It("should reuse sessions", func(ctx SpecContext) {
+		_, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b.(PowerControl).PowerOn(ctx)).To(Succeed())
+
+		other := redfishBMC(nil, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		Expect(other.Connect(ctx)).To(Succeed())
+		Expect(r.Logins()).To(Equal(1))
+		Expect(r.Sessions()).To(Equal(1))
+	})
+
+	It("should log in again if the BMC drops the session", func(ctx SpecContext) {
+		Expect(b.Connect(ctx)).To(Succeed())
+		relogins := testutil.ToFloat64(redfishSessionRelogins)
+
+		r.DropSessions()
+		_, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Logins()).To(Equal(2))
+		Expect(testutil.ToFloat64(redfishSessionRelogins)).To(Equal(relogins + 1))
+	})
+
+	It("should log out of idle sessions", func(ctx SpecContext) {
+		setRedfishSessionCache(newRedfishSessionCache(8, 0))
+
+		Expect(b.Connect(ctx)).To(Succeed())
+		Expect(b.Connect(ctx)).To(Succeed())
+		Expect(r.Logins()).To(Equal(2))
+		Expect(r.Sessions()).To(Equal(1))
+	})
+
+	It("should evict the least recently used session", func(ctx SpecContext) {
+		setRedfishSessionCache(newRedfishSessionCache(1, time.Hour))
+
+		Expect(b.Connect(ctx)).To(Succeed())
+		other := redfishBMC(map[string]string{FlagRedfishTimeout: "30s"}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		Expect(other.Connect(ctx)).To(Succeed())
+		Expect(redfishSessions.count()).To(Equal(1))
+		Eventually(r.Sessions).Should(Equal(1))
+	})
+
+	It("should not keep the credentials in the session cache", func(ctx SpecContext) {
+		setRedfishSessionCache(newRedfishSessionCache(8, time.Hour))
+
+		Expect(b.Connect(ctx)).To(Succeed())
+		Expect(redfishSessions.count()).To(Equal(1))
+		for k := range redfishSessions.sessions {
+			Expect(fmt.Sprintf("%+v", k)).NotTo(ContainSubstring("password"))
+		}
+
+		By("Evicting the session of replaced credentials")
+		redfishSessions.evict(ctx, nil, TLSTrust{}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"})
+		Expect(redfishSessions.count()).To(BeZero())
+		Expect(r.Sessions()).To(BeZero())
+	})
+
+	It("should not cache sessions if the cache is disabled", func(ctx SpecContext) {
+		setRedfishSessionCache(newRedfishSessionCache(0, time.Hour))
+
+		Expect(b.Connect(ctx)).To(Succeed())
+		Expect(b.Connect(ctx)).To(Succeed())
+		Expect(r.Logins()).To(Equal(2))
+		Expect(r.Sessions()).To(BeZero())
+	})
+
+	It("should verify the certificate against the system CAs", func(ctx SpecContext) {
+		b = redfishBMC(map[string]string{FlagRedfishTLSPolicy: TLSPolicySystem}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		Expect(b.Connect(ctx)).To(MatchError(ErrUntrustedCertificate))
+
+		b = redfishBMC(map[string]string{FlagRedfishTLSVerify: "true"}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		Expect(b.Connect(ctx)).To(MatchError(ErrUntrustedCertificate))
+	})
+
+	It("should verify the certificate against a CA", func(ctx SpecContext) {
+		b = redfishBMC(map[string]string{FlagRedfishTLSPolicy: TLSPolicyCA}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		tc, ok := b.(TLSControl)
+		Expect(ok).To(BeTrue())
+
+		Expect(b.Connect(ctx)).To(MatchError(ErrUntrustedCertificate))
+		tc.SetTLSTrust(TLSTrust{CA: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: r.Certificate().Raw})})
+		Expect(b.Connect(ctx)).To(Succeed())
+	})
+
+	It("should only accept the pinned certificate", func(ctx SpecContext) {
+		b = redfishBMC(map[string]string{FlagRedfishTLSPolicy: TLSPolicyPin}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		tc, ok := b.(TLSControl)
+		Expect(ok).To(BeTrue())
+		Expect(b.Connect(ctx)).To(MatchError(ErrUntrustedCertificate))
+
+		fp, err := tc.CertificateFingerprint(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		sum := sha256.Sum256(r.Certificate().Raw)
+		Expect(fp).To(Equal(hex.EncodeToString(sum[:])))
+
+		tc.SetTLSTrust(TLSTrust{Fingerprint: fp})
+		Expect(b.Connect(ctx)).To(Succeed())
+
+		tc.SetTLSTrust(TLSTrust{Fingerprint: strings.Repeat("0", 64)})
+		Expect(b.Connect(ctx)).To(MatchError(ErrCertificateChanged))
+
+		tc.SetTLSTrust(TLSTrust{Fingerprint: strings.Repeat("0", 64), PendingFingerprint: fp})
+		Expect(b.Connect(ctx)).To(Succeed())
+	})
+
+	It("should replace the certificate with one issued for a key of the BMC", func(ctx SpecContext) {
+		r.addCertificateService()
+		cc, ok := b.(CertificateControl)
+		Expect(ok).To(BeTrue())
+
+		By("Generating a CSR on the BMC")
+		csrPEM, err := cc.GenerateCSR(ctx, "127.0.0.1", []string{"127.0.0.1"})
+		Expect(err).NotTo(HaveOccurred())
+		block, _ := pem.Decode(csrPEM)
+		Expect(block).NotTo(BeNil())
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(csr.CheckSignature()).To(Succeed())
+		Expect(csr.Subject.CommonName).To(Equal("127.0.0.1"))
+
+		By("Installing a certificate issued by a CA")
+		caCert, caKey := newTestCA()
+		leaf := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "127.0.0.1"},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, leaf, caCert, csr.PublicKey, caKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cc.InstallCertificate(ctx, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))).To(Succeed())
+
+		cert, err := cc.Certificate(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.Raw).To(Equal(der))
+
+		By("Verifying the new certificate against the CA")
+		b = redfishBMC(map[string]string{FlagRedfishTLSPolicy: TLSPolicyCA}, "127.0.0.1", r.Port(), Credentials{Username: "admin", Password: "password"}, time.Time{})
+		b.(TLSControl).SetTLSTrust(TLSTrust{CA: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})})
+		Expect(b.Connect(ctx)).To(Succeed())
+	})
+
+	It("should report a missing certificate service", func(ctx SpecContext) {
+		_, err := b.(CertificateControl).GenerateCSR(ctx, "127.0.0.1", nil)
+		Expect(err).To(MatchError(ErrNoCertificateService))
+	})
+
+	It("should fail if the selected system does not exist", func(ctx SpecContext) {
+		b.(SystemSelector).SelectSystem("3")
+		_, err := b.ReadInfo(ctx)
+		Expect(err).To(MatchError(ContainSubstring("BMC has no system 3")))
+	})
+})
+
+// setRedfishSessionCache replaces the session cache for the current spec.
+func setRedfishSessionCache(sc *redfishSessionCache) {
+	prev := redfishSessions
+	redfishSessions = sc
+	DeferCleanup(func() {
+		redfishSessions = prev
+	})
+}
+
+// newTestCA returns a self-signed CA certificate and its key.
+func newTestCA() (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, key
+}
+
+// redfishService is a fake Redfish service with session authentication, systems, managers and
+// optionally a certificate service.
+type redfishService struct {
+	srv      *httptest.Server
+	username string
+	password string
+
+	mtx                sync.Mutex
+	sessions           map[string]bool
+	logins             int
+	systems            []*redfishTestSystem
+	managers           []*redfishTestManager
+	resets             []string
+	certificateService bool
+	csrKey             *ecdsa.PrivateKey
+	cert               *tls.Certificate
+}
+
+type redfishTestSystem struct {
+	ID           string
+	UUID         string
+	Manager      string
+	PowerState   string
+	IndicatorLED string
+}
+
+type redfishTestManager struct {
+	ID              string
+	FirmwareVersion string
+}
+
+func newRedfishService(username, password string) *redfishService {
+	r := &redfishService{
+		username: username,
+		password: password,
+		sessions: map[string]bool{},
+	}
+	r.srv = httptest.NewUnstartedServer(http.HandlerFunc(r.serveHTTP))
+	r.srv.TLS = &tls.Config{
+		GetConfigForClient: r.tlsConfig,
+	}
+	r.srv.StartTLS()
+	return r
+}
+
+// tlsConfig presents the installed certificate, if there is one.
+func (r *redfishService) tlsConfig(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.cert == nil {
+		return nil, nil
+	}
+	return &tls.Config{Certificates: []tls.Certificate{*r.cert}}, nil
+}
+
+func (r *redfishService) Close() {
+	r.srv.Close()
+}
+
+func (r *redfishService) Port() int {
+	return serverPort(r.srv)
+}
+
+func (r *redfishService) Certificate() *x509.Certificate {
+	return r.srv.Certificate()
+}
+
+func (r *redfishService) addSystem(id, uuid, manager string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.systems = append(r.systems, &redfishTestSystem{
+		ID:           id,
+		UUID:         uuid,
+		Manager:      manager,
+		PowerState:   "Off",
+		IndicatorLED: "Off",
+	})
+}
+
+func (r *redfishService) addCertificateService() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.certificateService = true
+}
+
+func (r *redfishService) addManager(id, fw string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.managers = append(r.managers, &redfishTestManager{ID: id, FirmwareVersion: fw})
+}
+
+// Sessions returns the number of open sessions.
+func (r *redfishService) Sessions() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.sessions)
+}
+
+// Logins returns the number of sessions that have been created.
+func (r *redfishService) Logins() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.logins
+}
+
+// DropSessions closes all sessions, as a BMC does when it restarts.
+func (r *redfishService) DropSessions() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	clear(r.sessions)
+}
+
+// Resets returns all reset actions in the form "<collection>/<id>: <type>".
+func (r *redfishService) Resets() []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return slices.Clone(r.resets)
+}
+
+func (r *redfishService) System(id string) redfishTestSystem {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, s := range r.systems {
+		if s.ID == id {
+			return *s
+		}
+	}
+	return redfishTestSystem{}
+}
+
+func (r *redfishService) authorized(req *http.Request) bool {
+	if user, pass, ok := req.BasicAuth(); ok {
+		return user == r.username && pass == r.password
+	}
+	return r.sessions[req.Header.Get("X-Auth-Token")]
+}
+
+func (r *redfishService) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch {
+	case path == "/redfish/v1":
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"@odata.id":      "/redfish/v1/",
+			"RedfishVersion": "1.6.0",
+			"Systems":        redfishLink("/redfish/v1/Systems"),
+			"Managers":       redfishLink("/redfish/v1/Managers"),
+			"Links": map[string]any{
+				"Sessions": redfishLink("/redfish/v1/SessionService/Sessions"),
+			},
+		})
+		return
+	case path == "/redfish/v1/SessionService/Sessions" && req.Method == http.MethodPost:
+		var creds struct {
+			UserName string
+			Password string
+		}
+		if json.NewDecoder(req.Body).Decode(&creds) != nil || creds.UserName != r.username || creds.Password != r.password {
+			redfishWriteJSON(w, http.StatusUnauthorized, map[string]any{})
+			return
+		}
+		r.logins++
+		token := fmt.Sprintf("token-%d", r.logins)
+		r.sessions[token] = true
+		w.Header().Set("X-Auth-Token", token)
+		w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/"+token)
+		redfishWriteJSON(w, http.StatusCreated, map[string]any{"Id": token})
+		return
+	}
+
+	if !r.authorized(req) {
+		redfishWriteJSON(w, http.StatusUnauthorized, map[string]any{})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/redfish/v1/SessionService/Sessions/") && req.Method == http.MethodDelete:
+		delete(r.sessions, strings.TrimPrefix(path, "/redfish/v1/SessionService/Sessions/"))
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/redfish/v1/Systems":
+		members := make([]any, 0, len(r.systems))
+		for _, s := range r.systems {
+			members = append(members, redfishLink("/redfish/v1/Systems/"+s.ID))
+		}
+		redfishWriteJSON(w, http.StatusOK, map[string]any{"Members": members})
+	case path == "/redfish/v1/Managers":
+		members := make([]any, 0, len(r.managers))
+		for _, m := range r.managers {
+			members = append(members, redfishLink("/redfish/v1/Managers/"+m.ID))
+		}
+		redfishWriteJSON(w, http.StatusOK, map[string]any{"Members": members})
+	case strings.HasPrefix(path, "/redfish/v1/Systems/"):
+		r.serveSystem(w, req, strings.TrimPrefix(path, "/redfish/v1/Systems/"))
+	case strings.HasPrefix(path, "/redfish/v1/Managers/"):
+		r.serveManager(w, req, strings.TrimPrefix(path, "/redfish/v1/Managers/"))
+	case strings.HasPrefix(path, "/redfish/v1/CertificateService") && r.certificateService:
+		r.serveCertificateService(w, req, strings.TrimPrefix(path, "/redfish/v1/CertificateService"))
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *redfishService) serveSystem(w http.ResponseWriter, req *http.Request, path string) {
+	id, action, _ := strings.Cut(path, "/")
+	var s *redfishTestSystem
+	for _, sys := range r.systems {
+		if sys.ID == id {
+			s = sys
+		}
+	}
+	if s == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	switch {
+	case action == "Actions/ComputerSystem.Reset" && req.Method == http.MethodPost:
+		var reset struct{ ResetType string }
+		_ = json.NewDecoder(req.Body).Decode(&reset)
+		r.resets = append(r.resets, fmt.Sprintf("Systems/%s: %s", id, reset.ResetType))
+		switch reset.ResetType {
+		case "On", "ForceOn":
+			s.PowerState = "On"
+		case "GracefulShutdown", "ForceOff":
+			s.PowerState = "Off"
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "" && req.Method == http.MethodPatch:
+		var patch struct{ IndicatorLED string }
+		_ = json.NewDecoder(req.Body).Decode(&patch)
+		if patch.IndicatorLED != "" {
+			s.IndicatorLED = patch.IndicatorLED
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "" && req.Method == http.MethodGet:
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"@odata.id":    "/redfish/v1/Systems/" + s.ID,
+			"Id":           s.ID,
+			"UUID":         s.UUID,
+			"Manufacturer": "Contoso",
+			"SerialNumber": "SN-" + s.ID,
+			"PowerState":   s.PowerState,
+			"IndicatorLED": s.IndicatorLED,
+			"Links": map[string]any{
+				"ManagedBy": []any{redfishLink("/redfish/v1/Managers/" + s.Manager)},
+			},
+			"Actions": map[string]any{
+				"#ComputerSystem.Reset": map[string]any{
+					"target": "/redfish/v1/Systems/" + s.ID + "/Actions/ComputerSystem.Reset",
+				},
+			},
+		})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *redfishService) serveManager(w http.ResponseWriter, req *http.Request, path string) {
+	id, action, _ := strings.Cut(path, "/")
+	var m *redfishTestManager
+	for _, mgr := range r.managers {
+		if mgr.ID == id {
+			m = mgr
+		}
+	}
+	if m == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	switch {
+	case action == "Actions/Manager.Reset" && req.Method == http.MethodPost:
+		var reset struct{ ResetType string }
+		_ = json.NewDecoder(req.Body).Decode(&reset)
+		r.resets = append(r.resets, fmt.Sprintf("Managers/%s: %s", id, reset.ResetType))
+		w.WriteHeader(http.StatusNoContent)
+	case action == "" && req.Method == http.MethodGet:
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"@odata.id":       "/redfish/v1/Managers/" + m.ID,
+			"Id":              m.ID,
+			"FirmwareVersion": m.FirmwareVersion,
+			"SerialConsole": map[string]any{
+				"ServiceEnabled":        true,
+				"ConnectTypesSupported": []string{"IPMI"},
+			},
+			"NetworkProtocol": redfishLink("/redfish/v1/Managers/" + m.ID + "/NetworkProtocol"),
+			"Actions": map[string]any{
+				"#Manager.Reset": map[string]any{
+					"target":                            "/redfish/v1/Managers/" + m.ID + "/Actions/Manager.Reset",
+					"ResetType@Redfish.AllowableValues": []string{"GracefulRestart", "ForceRestart"},
+				},
+			},
+		})
+	case action == "NetworkProtocol" && req.Method == http.MethodGet:
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"@odata.id": "/redfish/v1/Managers/" + m.ID + "/NetworkProtocol",
+			"HTTPS": map[string]any{
+				"ProtocolEnabled": true,
+				"Port":            443,
+				"Certificates":    redfishLink("/redfish/v1/Managers/" + m.ID + "/NetworkProtocol/HTTPS/Certificates"),
+			},
+		})
+	case action == "NetworkProtocol/HTTPS/Certificates" && req.Method == http.MethodGet:
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"Members": []any{redfishLink("/redfish/v1/Managers/" + m.ID + "/NetworkProtocol/HTTPS/Certificates/1")},
+		})
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// serveCertificateService generates a key for every CSR and presents the certificate for the key
+// once it is installed.
+func (r *redfishService) serveCertificateService(w http.ResponseWriter, req *http.Request, path string) {
+	switch {
+	case path == "" && req.Method == http.MethodGet:
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"@odata.id": "/redfish/v1/CertificateService",
+			"Actions": map[string]any{
+				"#CertificateService.GenerateCSR": map[string]any{
+					"target": "/redfish/v1/CertificateService/Actions/CertificateService.GenerateCSR",
+				},
+				"#CertificateService.ReplaceCertificate": map[string]any{
+					"target": "/redfish/v1/CertificateService/Actions/CertificateService.ReplaceCertificate",
+				},
+			},
+		})
+	case path == "/Actions/CertificateService.GenerateCSR" && req.Method == http.MethodPost:
+		var gen struct {
+			CommonName       string
+			AlternativeNames []string
+		}
+		_ = json.NewDecoder(req.Body).Decode(&gen)
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			redfishWriteJSON(w, http.StatusInternalServerError, map[string]any{})
+			return
+		}
+		tmpl := &x509.CertificateRequest{Subject: pkix.Name{CommonName: gen.CommonName}}
+		for _, n := range gen.AlternativeNames {
+			if ip := net.ParseIP(n); ip != nil {
+				tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+			} else {
+				tmpl.DNSNames = append(tmpl.DNSNames, n)
+			}
+		}
+		der, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+		if err != nil {
+			redfishWriteJSON(w, http.StatusInternalServerError, map[string]any{})
+			return
+		}
+		r.csrKey = key
+		redfishWriteJSON(w, http.StatusOK, map[string]any{
+			"CSRString": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+		})
+	case path == "/Actions/CertificateService.ReplaceCertificate" && req.Method == http.MethodPost:
+		var replace struct {
+			CertificateString string
+			CertificateType   string
+		}
+		_ = json.NewDecoder(req.Body).Decode(&replace)
+		block, _ := pem.Decode([]byte(replace.CertificateString))
+		if block == nil || replace.CertificateType != "PEM" || r.csrKey == nil {
+			redfishWriteJSON(w, http.StatusBadRequest, map[string]any{})
+			return
+		}
+		r.cert = &tls.Certificate{Certificate: [][]byte{block.Bytes}, PrivateKey: r.csrKey}
+		r.csrKey = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func redfishLink(id string) map[string]any {
+	return map[string]any{"@odata.id": id}
+}
+
+func redfishWriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stmcginnis/gofish"
+
+	"github.com/ironcore-dev/metal/internal/log"
+)
+
+const (
+	// DefaultRedfishSessionCacheSize is the default number of Redfish sessions kept open.
+	DefaultRedfishSessionCacheSize = 256
+	// DefaultRedfishSessionIdleTimeout is the default duration an unused Redfish session is kept open.
+	// It is shorter than the session timeout of most BMCs.
+	DefaultRedfishSessionIdleTimeout = 5 * time.Minute
+)
+
+var (
+	redfishSessions = newRedfishSessionCache(DefaultRedfishSessionCacheSize, DefaultRedfishSessionIdleTimeout)
+
+	redfishSessionsOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "metal_bmc_redfish_sessions",
+		Help: "Number of Redfish sessions kept open in the session cache.",
+	})
+	redfishSessionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "metal_bmc_redfish_session_requests_total",
+		Help: "Number of Redfish operations, by whether they reused a cached session (hit), logged in (miss) or bypassed the cache (uncached).",
+	}, []string{"result"})
+	redfishSessionRelogins = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "metal_bmc_redfish_session_relogins_total",
+		Help: "Number of cached Redfish sessions that were rejected by the BMC and replaced.",
+	})
+	redfishSessionEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "metal_bmc_redfish_session_evictions_total",
+		Help: "Number of Redfish sessions removed from the session cache, by reason.",
+	}, []string{"reason"})
+)
+
+// Collectors returns the metrics of the BMC drivers, to be registered by the caller.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		redfishSessionsOpen,
+		redfishSessionRequests,
+		redfishSessionRelogins,
+		redfishSessionEvictions,
+	}
+}
+
+// ConfigureRedfishSessionCache sets the maximum number of cached Redfish sessions and how long an
+// unused session is kept open. A size of 0 disables the cache. It must be called before any BMC is
+// used.
+func ConfigureRedfishSessionCache(size int, idleTimeout time.Duration) {
+	redfishSessions = newRedfishSessionCache(size, idleTimeout)
+}
+
+// redfishSessionCache keeps Redfish sessions open between operations, so that reconciles of the
+// OOB and Machine controllers share them. Many BMCs allow only a few concurrent sessions and are
+// slow to create them. Sessions are keyed by endpoint, flags and a keyed hash of the credentials,
+// so that the cache does not hold passwords beyond the sessions themselves.
+type redfishSessionCache struct {
+	size        int
+	idleTimeout time.Duration
+	hashKey     []byte
+
+	mtx      sync.Mutex
+	sessions map[redfishSessionKey]*redfishSession
+}
+
+type redfishSessionKey struct {
+	endpoint string
+	flags    string
+	trust    string
+	creds    [sha256.Size]byte
+}
+
+type redfishSession struct {
+	key      redfishSessionKey
+	mtx      sync.Mutex
+	c        *gofish.APIClient
+	refs     int
+	lastUsed time.Time
+}
+
+func newRedfishSessionCache(size int, idleTimeout time.Duration) *redfishSessionCache {
+	hashKey := make([]byte, sha256.Size)
+	_, err := rand.Read(hashKey)
+	if err != nil {
+		panic(fmt.Sprintf("cannot generate session cache key: %v", err))
+	}
+
+	return &redfishSessionCache{
+		size:        size,
+		idleTimeout: idleTimeout,
+		hashKey:     hashKey,
+		sessions:    make(map[redfishSessionKey]*redfishSession),
+	}
+}
+
+func (sc *redfishSessionCache) key(tags map[string]string, trust TLSTrust, host string, port int, creds Credentials) redfishSessionKey {
+	h := hmac.New(sha256.New, sc.hashKey)
+	_, _ = h.Write([]byte(creds.Username))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(creds.Password))
+
+	key := redfishSessionKey{
+		endpoint: redfishEndpoint(host, port),
+		flags:    fmt.Sprint(tags),
+		trust:    trust.Fingerprint + trust.PendingFingerprint + string(trust.CA),
+	}
+	h.Sum(key.creds[:0])
+	return key
+}
+
+// redfishDo runs f with a client for the BMC. The client reuses a cached session if there is one.
+// If the BMC rejects a cached session, it is replaced and f runs once more.
+func redfishDo(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, creds Credentials, f func(c *gofish.APIClient) error) error {
+	for {
+		c, s, reused, err := redfishSessions.acquire(ctx, tags, trust, host, port, creds)
+		if err != nil {
+			return err
+		}
+
+		err = f(c)
+		stale := reused && errors.Is(redfishClassifyError(err), ErrAuthFailed)
+		redfishSessions.release(ctx, s, c, stale)
+		if !stale {
+			return err
+		}
+
+		log.Debug(ctx, "Cached session was rejected, logging in again")
+		redfishSessionRelogins.Inc()
+	}
+}
+
+// acquire returns a client with an open session. If the session is cached, it is returned with
+// the cache entry, which must be released. The client has to be logged out by release.
+func (sc *redfishSessionCache) acquire(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, creds Credentials) (*gofish.APIClient, *redfishSession, bool, error) {
+	key := sc.key(tags, trust, host, port, creds)
+
+	sc.mtx.Lock()
+	expired := sc.expireLocked(time.Now())
+	s, ok := sc.sessions[key]
+	if !ok && sc.size > 0 && (len(sc.sessions) < sc.size || sc.evictLocked()) {
+		s = &redfishSession{key: key}
+		sc.sessions[key] = s
+		redfishSessionsOpen.Set(float64(len(sc.sessions)))
+		ok = true
+	}
+	if ok {
+		s.refs++
+	}
+	sc.mtx.Unlock()
+
+	for _, e := range expired {
+		e.c.Logout()
+	}
+
+	if !ok {
+		redfishSessionRequests.WithLabelValues("uncached").Inc()
+		c, err := redfishConnect(ctx, tags, trust, host, port, creds)
+		return c, nil, false, err
+	}
+
+	s.mtx.Lock()
+	if s.c != nil {
+		s.mtx.Unlock()
+		redfishSessionRequests.WithLabelValues("hit").Inc()
+		return s.c, s, true, nil
+	}
+
+	redfishSessionRequests.WithLabelValues("miss").Inc()
+	c, err := redfishConnect(ctx, tags, trust, host, port, creds)
+	s.c = c
+	s.mtx.Unlock()
+	if err != nil {
+		sc.release(ctx, s, nil, true)
+		return nil, nil, false, err
+	}
+	return c, s, false, nil
+}
+
+// release returns a client to the cache. An uncached client is logged out. A stale cache entry is
+// removed, and its session is logged out once no one uses it.
+func (sc *redfishSessionCache) release(ctx context.Context, s *redfishSession, c *gofish.APIClient, stale bool) {
+	if s == nil {
+		c.Logout()
+		return
+	}
+
+	sc.mtx.Lock()
+	s.refs--
+	s.lastUsed = time.Now()
+	if stale && sc.sessions[s.key] == s {
+		delete(sc.sessions, s.key)
+		redfishSessionsOpen.Set(float64(len(sc.sessions)))
+		redfishSessionEvictions.WithLabelValues("rejected").Inc()
+	}
+	logout := sc.sessions[s.key] != s && s.refs == 0
+	sc.mtx.Unlock()
+
+	if logout {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if s.c != nil {
+			log.Debug(ctx, "Logging out of evicted session")
+			s.c.Logout()
+			s.c = nil
+		}
+	}
+}
+
+// evict removes the session of credentials that are replaced, and logs it out once no one uses it.
+func (sc *redfishSessionCache) evict(ctx context.Context, tags map[string]string, trust TLSTrust, host string, port int, creds Credentials) {
+	key := sc.key(tags, trust, host, port, creds)
+
+	sc.mtx.Lock()
+	s, ok := sc.sessions[key]
+	if ok {
+		delete(sc.sessions, key)
+		redfishSessionsOpen.Set(float64(len(sc.sessions)))
+		redfishSessionEvictions.WithLabelValues("replaced").Inc()
+	}
+	logout := ok && s.refs == 0
+	sc.mtx.Unlock()
+
+	if logout {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if s.c != nil {
+			log.Debug(ctx, "Logging out of session with replaced credentials")
+			s.c.Logout()
+			s.c = nil
+		}
+	}
+}
+
+// expireLocked removes all unused sessions that have been idle for longer than the idle timeout
+// and returns them to be logged out.
+func (sc *redfishSessionCache) expireLocked(now time.Time) []*redfishSession {
+	var expired []*redfishSession
+	for k, s := range sc.sessions {
+		if s.refs == 0 && now.Sub(s.lastUsed) > sc.idleTimeout {
+			delete(sc.sessions, k)
+			redfishSessionEvictions.WithLabelValues("idle").Inc()
+			if s.c != nil {
+				expired = append(expired, s)
+			}
+		}
+	}
+	redfishSessionsOpen.Set(float64(len(sc.sessions)))
+	return expired
+}
+
+// evictLocked removes the least recently used session that is not in use. The session is logged
+// out in the background, as the cache is locked. It returns false if all sessions are in use.
+func (sc *redfishSessionCache) evictLocked() bool {
+	var lru *redfishSession
+	for _, s := range sc.sessions {
+		if s.refs == 0 && (lru == nil || s.lastUsed.Before(lru.lastUsed)) {
+			lru = s
+		}
+	}
+	if lru == nil {
+		return false
+	}
+
+	delete(sc.sessions, lru.key)
+	redfishSessionEvictions.WithLabelValues("capacity").Inc()
+	if lru.c != nil {
+		go lru.c.Logout()
+	}
+	return true
+}
+
+// count returns the number of cached sessions.
+func (sc *redfishSessionCache) count() int {
+	sc.mtx.Lock()
+	defer sc.mtx.Unlock()
+	return len(sc.sessions)
+}
@@ -0,0 +1,435 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/ironcore-dev/metal/internal/log"
+)
+
+// The SSH driver manages network devices running a Linux-based network operating system, such as
+// SONiC, through their management port. The login user needs passwordless sudo.
+
+const (
+	sshDefaultPort      = 22
+	sshHandshakeTimeout = 30 * time.Second
+	sshAdminGroup       = "sudo"
+
+	sshInfoCommand  = `for k in sys_vendor product_name product_serial; do printf '%s=' "$k"; sudo -n cat "/sys/class/dmi/id/$k" 2>/dev/null || echo; done; . /etc/os-release && echo "os=$PRETTY_NAME"`
+	sshUsersCommand = "getent passwd"
+	sshFirstUserUID = 1000
+)
+
+var sshUsernameRegex = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+func init() {
+	registerBMC(sshBMC)
+}
+
+func sshBMC(tags map[string]string, host string, port int, creds Credentials, exp time.Time) BMC {
+	return &SSHBMC{
+		tags:  tags,
+		host:  host,
+		port:  port,
+		creds: creds,
+		exp:   exp,
+	}
+}
+
+type SSHBMC struct {
+	tags    map[string]string
+	host    string
+	port    int
+	creds   Credentials
+	exp     time.Time
+	hostKey string
+}
+
+func (b *SSHBMC) Type() string {
+	return "SSH"
+}
+
+func (b *SSHBMC) Tags() map[string]string {
+	return b.tags
+}
+
+func (b *SSHBMC) ResetControl() ResetControl {
+	return b
+}
+
+func (b *SSHBMC) Credentials() (Credentials, time.Time) {
+	return b.creds, b.exp
+}
+
+func (b *SSHBMC) SetHostKey(fingerprint string) {
+	b.hostKey = fingerprint
+}
+
+// errSSHHostKeyRead aborts the handshake once the host key has been read.
+var errSSHHostKeyRead = errors.New("host key read")
+
+func (b *SSHBMC) HostKeyFingerprint(ctx context.Context) (string, error) {
+	log.Debug(ctx, "Reading host key", "host", b.host)
+	ctx, cancel := context.WithTimeout(ctx, sshHandshakeTimeout)
+	defer cancel()
+
+	conn, hostAndPort, err := sshDial(ctx, b.host, b.port)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var fingerprint string
+	_, _, _, err = ssh.NewClientConn(conn, hostAndPort, &ssh.ClientConfig{
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			fingerprint = ssh.FingerprintSHA256(key)
+			return errSSHHostKeyRead
+		},
+	})
+	if fingerprint == "" {
+		return "", fmt.Errorf("cannot read host key: %w", sshClassifyError(err))
+	}
+	return fingerprint, nil
+}
+
+// sshDial opens a TCP connection to the device, which expires with the context.
+func sshDial(ctx context.Context, host string, port int) (net.Conn, string, error) {
+	if port == 0 {
+		port = sshDefaultPort
+	}
+
+	hostAndPort := net.JoinHostPort(host, strconv.Itoa(port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", hostAndPort)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot connect: %w", sshClassifyError(err))
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		_ = conn.Close()
+		return nil, "", fmt.Errorf("cannot set deadline: %w", err)
+	}
+	return conn, hostAndPort, nil
+}
+
+// sshConnect connects only if the host key of the device matches the pinned fingerprint, see
+// HostKeyFingerprint.
+func sshConnect(ctx context.Context, host string, port int, hostKey string, creds Credentials) (*ssh.Client, error) {
+	log.Debug(ctx, "Connecting", "host", host, "user", creds.Username)
+
+	ctx, cancel := context.WithTimeout(ctx, sshHandshakeTimeout)
+	defer cancel()
+
+	conn, hostAndPort, err := sshDial(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ssh.ClientConfig{
+		User: creds.Username,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(creds.Password),
+			// Many network devices only offer keyboard-interactive authentication for passwords.
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range questions {
+					answers[i] = creds.Password
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			if hostKey == "" {
+				return ErrUntrustedHostKey
+			}
+			if ssh.FingerprintSHA256(key) != hostKey {
+				return ErrHostKeyChanged
+			}
+			return nil
+		},
+	}
+	sconn, chans, reqs, err := ssh.NewClientConn(conn, hostAndPort, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("cannot connect: %w", sshClassifyError(err))
+	}
+
+	err = conn.SetDeadline(time.Time{})
+	if err != nil {
+		_ = sconn.Close()
+		return nil, fmt.Errorf("cannot clear deadline: %w", err)
+	}
+
+	return ssh.NewClient(sconn, chans, reqs), nil
+}
+
+func sshClassifyError(err error) error {
+	if errors.Is(err, ErrUntrustedHostKey) || errors.Is(err, ErrHostKeyChanged) {
+		return err
+	}
+	if strings.Contains(err.Error(), "unable to authenticate") {
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) || errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	return err
+}
+
+// sshRun runs a command in a new session. Secrets must be passed through stdin, never as part of the
+// command.
+func sshRun(ctx context.Context, c *ssh.Client, cmd string, stdin string) (string, error) {
+	s, err := c.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("cannot create session: %w", err)
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+
+	var stdout, stderr bytes.Buffer
+	s.Stdin = strings.NewReader(stdin)
+	s.Stdout = &stdout
+	s.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(cmd)
+	}()
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("cannot run command: %w", ctx.Err())
+	case err = <-done:
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot run command, stderr: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return stdout.String(), nil
+}
+
+func sshFindWorkingCredentials(ctx context.Context, host string, port int, hostKey string, defaultCreds []Credentials, tempPassword string) (Credentials, error) {
+	if len(defaultCreds) == 0 {
+		return Credentials{}, fmt.Errorf("no default credentials to try")
+	}
+
+	var merr error
+	for _, creds := range defaultCreds {
+		c, err := sshConnect(ctx, host, port, hostKey, creds)
+		if err == nil {
+			_ = c.Close()
+			return creds, nil
+		}
+		if errors.Is(err, ErrUntrustedHostKey) || errors.Is(err, ErrHostKeyChanged) {
+			return Credentials{}, err
+		}
+		merr = multierror.Append(merr, err)
+	}
+	for _, creds := range defaultCreds {
+		creds.Password = tempPassword
+		c, err := sshConnect(ctx, host, port, hostKey, creds)
+		if err == nil {
+			_ = c.Close()
+			return creds, nil
+		}
+		merr = multierror.Append(merr, err)
+	}
+	return Credentials{}, fmt.Errorf("cannot connect using any predefined credentials: %w", merr)
+}
+
+func (b *SSHBMC) EnsureInitialCredentials(ctx context.Context, defaultCreds []Credentials, tempPassword string) error {
+	creds, err := sshFindWorkingCredentials(ctx, b.host, b.port, b.hostKey, defaultCreds, tempPassword)
+	if err != nil {
+		return fmt.Errorf("cannot obtain initial credentials: %w", redact(err, passwords(defaultCreds, tempPassword)...))
+	}
+
+	b.creds = creds
+	return nil
+}
+
+func (b *SSHBMC) Connect(ctx context.Context) error {
+	c, err := sshConnect(ctx, b.host, b.port, b.hostKey, b.creds)
+	if err != nil {
+		return err
+	}
+	_ = c.Close()
+	return nil
+}
+
+// sshGetUsers returns the names of all regular users, skipping system accounts.
+func sshGetUsers(ctx context.Context, c *ssh.Client) ([]string, error) {
+	out, err := sshRun(ctx, c, sshUsersCommand, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get users: %w", err)
+	}
+
+	var users []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil || uid < sshFirstUserUID || uid == 65534 {
+			continue
+		}
+		users = append(users, fields[0])
+	}
+	return users, nil
+}
+
+func (b *SSHBMC) CreateUser(ctx context.Context, creds Credentials, _ string) error {
+	pw := b.creds.Password
+	return redact(b.createUser(ctx, creds), pw, creds.Password)
+}
+
+func (b *SSHBMC) createUser(ctx context.Context, creds Credentials) error {
+	log.Debug(ctx, "Creating user", "host", b.host, "user", creds.Username)
+	if !sshUsernameRegex.MatchString(creds.Username) {
+		return fmt.Errorf("cannot create user with invalid name: %s", creds.Username)
+	}
+
+	c, err := sshConnect(ctx, b.host, b.port, b.hostKey, b.creds)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	users, err := sshGetUsers(ctx, c)
+	if err != nil {
+		return fmt.Errorf("cannot generate the list of users: %w", err)
+	}
+	for _, u := range users {
+		if u == creds.Username {
+			return fmt.Errorf("user %s already exists", creds.Username)
+		}
+	}
+
+	_, err = sshRun(ctx, c, fmt.Sprintf("sudo -n useradd -m -s /bin/bash -G %s %s", sshAdminGroup, creds.Username), "")
+	if err != nil {
+		return fmt.Errorf("cannot create user: %w", err)
+	}
+	_, err = sshRun(ctx, c, "sudo -n chpasswd", fmt.Sprintf("%s:%s\n", creds.Username, creds.Password))
+	if err != nil {
+		return fmt.Errorf("cannot set user password: %w", err)
+	}
+
+	var nc *ssh.Client
+	nc, err = sshConnect(ctx, b.host, b.port, b.hostKey, creds)
+	if err != nil {
+		return fmt.Errorf("created user is not available: %w", err)
+	}
+	_ = nc.Close()
+
+	b.creds = creds
+	b.exp = time.Time{}
+	return nil
+}
+
+func (b *SSHBMC) DeleteUsers(ctx context.Context, regex *regexp.Regexp) error {
+	c, err := sshConnect(ctx, b.host, b.port, b.hostKey, b.creds)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	users, err := sshGetUsers(ctx, c)
+	if err != nil {
+		return fmt.Errorf("cannot delete users: %w", err)
+	}
+
+	for _, u := range users {
+		if u != b.creds.Username && regex.MatchString(u) && sshUsernameRegex.MatchString(u) {
+			log.Debug(ctx, "Deleting user", "user", u)
+			_, err = sshRun(ctx, c, fmt.Sprintf("sudo -n userdel -r %s", u), "")
+			if err != nil {
+				return fmt.Errorf("cannot delete user %s: %w", u, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *SSHBMC) ReadInfo(ctx context.Context) (Info, error) {
+	log.Debug(ctx, "Reading BMC info", "host", b.host)
+	c, err := sshConnect(ctx, b.host, b.port, b.hostKey, b.creds)
+	if err != nil {
+		return Info{}, err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	out, err := sshRun(ctx, c, sshInfoCommand, "")
+	if err != nil {
+		return Info{}, fmt.Errorf("cannot get device info: %w", err)
+	}
+	info := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		info[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+	}
+
+	return Info{
+		Type:         flagValue(b.tags, FlagSSHType),
+		Capabilities: []string{"credentials", "reset"},
+		SerialNumber: info["product_serial"],
+		SKU:          info["product_name"],
+		Manufacturer: info["sys_vendor"],
+		// The management port only answers while the device is running.
+		Power:     "On",
+		Console:   "ssh",
+		FWVersion: info["os"],
+	}, nil
+}
+
+// Reset reloads the device. The device cannot be powered on again over SSH, so power off is not
+// supported.
+func (b *SSHBMC) Reset(ctx context.Context, immediate bool) error {
+	log.Debug(ctx, "Reloading device", "host", b.host, "immediate", immediate)
+	c, err := sshConnect(ctx, b.host, b.port, b.hostKey, b.creds)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	cmd := "sudo -n reboot"
+	if immediate {
+		cmd = "sudo -n reboot -f"
+	}
+	// The connection is usually dropped before the command returns.
+	_, err = sshRun(ctx, c, cmd, "")
+	var eerr *ssh.ExitMissingError
+	if err != nil && !errors.As(err, &eerr) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot reload device: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SSH BMC", func() {
+	var dev *SSHDevice
+	var b BMC
+
+	newSSHBMC := func(tags map[string]string, creds Credentials) BMC {
+		b := sshBMC(tags, "127.0.0.1", dev.Port(), creds, time.Time{})
+		b.(HostKeyControl).SetHostKey(dev.HostKey())
+		return b
+	}
+
+	BeforeEach(func() {
+		By("Starting a network device")
+		var err error
+		dev, err = NewSSHDevice(map[string]string{"admin": "YourPaSsWoRd"})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(dev.Close)
+		b = newSSHBMC(nil, Credentials{})
+	})
+
+	It("should read the host key", func(ctx SpecContext) {
+		b = sshBMC(nil, "127.0.0.1", dev.Port(), Credentials{}, time.Time{})
+		hostKey, err := b.(HostKeyControl).HostKeyFingerprint(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hostKey).To(Equal(dev.HostKey()))
+	})
+
+	It("should not connect without a pinned host key", func(ctx SpecContext) {
+		b = sshBMC(nil, "127.0.0.1", dev.Port(), Credentials{}, time.Time{})
+		Expect(b.EnsureInitialCredentials(ctx, []Credentials{
+			{Username: "admin", Password: "YourPaSsWoRd"},
+		}, "temporary")).To(MatchError(ErrUntrustedHostKey))
+	})
+
+	It("should not connect if the host key changed", func(ctx SpecContext) {
+		b = newSSHBMC(nil, Credentials{Username: "admin", Password: "YourPaSsWoRd"})
+		b.(HostKeyControl).SetHostKey("SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU")
+		Expect(b.Connect(ctx)).To(MatchError(ErrHostKeyChanged))
+	})
+
+	It("should find the default credentials", func(ctx SpecContext) {
+		Expect(b.EnsureInitialCredentials(ctx, []Credentials{
+			{Username: "root", Password: "root"},
+			{Username: "admin", Password: "YourPaSsWoRd"},
+		}, "temporary")).To(Succeed())
+		creds, _ := b.Credentials()
+		Expect(creds.Username).To(Equal("admin"))
+		Expect(b.Connect(ctx)).To(Succeed())
+	})
+
+	It("should report failed authentication", func(ctx SpecContext) {
+		b = newSSHBMC(nil, Credentials{Username: "admin", Password: "wrong"})
+		Expect(b.Connect(ctx)).To(MatchError(ErrAuthFailed))
+	})
+
+	It("should create and delete users", func(ctx SpecContext) {
+		Expect(b.EnsureInitialCredentials(ctx, []Credentials{{Username: "admin", Password: "YourPaSsWoRd"}}, "temporary")).To(Succeed())
+
+		By("Creating a user")
+		Expect(b.CreateUser(ctx, Credentials{Username: "metal-abc", Password: "secret"}, "")).To(Succeed())
+		creds, _ := b.Credentials()
+		Expect(creds).To(Equal(Credentials{Username: "metal-abc", Password: "secret"}))
+		Expect(dev.UserNames()).To(ConsistOf("admin", "metal-abc"))
+		Expect(dev.Commands()).NotTo(ContainElement(ContainSubstring("secret")))
+
+		By("Creating another user")
+		Expect(b.CreateUser(ctx, Credentials{Username: "metal-def", Password: "secret2"}, "")).To(Succeed())
+
+		By("Deleting all users but the current one")
+		Expect(b.DeleteUsers(ctx, regexp.MustCompile(`^metal-`))).To(Succeed())
+		Expect(dev.UserNames()).To(ConsistOf("admin", "metal-def"))
+	})
+
+	It("should not leak a rejected password", func(ctx SpecContext) {
+		b = newSSHBMC(nil, Credentials{Username: "admin", Password: "YourPaSsWoRd"})
+		err := b.CreateUser(ctx, Credentials{Username: "metal-abc", Password: "s3cr"}, "")
+		Expect(err).To(MatchError(ContainSubstring("BAD PASSWORD: <redacted> is shorter")))
+		Expect(err.Error()).NotTo(ContainSubstring("s3cr"))
+	})
+
+	It("should read the device info", func(ctx SpecContext) {
+		b = newSSHBMC(map[string]string{FlagSSHType: "Router"}, Credentials{Username: "admin", Password: "YourPaSsWoRd"})
+		info, err := b.ReadInfo(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Type).To(Equal("Router"))
+		Expect(info.Manufacturer).To(Equal("Edgecore"))
+		Expect(info.SKU).To(Equal("AS7726-32X"))
+		Expect(info.SerialNumber).To(Equal("SN12345"))
+		Expect(info.FWVersion).To(Equal("SONiC OS 202311"))
+		Expect(info.Power).To(Equal("On"))
+	})
+
+	It("should reload the device", func(ctx SpecContext) {
+		b = newSSHBMC(nil, Credentials{Username: "admin", Password: "YourPaSsWoRd"})
+		r, ok := b.(interface{ ResetControl() ResetControl })
+		Expect(ok).To(BeTrue())
+		Expect(r.ResetControl().Reset(ctx, true)).To(Succeed())
+		Expect(dev.Commands()).To(ContainElement("sudo -n reboot -f"))
+	})
+})
@@ -0,0 +1,225 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package bmc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHDevice is a minimal in-process network device that answers the commands the SSH driver runs on
+// a Linux-based network operating system. It is meant for tests.
+type SSHDevice struct {
+	l       net.Listener
+	port    int
+	config  *ssh.ServerConfig
+	hostKey string
+
+	mtx      sync.Mutex
+	users    map[string]string
+	blocked  map[string]bool
+	blockNew bool
+	cmds     []string
+}
+
+// NewSSHDevice starts a device with a random host key on a random local TCP port. The users map user
+// names to passwords.
+func NewSSHDevice(users map[string]string) (*SSHDevice, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	var signer ssh.Signer
+	signer, err = ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &SSHDevice{
+		hostKey: ssh.FingerprintSHA256(signer.PublicKey()),
+		users:   make(map[string]string, len(users)),
+		blocked: make(map[string]bool),
+	}
+	for u, p := range users {
+		d.users[u] = p
+	}
+	d.config = &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			d.mtx.Lock()
+			defer d.mtx.Unlock()
+			p, ok := d.users[meta.User()]
+			if !ok || p != string(password) || d.blocked[meta.User()] {
+				return nil, fmt.Errorf("password rejected for %s", meta.User())
+			}
+			return nil, nil
+		},
+	}
+	d.config.AddHostKey(signer)
+
+	err = d.listen("127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	_, port, _ := net.SplitHostPort(d.l.Addr().String())
+	d.port, _ = strconv.Atoi(port)
+	return d, nil
+}
+
+func (d *SSHDevice) listen(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	d.l = l
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return nil
+}
+
+// Port returns the port the device listens on.
+func (d *SSHDevice) Port() int {
+	return d.port
+}
+
+// HostKey returns the fingerprint of the host key of the device, see HostKeyFingerprint.
+func (d *SSHDevice) HostKey() string {
+	return d.hostKey
+}
+
+// Close stops listening, so that the device is unreachable until it is started again.
+func (d *SSHDevice) Close() error {
+	return d.l.Close()
+}
+
+// Start listens on the port of a closed device again, like a device that comes back after an outage.
+func (d *SSHDevice) Start() error {
+	return d.listen(net.JoinHostPort("127.0.0.1", strconv.Itoa(d.port)))
+}
+
+// BlockNewUsers makes the device reject the logins of the users that are created while it is set,
+// like a device that fails after a user was created.
+func (d *SSHDevice) BlockNewUsers(block bool) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.blockNew = block
+}
+
+// UserNames returns the sorted names of all users of the device.
+func (d *SSHDevice) UserNames() []string {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	names := make([]string, 0, len(d.users))
+	for u := range d.users {
+		names = append(names, u)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Commands returns all commands the device ran.
+func (d *SSHDevice) Commands() []string {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return append([]string(nil), d.cmds...)
+}
+
+func (d *SSHDevice) serve(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, d.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		ch, creqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() {
+				_ = ch.Close()
+			}()
+			for req := range creqs {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				_ = req.Reply(true, nil)
+				cmd := string(req.Payload[4:])
+				out, status := d.exec(cmd, ch)
+				_, _ = io.WriteString(ch, out)
+				_, _ = ch.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, status))
+				return
+			}
+		}()
+	}
+}
+
+func (d *SSHDevice) exec(cmd string, ch ssh.Channel) (string, uint32) {
+	d.mtx.Lock()
+	d.cmds = append(d.cmds, cmd)
+	d.mtx.Unlock()
+
+	fields := strings.Fields(cmd)
+	switch {
+	case cmd == sshUsersCommand:
+		var b strings.Builder
+		b.WriteString("root:x:0:0:root:/root:/bin/bash\n")
+		b.WriteString("nobody:x:65534:65534:nobody:/nonexistent:/usr/sbin/nologin\n")
+		for i, u := range d.UserNames() {
+			_, _ = fmt.Fprintf(&b, "%s:x:%d:%d::/home/%s:/bin/bash\n", u, 1000+i, 1000+i, u)
+		}
+		return b.String(), 0
+	case cmd == sshInfoCommand:
+		return "sys_vendor=Edgecore\nproduct_name=AS7726-32X\nproduct_serial=SN12345\nos=SONiC OS 202311\n", 0
+	case len(fields) > 2 && fields[2] == "useradd":
+		d.mtx.Lock()
+		defer d.mtx.Unlock()
+		d.users[fields[len(fields)-1]] = ""
+		d.blocked[fields[len(fields)-1]] = d.blockNew
+		return "", 0
+	case cmd == "sudo -n chpasswd":
+		in, _ := io.ReadAll(io.LimitReader(ch, 1024))
+		u, p, _ := strings.Cut(strings.TrimSpace(string(in)), ":")
+		if len(p) < 6 {
+			_, _ = fmt.Fprintf(ch.Stderr(), "BAD PASSWORD: %s is shorter than 6 characters\n", p)
+			return "", 1
+		}
+		d.mtx.Lock()
+		defer d.mtx.Unlock()
+		d.users[u] = p
+		return "", 0
+	case len(fields) == 5 && fields[2] == "userdel":
+		d.mtx.Lock()
+		defer d.mtx.Unlock()
+		delete(d.users, fields[4])
+		delete(d.blocked, fields[4])
+		return "", 0
+	case strings.HasPrefix(cmd, "sudo -n reboot"):
+		return "", 0
+	default:
+		return "", 127
+	}
+}
@@ -27,6 +27,28 @@ func CreateIndexes(ctx context.Context, mgr manager.Manager) error {
 		return fmt.Errorf("cannot index field %s: %w", MachineClaimSpecMachineRef, err)
 	}
 
+	err = indexer.IndexField(ctx, &metalv1alpha1.Machine{}, MachineSpecUUID, func(obj client.Object) []string {
+		machine := obj.(*metalv1alpha1.Machine)
+		if machine.Spec.UUID == "" {
+			return nil
+		}
+		return []string{machine.Spec.UUID}
+	})
+	if err != nil {
+		return fmt.Errorf("cannot index field %s: %w", MachineSpecUUID, err)
+	}
+
+	err = indexer.IndexField(ctx, &metalv1alpha1.Machine{}, MachineSpecOOBRef, func(obj client.Object) []string {
+		machine := obj.(*metalv1alpha1.Machine)
+		if machine.Spec.OOBRef.Name == "" {
+			return nil
+		}
+		return []string{machine.Spec.OOBRef.Name}
+	})
+	if err != nil {
+		return fmt.Errorf("cannot index field %s: %w", MachineSpecOOBRef, err)
+	}
+
 	err = indexer.IndexField(ctx, &metalv1alpha1.OOB{}, OOBSpecMACAddress, func(obj client.Object) []string {
 		oob := obj.(*metalv1alpha1.OOB)
 		if oob.Spec.MACAddress == "" {
@@ -5,40 +5,774 @@ package controller
 
 import (
 	"context"
+	goerrors "errors"
+	"fmt"
+	"maps"
+	"time"
 
+	ipamv1alpha1 "github.com/ironcore-dev/ipam/api/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/bmc"
+	"github.com/ironcore-dev/metal/internal/credstore"
+	"github.com/ironcore-dev/metal/internal/log"
+	"github.com/ironcore-dev/metal/internal/ssa"
+	"github.com/ironcore-dev/metal/internal/util"
 )
 
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=machines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=machines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=machines/finalizers,verbs=update
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobsecrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.metal.ironcore.dev,resources=ips,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-func NewMachineReconciler() (*MachineReconciler, error) {
-	return &MachineReconciler{}, nil
+const (
+	MachineFieldManager = "metal.ironcore.dev/machine"
+	MachineSpecUUID     = ".spec.uuid"
+	MachineSpecOOBRef   = ".spec.oobRef.Name"
+	// MachineEventSource is the component name used when recording Machine events.
+	MachineEventSource = "machine-controller"
+	// MachinePowerTimeout is the time to wait for a power state change before retrying.
+	MachinePowerTimeout = 2 * time.Minute
+	// MachinePowerCheckInterval is the interval to check the power state while a change is in progress.
+	MachinePowerCheckInterval = 10 * time.Second
+)
+
+func NewMachineReconciler(systemNamespace string, shutdownTimeout time.Duration, store credstore.Store) (*MachineReconciler, error) {
+	if systemNamespace == "" {
+		return nil, fmt.Errorf("system namespace cannot be empty")
+	}
+	if shutdownTimeout <= 0 {
+		return nil, fmt.Errorf("shutdown timeout must be positive")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("credential store cannot be nil")
+	}
+
+	return &MachineReconciler{
+		systemNamespace: systemNamespace,
+		shutdownTimeout: shutdownTimeout,
+		store:           store,
+	}, nil
 }
 
 // MachineReconciler reconciles a Machine object
 type MachineReconciler struct {
 	client.Client
+	recorder        record.EventRecorder
+	systemNamespace string
+	shutdownTimeout time.Duration
+	store           credstore.Store
+	credentials     credentialProvider
 }
 
+type ctxkOperation struct{}
+
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *MachineReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var machine metalv1alpha1.Machine
+	err := r.Get(ctx, req.NamespacedName, &machine)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("cannot get Machine: %w", err))
+	}
+
+	if !machine.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return r.reconcile(ctx, &machine)
+}
+
+func (r *MachineReconciler) reconcile(ctx context.Context, machine *metalv1alpha1.Machine) (ctrl.Result, error) {
+	log.Debug(ctx, "Reconciling")
+
+	var ok bool
+	var err error
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "InitialState"), machine, r.processInitialState)
+	if !ok {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "BMC"), machine, r.processBMC)
+	_, found := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	if !ok || !found {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Operation"), machine, r.processOperation)
+	_, found = ctx.Value(ctxkOperation{}).(string)
+	if !ok || found {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Power"), machine, r.processPower)
+	if !ok {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
 
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "LocatorLED"), machine, r.processLocatorLED)
+	if !ok {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx = log.WithValues(ctx, "phase", "all")
+	log.Debug(ctx, "Reconciled successfully")
+	if machine.Spec.Power != "" && machine.Status.Power != machine.Spec.Power {
+		return ctrl.Result{RequeueAfter: MachinePowerCheckInterval}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+type machineProcessFunc func(context.Context, *metalv1alpha1.Machine) (context.Context, *metalv1alpha1apply.MachineApplyConfiguration, *metalv1alpha1apply.MachineStatusApplyConfiguration, error)
+
+func (r *MachineReconciler) applyOrContinue(ctx context.Context, machine *metalv1alpha1.Machine, pfunc machineProcessFunc) (context.Context, bool, error) {
+	var apply *metalv1alpha1apply.MachineApplyConfiguration
+	var status *metalv1alpha1apply.MachineStatusApplyConfiguration
+	var err error
+
+	ctx, apply, status, err = pfunc(ctx, machine)
+	if err != nil {
+		return ctx, false, err
+	}
+
+	if apply != nil {
+		log.Debug(ctx, "Applying")
+		err = r.Patch(ctx, machine, ssa.Apply(apply), client.FieldOwner(MachineFieldManager), client.ForceOwnership)
+		if err != nil {
+			return ctx, false, fmt.Errorf("cannot apply Machine: %w", err)
+		}
+	}
+
+	if status != nil {
+		apply = metalv1alpha1apply.Machine(machine.Name, machine.Namespace).WithStatus(status)
+
+		log.Debug(ctx, "Applying status")
+		err = r.Status().Patch(ctx, machine, ssa.Apply(apply), client.FieldOwner(MachineFieldManager), client.ForceOwnership)
+		if err != nil {
+			return ctx, false, fmt.Errorf("cannot apply Machine status: %w", err)
+		}
+
+		for _, cond := range status.Conditions {
+			if cond.Status == metav1.ConditionFalse && cond.Reason == metalv1alpha1.MachineConditionReasonError {
+				err = goerrors.New(cond.Message)
+				break
+			}
+		}
+	}
+
+	return ctx, apply == nil, err
+}
+
+func (r *MachineReconciler) processInitialState(ctx context.Context, machine *metalv1alpha1.Machine) (context.Context, *metalv1alpha1apply.MachineApplyConfiguration, *metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.MachineStatusApplyConfiguration
+	var err error
+
+	ctx = log.WithValues(ctx, "uuid", machine.Spec.UUID)
+
+	_, ok := ssa.GetCondition(machine.Status.Conditions, metalv1alpha1.MachineConditionTypeReady)
+	if machine.Status.State == "" || !ok {
+		var applyst *metalv1alpha1apply.MachineApplyConfiguration
+		applyst, err = metalv1alpha1apply.ExtractMachineStatus(machine, MachineFieldManager)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		status = util.Ensure(applyst.Status).
+			WithState(metalv1alpha1.MachineStateUneady)
+		status.Conditions, _ = ssa.SetCondition(machine.Status.Conditions, metav1.Condition{
+			Type:   metalv1alpha1.MachineConditionTypeReady,
+			Status: metav1.ConditionFalse,
+			Reason: metalv1alpha1.MachineConditionReasonInProgress,
+		})
+	}
+
+	return ctx, nil, status, nil
+}
+
+func (r *MachineReconciler) processBMC(ctx context.Context, machine *metalv1alpha1.Machine) (context.Context, *metalv1alpha1apply.MachineApplyConfiguration, *metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.MachineStatusApplyConfiguration
+	var err error
+
+	var oob metalv1alpha1.OOB
+	err = r.Get(ctx, client.ObjectKey{
+		Name: machine.Spec.OOBRef.Name,
+	}, &oob)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctx, nil, nil, fmt.Errorf("cannot get OOB: %w", err)
+	}
+	if errors.IsNotFound(err) {
+		status, err = machineStatus(machine, metalv1alpha1.MachineStateUneady, metav1.Condition{
+			Type:   metalv1alpha1.MachineConditionTypeReady,
+			Status: metav1.ConditionFalse,
+			Reason: metalv1alpha1.MachineConditionReasonNoOOB,
+		})
+		return ctx, nil, status, err
+	}
+	ctx = log.WithValues(ctx, "oob", oob.Name)
+
+	if oob.Status.State != metalv1alpha1.OOBStateReady {
+		status, err = machineStatus(machine, metalv1alpha1.MachineStateUneady, metav1.Condition{
+			Type:   metalv1alpha1.MachineConditionTypeReady,
+			Status: metav1.ConditionFalse,
+			Reason: metalv1alpha1.MachineConditionReasonOOBNotReady,
+		})
+		return ctx, nil, status, err
+	}
+
+	var b bmc.BMC
+	b, err = newBMCForOOB(ctx, r, r.credentials, r.systemNamespace, &oob)
+	if err != nil {
+		status, err = machineStatus(machine, metalv1alpha1.MachineStateError, metav1.Condition{
+			Type:    metalv1alpha1.MachineConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.MachineConditionReasonError,
+			Message: err.Error(),
+		})
+		return ctx, nil, status, err
+	}
+	if machine.Spec.SystemID != "" {
+		ss, ok := b.(bmc.SystemSelector)
+		if !ok {
+			status, err = machineStatus(machine, metalv1alpha1.MachineStateError, metav1.Condition{
+				Type:    metalv1alpha1.MachineConditionTypeReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  metalv1alpha1.MachineConditionReasonError,
+				Message: fmt.Sprintf("BMC of type %s cannot select system %s", b.Type(), machine.Spec.SystemID),
+			})
+			return ctx, nil, status, err
+		}
+		ss.SelectSystem(machine.Spec.SystemID)
+		ctx = log.WithValues(ctx, "system", machine.Spec.SystemID)
+	}
+
+	var info bmc.Info
+	info, err = b.ReadInfo(ctx)
+	if err != nil {
+		status, err = machineStatus(machine, metalv1alpha1.MachineStateError, metav1.Condition{
+			Type:    metalv1alpha1.MachineConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.MachineConditionReasonError,
+			Message: fmt.Sprintf("cannot read BMC info: %s", err),
+		})
+		return ctx, nil, status, err
+	}
+	ctx = context.WithValue(ctx, ctxkBMC{}, b)
+	ctx = context.WithValue(ctx, ctxkInfo{}, info)
+
+	state := metalv1alpha1.MachineStateReady
+	power := machinePower(info.Power)
+	conds, mod := ssa.SetCondition(machine.Status.Conditions, metav1.Condition{
+		Type:   metalv1alpha1.MachineConditionTypeReady,
+		Status: metav1.ConditionTrue,
+		Reason: metalv1alpha1.MachineConditionReasonReady,
+	})
+	if machine.Status.Manufacturer != info.Manufacturer ||
+		machine.Status.SKU != info.SKU ||
+		machine.Status.SerialNumber != info.SerialNumber ||
+		machine.Status.Power != power ||
+		machine.Status.State != state || mod {
+		var applyst *metalv1alpha1apply.MachineApplyConfiguration
+		applyst, err = metalv1alpha1apply.ExtractMachineStatus(machine, MachineFieldManager)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		status = util.Ensure(applyst.Status).
+			WithManufacturer(info.Manufacturer).
+			WithSKU(info.SKU).
+			WithSerialNumber(info.SerialNumber).
+			WithPower(power).
+			WithState(state)
+		status.Conditions = conds
+	}
+
+	return ctx, nil, status, nil
+}
+
+func (r *MachineReconciler) processOperation(ctx context.Context, machine *metalv1alpha1.Machine) (context.Context, *metalv1alpha1apply.MachineApplyConfiguration, *metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.MachineStatusApplyConfiguration
+	var err error
+
+	op, ok := machine.Annotations[metalv1alpha1.MachineOperationKeyName]
+	if !ok {
+		return ctx, nil, nil, nil
+	}
+	ctx = log.WithValues(ctx, "operation", op)
+
+	// The annotation is removed before running the operation, so that it is executed at most once.
+	base := machine.DeepCopy()
+	delete(machine.Annotations, metalv1alpha1.MachineOperationKeyName)
+	err = r.Patch(ctx, machine, client.MergeFrom(base))
+	if err != nil {
+		return ctx, nil, nil, fmt.Errorf("cannot remove operation annotation: %w", err)
+	}
+	ctx = context.WithValue(ctx, ctxkOperation{}, op)
+
+	b, _ := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	supported := false
+	rejected := false
+	msg := fmt.Sprintf("Operation %s is not supported by BMC of type %s", op, b.Type())
+	switch op {
+	case metalv1alpha1.MachineOperationRestart, metalv1alpha1.MachineOperationForceRestart:
+		var rc bmc.ResetControl
+		rc, supported = b.(bmc.ResetControl)
+		if supported {
+			log.Info(ctx, "Restarting")
+			err = rc.Reset(ctx, op == metalv1alpha1.MachineOperationForceRestart)
+		}
+	case metalv1alpha1.MachineOperationForceOff:
+		// Powering off a Machine that is meant to be on would be reverted by the power phase right away.
+		if machine.Spec.Power == metalv1alpha1.PowerOn {
+			rejected = true
+			msg = fmt.Sprintf("Operation %s conflicts with power %s, set the power to %s instead", op, machine.Spec.Power, metalv1alpha1.PowerOff)
+			break
+		}
+		var pc bmc.PowerControl
+		pc, supported = b.(bmc.PowerControl)
+		if supported {
+			log.Info(ctx, "Powering off immediately")
+			err = pc.PowerOff(ctx, true)
+		}
+	default:
+		msg = fmt.Sprintf("Operation %s is unknown", op)
+	}
+
+	now := metav1.Now()
+	cond := metav1.Condition{
+		Type:               metalv1alpha1.MachineConditionTypeOperation,
+		LastTransitionTime: now,
+	}
+	switch {
+	case rejected:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.MachineConditionReasonConflict
+		cond.Message = msg
+		r.recorder.Event(machine, corev1.EventTypeWarning, "OperationRejected", cond.Message)
+	case !supported:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.MachineConditionReasonUnsupported
+		cond.Message = msg
+		r.recorder.Event(machine, corev1.EventTypeWarning, "OperationUnsupported", cond.Message)
+	case err != nil:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.MachineConditionReasonError
+		cond.Message = fmt.Sprintf("Operation %s failed at %s: %s", op, now.UTC().Format(time.RFC3339), err)
+		r.recorder.Event(machine, corev1.EventTypeWarning, "OperationFailed", cond.Message)
+	default:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = metalv1alpha1.MachineConditionReasonCompleted
+		cond.Message = fmt.Sprintf("Operation %s completed at %s", op, now.UTC().Format(time.RFC3339))
+		r.recorder.Event(machine, corev1.EventTypeNormal, "OperationCompleted", cond.Message)
+	}
+
+	status, err = machineCondition(machine, cond)
+	return ctx, nil, status, err
+}
+
+func (r *MachineReconciler) processPower(ctx context.Context, machine *metalv1alpha1.Machine) (context.Context, *metalv1alpha1apply.MachineApplyConfiguration, *metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.MachineStatusApplyConfiguration
+	var err error
+
+	if machine.Spec.Power == "" {
+		return ctx, nil, nil, nil
+	}
+	ctx = log.WithValues(ctx, "power", machine.Spec.Power)
+
+	if machine.Status.Power == machine.Spec.Power {
+		status, err = machinePowerCondition(machine, nil, metav1.Condition{
+			Type:   metalv1alpha1.MachineConditionTypePower,
+			Status: metav1.ConditionTrue,
+			Reason: metalv1alpha1.MachineConditionReasonReconciled,
+		})
+		return ctx, nil, status, err
+	}
+
+	b, _ := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	pc, ok := b.(bmc.PowerControl)
+	if !ok {
+		status, err = machineCondition(machine, metav1.Condition{
+			Type:    metalv1alpha1.MachineConditionTypePower,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.MachineConditionReasonUnsupported,
+			Message: fmt.Sprintf("BMC of type %s does not support power control", b.Type()),
+		})
+		return ctx, nil, status, err
+	}
+
+	var deadline *metav1.Time
+	if machine.Spec.Power == metalv1alpha1.PowerOff {
+		deadline = machine.Status.ShutdownDeadline
+		if deadline == nil {
+			timeout := r.shutdownTimeout
+			if machine.Spec.ShutdownTimeout != nil {
+				timeout = machine.Spec.ShutdownTimeout.Duration
+			}
+
+			log.Info(ctx, "Shutting down", "timeout", timeout)
+			err = pc.PowerOff(ctx, false)
+			if err != nil {
+				status, err = machineCondition(machine, metav1.Condition{
+					Type:    metalv1alpha1.MachineConditionTypePower,
+					Status:  metav1.ConditionFalse,
+					Reason:  metalv1alpha1.MachineConditionReasonError,
+					Message: err.Error(),
+				})
+				return ctx, nil, status, err
+			}
+
+			deadline = &metav1.Time{Time: time.Now().Add(timeout)}
+			status, err = machinePowerCondition(machine, deadline, metav1.Condition{
+				Type:    metalv1alpha1.MachineConditionTypePower,
+				Status:  metav1.ConditionFalse,
+				Reason:  metalv1alpha1.MachineConditionReasonInProgress,
+				Message: "Shutting down gracefully",
+			})
+			return ctx, nil, status, err
+		}
+		if time.Now().Before(deadline.Time) {
+			return ctx, nil, nil, nil
+		}
+	}
+
+	msg := fmt.Sprintf("Powering %s", machine.Spec.Power)
+	if deadline != nil {
+		msg = "Powering Off immediately after the shutdown deadline"
+	}
+	cond, _ := ssa.GetCondition(machine.Status.Conditions, metalv1alpha1.MachineConditionTypePower)
+	if cond.Reason == metalv1alpha1.MachineConditionReasonInProgress && cond.Message == msg {
+		if time.Since(cond.LastTransitionTime.Time) < MachinePowerTimeout {
+			return ctx, nil, nil, nil
+		}
+		status, err = machinePowerCondition(machine, deadline, metav1.Condition{
+			Type:    metalv1alpha1.MachineConditionTypePower,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.MachineConditionReasonTimeout,
+			Message: fmt.Sprintf("Power state did not change to %s within %s", machine.Spec.Power, MachinePowerTimeout),
+		})
+		return ctx, nil, status, err
+	}
+
+	switch machine.Spec.Power {
+	case metalv1alpha1.PowerOn:
+		log.Info(ctx, "Powering on")
+		err = pc.PowerOn(ctx)
+	case metalv1alpha1.PowerOff:
+		log.Info(ctx, "Powering off immediately", "deadline", deadline.Time)
+		err = pc.PowerOff(ctx, true)
+	}
+	if err != nil {
+		status, err = machineCondition(machine, metav1.Condition{
+			Type:    metalv1alpha1.MachineConditionTypePower,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.MachineConditionReasonError,
+			Message: err.Error(),
+		})
+		return ctx, nil, status, err
+	}
+
+	status, err = machinePowerCondition(machine, deadline, metav1.Condition{
+		Type:    metalv1alpha1.MachineConditionTypePower,
+		Status:  metav1.ConditionFalse,
+		Reason:  metalv1alpha1.MachineConditionReasonInProgress,
+		Message: msg,
+	})
+	return ctx, nil, status, err
+}
+
+func (r *MachineReconciler) processLocatorLED(ctx context.Context, machine *metalv1alpha1.Machine) (context.Context, *metalv1alpha1apply.MachineApplyConfiguration, *metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.MachineStatusApplyConfiguration
+	var err error
+
+	info, _ := ctx.Value(ctxkInfo{}).(bmc.Info)
+	led := machineLocatorLED(info.LocatorLED)
+
+	conds := machine.Status.Conditions
+	mod := false
+	if machine.Spec.LocatorLED != "" {
+		ctx = log.WithValues(ctx, "led", machine.Spec.LocatorLED)
+
+		if led != machine.Spec.LocatorLED {
+			b, _ := ctx.Value(ctxkBMC{}).(bmc.BMC)
+			lc, ok := b.(bmc.LEDControl)
+			if !ok {
+				conds, mod = ssa.SetCondition(conds, metav1.Condition{
+					Type:    metalv1alpha1.MachineConditionTypeLocatorLED,
+					Status:  metav1.ConditionFalse,
+					Reason:  metalv1alpha1.MachineConditionReasonUnsupported,
+					Message: fmt.Sprintf("BMC of type %s does not support locator LED control", b.Type()),
+				})
+			} else {
+				log.Info(ctx, "Setting locator LED")
+				var state string
+				state, err = lc.SetLocatorLED(ctx, string(machine.Spec.LocatorLED))
+				if goerrors.Is(err, bmc.ErrUnsupported) {
+					status, err = machineCondition(machine, metav1.Condition{
+						Type:    metalv1alpha1.MachineConditionTypeLocatorLED,
+						Status:  metav1.ConditionFalse,
+						Reason:  metalv1alpha1.MachineConditionReasonUnsupported,
+						Message: err.Error(),
+					})
+					return ctx, nil, status, err
+				}
+				if err != nil {
+					status, err = machineCondition(machine, metav1.Condition{
+						Type:    metalv1alpha1.MachineConditionTypeLocatorLED,
+						Status:  metav1.ConditionFalse,
+						Reason:  metalv1alpha1.MachineConditionReasonError,
+						Message: err.Error(),
+					})
+					return ctx, nil, status, err
+				}
+				led = machineLocatorLED(state)
+			}
+		}
+
+		if led == machine.Spec.LocatorLED {
+			conds, mod = ssa.SetCondition(conds, metav1.Condition{
+				Type:   metalv1alpha1.MachineConditionTypeLocatorLED,
+				Status: metav1.ConditionTrue,
+				Reason: metalv1alpha1.MachineConditionReasonReconciled,
+			})
+		}
+	}
+
+	if machine.Status.LocatorLED != led || mod {
+		var applyst *metalv1alpha1apply.MachineApplyConfiguration
+		applyst, err = metalv1alpha1apply.ExtractMachineStatus(machine, MachineFieldManager)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		status = util.Ensure(applyst.Status).
+			WithLocatorLED(led)
+		status.Conditions = conds
+	}
+
+	return ctx, nil, status, nil
+}
+
+func machinePower(power string) metalv1alpha1.Power {
+	switch power {
+	case string(metalv1alpha1.PowerOn):
+		return metalv1alpha1.PowerOn
+	case string(metalv1alpha1.PowerOff):
+		return metalv1alpha1.PowerOff
+	default:
+		return ""
+	}
+}
+
+func machineLocatorLED(led string) metalv1alpha1.LocatorLED {
+	switch led {
+	case string(metalv1alpha1.LocatorLEDOn):
+		return metalv1alpha1.LocatorLEDOn
+	case string(metalv1alpha1.LocatorLEDOff):
+		return metalv1alpha1.LocatorLEDOff
+	case string(metalv1alpha1.LocatorLEDBlinking):
+		return metalv1alpha1.LocatorLEDBlinking
+	default:
+		return ""
+	}
+}
+
+func machineStatus(machine *metalv1alpha1.Machine, state metalv1alpha1.MachineState, cond metav1.Condition) (*metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(machine.Status.Conditions, cond)
+	if machine.Status.State == state && !mod {
+		if cond.Reason == metalv1alpha1.MachineConditionReasonError {
+			return nil, goerrors.New(cond.Message)
+		}
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractMachineStatus(machine, MachineFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status).
+		WithState(state)
+	status.Conditions = conds
+	return status, nil
+}
+
+func machineCondition(machine *metalv1alpha1.Machine, cond metav1.Condition) (*metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(machine.Status.Conditions, cond)
+	if !mod {
+		if cond.Reason == metalv1alpha1.MachineConditionReasonError {
+			return nil, goerrors.New(cond.Message)
+		}
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractMachineStatus(machine, MachineFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status)
+	status.Conditions = conds
+	return status, nil
+}
+
+// machinePowerCondition is like machineCondition, but also records the shutdown deadline, which is cleared if nil.
+func machinePowerCondition(machine *metalv1alpha1.Machine, deadline *metav1.Time, cond metav1.Condition) (*metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(machine.Status.Conditions, cond)
+	if !mod && deadline.Equal(machine.Status.ShutdownDeadline) {
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractMachineStatus(machine, MachineFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status)
+	status.ShutdownDeadline = deadline
+	status.Conditions = conds
+	return status, nil
+}
+
+// newBMCForOOB returns the BMC of an OOB with the flags the OOB controller resolved for it, so that
+// all controllers connect to the BMC with the same settings.
+func newBMCForOOB(ctx context.Context, c client.Client, cp credentialProvider, namespace string, oob *metalv1alpha1.OOB) (bmc.BMC, error) {
+	if oob.Spec.EndpointRef == nil {
+		return nil, fmt.Errorf("OOB %s has no endpoint", oob.Name)
+	}
+	if oob.Spec.SecretRef == nil {
+		return nil, fmt.Errorf("OOB %s has no secret", oob.Name)
+	}
+	if oob.Spec.Protocol == nil {
+		return nil, fmt.Errorf("OOB %s has no protocol", oob.Name)
+	}
+
+	var ip ipamv1alpha1.IP
+	err := c.Get(ctx, client.ObjectKey{
+		Namespace: OOBTemporaryNamespaceHack,
+		Name:      oob.Spec.EndpointRef.Name,
+	}, &ip)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get IP: %w", err)
+	}
+	if ip.Status.Reserved == nil || !ip.Status.Reserved.Net.IsValid() {
+		return nil, fmt.Errorf("IP %s has no valid address", ip.Name)
+	}
+
+	var secret metalv1alpha1.OOBSecret
+	err = c.Get(ctx, client.ObjectKey{
+		Name: oob.Spec.SecretRef.Name,
+	}, &secret)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get OOBSecret: %w", err)
+	}
+
+	creds, err := cp.Credentials(ctx, &secret)
+	if err != nil {
+		return nil, err
+	}
+	var exp time.Time
+	if secret.Spec.ExpirationTime != nil {
+		exp = secret.Spec.ExpirationTime.Time
+	}
+
+	flags := oobBMCFlags(oob)
+
+	b, err := bmc.NewBMC(string(oob.Spec.Protocol.Name), flags, ip.Status.Reserved.String(), int(oob.Spec.Protocol.Port), creds, exp)
+	if err != nil {
+		return nil, err
+	}
+
+	tc, ok := b.(bmc.TLSControl)
+	if ok {
+		var trust bmc.TLSTrust
+		trust, err = oobTLSTrust(ctx, c, namespace, flags, oob)
+		if err != nil {
+			return nil, err
+		}
+		tc.SetTLSTrust(trust)
+	}
+
+	hc, ok := b.(bmc.HostKeyControl)
+	if ok {
+		hc.SetHostKey(oob.Status.SSHHostKeyFingerprint)
+	}
+
+	return b, nil
+}
+
+// oobBMCFlags returns the flags to connect to the BMC of an OOB. The TLS policy and its CA Secret are
+// usually set in the MAC DB or an OOBProfile, so they are taken from the flags the OOB controller
+// resolved. A pinned certificate is only kept while the pin policy applies, so it selects the pin
+// policy until the flags are resolved.
+func oobBMCFlags(oob *metalv1alpha1.OOB) map[string]string {
+	flags := oobResolvedFlags(oob)
+	if oob.Status.Flags != nil || oob.Status.TLSFingerprint == "" {
+		return flags
+	}
+
+	flags = maps.Clone(flags)
+	if flags == nil {
+		flags = make(map[string]string, 1)
+	}
+	flags[bmc.FlagRedfishTLSPolicy] = bmc.TLSPolicyPin
+	return flags
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
+	r.recorder = mgr.GetEventRecorderFor(MachineEventSource)
+	r.credentials = newStoreCredentialProvider(r.store)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&metalv1alpha1.Machine{}).
+		Watches(&metalv1alpha1.OOB{}, r.enqueueMachinesFromOOB()).
 		Complete(r)
 }
+
+func (r *MachineReconciler) enqueueMachinesFromOOB() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		oob := obj.(*metalv1alpha1.OOB)
+
+		machineList := metalv1alpha1.MachineList{}
+		err := r.List(ctx, &machineList, client.MatchingFields{MachineSpecOOBRef: oob.Name})
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot list Machines: %w", err))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, m := range machineList.Items {
+			if m.DeletionTimestamp != nil {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name: m.Name,
+			}})
+		}
+		return reqs
+	})
+}
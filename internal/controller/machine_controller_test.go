@@ -4,8 +4,386 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	. "sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/bmc"
+	"github.com/ironcore-dev/metal/internal/ssa"
 )
 
 var _ = Describe("Machine Controller", func() {
+	It("should wait for the OOB to become ready", func(ctx SpecContext) {
+		By("Creating a Machine")
+		machine := &metalv1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+			},
+			Spec: metalv1alpha1.MachineSpec{
+				UUID: "01234567-89ab-cdef-0123-456789abcdef",
+				OOBRef: v1.LocalObjectReference{
+					Name: "001122334455",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			Eventually(Get(machine)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Expecting the Machine to have no OOB")
+		Eventually(Object(machine)).Should(SatisfyAll(
+			HaveField("Status.State", metalv1alpha1.MachineStateUneady),
+			WithTransform(machineReadyReason, Equal(metalv1alpha1.MachineConditionReasonNoOOB)),
+		))
+
+		By("Creating an OOB")
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "001122334455",
+			},
+			Spec: metalv1alpha1.OOBSpec{
+				MACAddress: "001122334455",
+			},
+		}
+		Expect(k8sClient.Create(ctx, oob)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, oob)).To(Succeed())
+			Eventually(Get(oob)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Expecting the Machine to wait for the OOB")
+		Eventually(Object(machine)).Should(SatisfyAll(
+			HaveField("Status.State", metalv1alpha1.MachineStateUneady),
+			WithTransform(machineReadyReason, Equal(metalv1alpha1.MachineConditionReasonOOBNotReady)),
+		))
+	})
+
+	It("should connect to the BMC with the TLS policy resolved for the OOB", func() {
+		oob := &metalv1alpha1.OOB{
+			Status: metalv1alpha1.OOBStatus{
+				Flags: map[string]string{
+					bmc.FlagRedfishTLSPolicy:   bmc.TLSPolicyCA,
+					bmc.FlagRedfishTLSCASecret: "bmc-ca",
+				},
+				TLSFingerprint: "stale",
+			},
+		}
+		Expect(oobBMCFlags(oob)).To(Equal(oob.Status.Flags))
+
+		By("Pinning the certificate until the flags are resolved")
+		oob.Status.Flags = nil
+		Expect(oobBMCFlags(oob)).To(HaveKeyWithValue(bmc.FlagRedfishTLSPolicy, bmc.TLSPolicyPin))
+	})
+
+	It("should set the locator LED through the BMC", func(ctx SpecContext) {
+		r := &MachineReconciler{}
+		b := &fakeBMC{}
+		processLocatorLED := func(b bmc.BMC, spec metalv1alpha1.LocatorLED, led string) (*metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+			machine := &metalv1alpha1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: metalv1alpha1.MachineSpec{
+					LocatorLED: spec,
+				},
+			}
+			bctx := context.WithValue(ctx, ctxkBMC{}, b)
+			bctx = context.WithValue(bctx, ctxkInfo{}, bmc.Info{LocatorLED: led})
+			_, _, status, err := r.processLocatorLED(bctx, machine)
+			return status, err
+		}
+
+		By("Reporting the locator LED without a desired state")
+		Expect(processLocatorLED(b, "", "On")).To(SatisfyAll(
+			HaveField("LocatorLED", PointTo(Equal(metalv1alpha1.LocatorLEDOn))),
+			HaveField("Conditions", BeEmpty()),
+		))
+		Expect(b.Calls()).To(BeEmpty())
+
+		By("Setting the desired state")
+		Expect(processLocatorLED(b, metalv1alpha1.LocatorLEDBlinking, "Off")).To(SatisfyAll(
+			HaveField("LocatorLED", PointTo(Equal(metalv1alpha1.LocatorLEDBlinking))),
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeLocatorLED), Equal(metalv1alpha1.MachineConditionReasonReconciled)),
+		))
+		Expect(b.Calls()).To(Equal([]string{"SetLocatorLED(Blinking)"}))
+
+		By("Reporting an error of the BMC")
+		b.err = bmc.ErrUnreachable
+		Expect(processLocatorLED(b, metalv1alpha1.LocatorLEDOn, "Off")).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeLocatorLED), Equal(metalv1alpha1.MachineConditionReasonError)),
+		)
+
+		By("Reporting a state that the BMC does not support without an error")
+		b.err = fmt.Errorf("unable to set LED state to Blinking: %w", bmc.ErrUnsupported)
+		status, err := processLocatorLED(b, metalv1alpha1.LocatorLEDBlinking, "Off")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeLocatorLED), Equal(metalv1alpha1.MachineConditionReasonUnsupported)),
+		)
+
+		By("Reporting a BMC without locator LED control")
+		Expect(processLocatorLED(struct{ bmc.BMC }{b}, metalv1alpha1.LocatorLEDOn, "Off")).To(SatisfyAll(
+			HaveField("LocatorLED", PointTo(Equal(metalv1alpha1.LocatorLEDOff))),
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeLocatorLED), Equal(metalv1alpha1.MachineConditionReasonUnsupported)),
+		))
+		Expect(b.Calls()).To(HaveLen(3))
+	})
+
+	It("should power off immediately after the shutdown deadline", func(ctx SpecContext) {
+		r := &MachineReconciler{
+			shutdownTimeout: time.Minute,
+		}
+		b := &fakeBMC{}
+		machine := &metalv1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: metalv1alpha1.MachineSpec{
+				Power: metalv1alpha1.PowerOff,
+			},
+			Status: metalv1alpha1.MachineStatus{
+				Power: metalv1alpha1.PowerOn,
+			},
+		}
+		processPower := func() (*metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+			_, _, status, err := r.processPower(context.WithValue(ctx, ctxkBMC{}, b), machine)
+			if status != nil {
+				machine.Status.ShutdownDeadline = status.ShutdownDeadline
+				machine.Status.Conditions = status.Conditions
+			}
+			return status, err
+		}
+		powerMessage := func(status *metalv1alpha1apply.MachineStatusApplyConfiguration) string {
+			cond, _ := ssa.GetCondition(status.Conditions, metalv1alpha1.MachineConditionTypePower)
+			return cond.Message
+		}
+
+		By("Shutting down gracefully with the timeout of the controller")
+		Expect(processPower()).To(SatisfyAll(
+			HaveField("ShutdownDeadline.Time", BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second)),
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypePower), Equal(metalv1alpha1.MachineConditionReasonInProgress)),
+			WithTransform(powerMessage, Equal("Shutting down gracefully")),
+		))
+		Expect(b.Calls()).To(Equal([]string{"PowerOff(false)"}))
+
+		By("Waiting for the shutdown before the deadline")
+		Expect(processPower()).To(BeNil())
+		Expect(b.Calls()).To(HaveLen(1))
+
+		By("Powering off immediately at the deadline")
+		deadline := &metav1.Time{Time: time.Now().Add(-time.Second)}
+		machine.Status.ShutdownDeadline = deadline
+		Expect(processPower()).To(SatisfyAll(
+			HaveField("ShutdownDeadline", Equal(deadline)),
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypePower), Equal(metalv1alpha1.MachineConditionReasonInProgress)),
+			WithTransform(powerMessage, Equal("Powering Off immediately after the shutdown deadline")),
+		))
+		Expect(b.Calls()).To(Equal([]string{"PowerOff(false)", "PowerOff(true)"}))
+
+		By("Waiting for the power to change")
+		Expect(processPower()).To(BeNil())
+		Expect(b.Calls()).To(HaveLen(2))
+
+		By("Clearing the deadline once the Machine is off")
+		machine.Status.Power = metalv1alpha1.PowerOff
+		Expect(processPower()).To(SatisfyAll(
+			HaveField("ShutdownDeadline", BeNil()),
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypePower), Equal(metalv1alpha1.MachineConditionReasonReconciled)),
+		))
+		Expect(b.Calls()).To(HaveLen(2))
+
+		By("Shutting down gracefully with the timeout of the Machine")
+		machine.Spec.ShutdownTimeout = &metav1.Duration{Duration: 10 * time.Second}
+		machine.Status.Power = metalv1alpha1.PowerOn
+		Expect(processPower()).To(
+			HaveField("ShutdownDeadline.Time", BeTemporally("~", time.Now().Add(10*time.Second), 5*time.Second)),
+		)
+		Expect(b.Calls()).To(Equal([]string{"PowerOff(false)", "PowerOff(true)", "PowerOff(false)"}))
+	})
+
+	It("should run the operation of the annotation once", func(ctx SpecContext) {
+		recorder := record.NewFakeRecorder(8)
+		r := &MachineReconciler{
+			Client:   k8sClient,
+			recorder: recorder,
+		}
+		b := &fakeBMC{}
+		machine := createMachine(ctx, "", "11111111-2222-3333-4444-000000000011", "a1b2c3000003")
+		processOperation := func(b bmc.BMC, power metalv1alpha1.Power, op string) (*metalv1alpha1apply.MachineStatusApplyConfiguration, error) {
+			Eventually(Update(machine, func() {
+				machine.Spec.Power = power
+				machine.Annotations = map[string]string{
+					metalv1alpha1.MachineOperationKeyName: op,
+				}
+			})).Should(Succeed())
+			bctx, _, status, err := r.processOperation(context.WithValue(ctx, ctxkBMC{}, b), machine)
+			Expect(bctx.Value(ctxkOperation{})).To(Equal(op))
+			Eventually(Object(machine)).Should(HaveField("Annotations", Not(HaveKey(metalv1alpha1.MachineOperationKeyName))))
+			return status, err
+		}
+
+		By("Restarting the Machine immediately")
+		Expect(processOperation(b, "", metalv1alpha1.MachineOperationForceRestart)).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeOperation), Equal(metalv1alpha1.MachineConditionReasonCompleted)),
+		)
+		Expect(b.Calls()).To(Equal([]string{"Reset(true)"}))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal OperationCompleted Operation ForceRestart completed")))
+
+		By("Powering the Machine off immediately")
+		Expect(processOperation(b, metalv1alpha1.PowerOff, metalv1alpha1.MachineOperationForceOff)).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeOperation), Equal(metalv1alpha1.MachineConditionReasonCompleted)),
+		)
+		Expect(b.Calls()).To(Equal([]string{"Reset(true)", "PowerOff(true)"}))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal OperationCompleted Operation ForceOff completed")))
+
+		By("Rejecting to power off a Machine that is meant to be on")
+		Expect(processOperation(b, metalv1alpha1.PowerOn, metalv1alpha1.MachineOperationForceOff)).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeOperation), Equal(metalv1alpha1.MachineConditionReasonConflict)),
+		)
+		Expect(b.Calls()).To(HaveLen(2))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning OperationRejected")))
+
+		By("Reporting an error of the BMC")
+		b.err = bmc.ErrUnreachable
+		Expect(processOperation(b, "", metalv1alpha1.MachineOperationRestart)).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeOperation), Equal(metalv1alpha1.MachineConditionReasonError)),
+		)
+		Expect(b.Calls()).To(Equal([]string{"Reset(true)", "PowerOff(true)", "Reset(false)"}))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning OperationFailed")))
+
+		By("Reporting an operation the BMC does not support")
+		Expect(processOperation(struct{ bmc.BMC }{b}, "", metalv1alpha1.MachineOperationRestart)).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeOperation), Equal(metalv1alpha1.MachineConditionReasonUnsupported)),
+		)
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning OperationUnsupported")))
+
+		By("Reporting an unknown operation")
+		Expect(processOperation(b, "", "Explode")).To(
+			WithTransform(machineStatusReason(metalv1alpha1.MachineConditionTypeOperation), Equal(metalv1alpha1.MachineConditionReasonUnsupported)),
+		)
+		Expect(b.Calls()).To(HaveLen(3))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Operation Explode is unknown")))
+	})
 })
+
+func machineReadyReason(o client.Object) (string, error) {
+	machine, ok := o.(*metalv1alpha1.Machine)
+	if !ok {
+		return "", fmt.Errorf("%s is not a Machine", o.GetName())
+	}
+	var cond metav1.Condition
+	cond, ok = ssa.GetCondition(machine.Status.Conditions, metalv1alpha1.MachineConditionTypeReady)
+	if !ok {
+		return "", fmt.Errorf("%s has no condition of type %s", machine.Name, metalv1alpha1.MachineConditionTypeReady)
+	}
+	return cond.Reason, nil
+}
+
+// machineStatusReason returns the reason of the condition of a type in an applied Machine status.
+func machineStatusReason(typ string) func(*metalv1alpha1apply.MachineStatusApplyConfiguration) (string, error) {
+	return func(status *metalv1alpha1apply.MachineStatusApplyConfiguration) (string, error) {
+		if status == nil {
+			return "", fmt.Errorf("no status was applied")
+		}
+		cond, ok := ssa.GetCondition(status.Conditions, typ)
+		if !ok {
+			return "", fmt.Errorf("no condition of type %s was applied", typ)
+		}
+		return cond.Reason, nil
+	}
+}
+
+// fakeBMC is a BMC that records the calls of the optional interfaces it implements. A BMC without
+// them is struct{ bmc.BMC }{b}.
+type fakeBMC struct {
+	mtx   sync.Mutex
+	calls []string
+	err   error
+}
+
+func (b *fakeBMC) Type() string {
+	return "Fake"
+}
+
+func (b *fakeBMC) Tags() map[string]string {
+	return nil
+}
+
+func (b *fakeBMC) Credentials() (bmc.Credentials, time.Time) {
+	return bmc.Credentials{}, time.Time{}
+}
+
+func (b *fakeBMC) EnsureInitialCredentials(context.Context, []bmc.Credentials, string) error {
+	return nil
+}
+
+func (b *fakeBMC) Connect(context.Context) error {
+	return nil
+}
+
+func (b *fakeBMC) CreateUser(context.Context, bmc.Credentials, string) error {
+	return nil
+}
+
+func (b *fakeBMC) DeleteUsers(context.Context, *regexp.Regexp) error {
+	return nil
+}
+
+func (b *fakeBMC) ReadInfo(context.Context) (bmc.Info, error) {
+	return bmc.Info{}, nil
+}
+
+func (b *fakeBMC) SetLocatorLED(_ context.Context, state string) (string, error) {
+	err := b.call(fmt.Sprintf("SetLocatorLED(%s)", state))
+	if err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+func (b *fakeBMC) PowerOn(context.Context) error {
+	return b.call("PowerOn")
+}
+
+func (b *fakeBMC) PowerOff(_ context.Context, immediate bool) error {
+	return b.call(fmt.Sprintf("PowerOff(%t)", immediate))
+}
+
+func (b *fakeBMC) Reset(_ context.Context, immediate bool) error {
+	return b.call(fmt.Sprintf("Reset(%t)", immediate))
+}
+
+func (b *fakeBMC) ResetManager(context.Context) error {
+	return b.call("ResetManager")
+}
+
+func (b *fakeBMC) call(c string) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.calls = append(b.calls, c)
+	return b.err
+}
+
+// Calls returns all calls in the form "<method>(<arguments>)".
+func (b *fakeBMC) Calls() []string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return slices.Clone(b.calls)
+}
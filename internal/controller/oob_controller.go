@@ -5,10 +5,24 @@ package controller
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	goerrors "errors"
 	"fmt"
+	"maps"
+	"math/big"
+	"net"
 	"os"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	ipamv1alpha1 "github.com/ironcore-dev/ipam/api/ipam/v1alpha1"
 	ipamv1alpha1apply "github.com/ironcore-dev/ipam/clientgo/applyconfiguration/ipam/v1alpha1"
@@ -16,20 +30,27 @@ import (
 	"gopkg.in/yaml.v3"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	v1apply "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1apply "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
 	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
 	"github.com/ironcore-dev/metal/internal/bmc"
+	"github.com/ironcore-dev/metal/internal/credstore"
 	"github.com/ironcore-dev/metal/internal/cru"
 	"github.com/ironcore-dev/metal/internal/log"
 	"github.com/ironcore-dev/metal/internal/ssa"
@@ -39,9 +60,13 @@ import (
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobsecrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=machines,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobprofiles,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ipam.metal.ironcore.dev,resources=ips,verbs=get;list;watch
 // +kubebuilder:rbac:groups=ipam.metal.ironcore.dev,resources=ips/status,verbs=get
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 
 const (
 	OOBFieldManager        = "metal.ironcore.dev/oob"
@@ -51,15 +76,37 @@ const (
 	OOBMacRegex            = `^[0-9A-Fa-f]{12}$`
 	OOBUsernameRegexSuffix = `[a-z]{6}`
 	OOBSpecMACAddress      = ".spec.MACAddress"
+	OOBMacDBKey            = "macdb.yaml"
+	OOBMacPrefixRegex      = `^[0-9A-Fa-f]{1,12}$`
 	// OOBTemporaryNamespaceHack TODO: Remove temporary namespace hack.
 	OOBTemporaryNamespaceHack = "oob"
+	// OOBRestartTimeout is the time to wait for a BMC to come back after a restart before reporting errors.
+	OOBRestartTimeout = 5 * time.Minute
+	// OOBRestartCheckInterval is the interval to check whether a restarting BMC is back.
+	OOBRestartCheckInterval = 15 * time.Second
+	// OOBRestartMinDelay is the time a restarting BMC is given to go down before it is connected to
+	// again, so that a BMC that still answers right after the restart request is not taken as back.
+	OOBRestartMinDelay = 30 * time.Second
+	// OOBEventSource is the component name used when recording OOB events.
+	OOBEventSource = "oob-controller"
+	// OOBBackoffBase is the initial delay before retrying an OOB that failed to reconcile. The delay
+	// doubles with every failed attempt, up to the resync interval.
+	OOBBackoffBase = 5 * time.Second
+	// OOBCertificateInstallTimeout is the time to wait for a BMC to present an installed certificate
+	// before issuing another one.
+	OOBCertificateInstallTimeout = 15 * time.Minute
 )
 
-func NewOOBReconciler(systemNamespace, ipLabelSelector, macDB, usernamePrefix, temporaryPasswordSecret string) (*OOBReconciler, error) {
+func NewOOBReconciler(systemNamespace, ipLabelSelector, macDB, macDBConfigMap, macDBSecret, usernamePrefix, temporaryPasswordSecret string, resyncInterval, detectionTimeout time.Duration, store credstore.Store) (*OOBReconciler, error) {
 	r := &OOBReconciler{
 		systemNamespace:         systemNamespace,
+		store:                   store,
+		macDBConfigMap:          macDBConfigMap,
+		macDBSecret:             macDBSecret,
 		usernamePrefix:          usernamePrefix,
 		temporaryPasswordSecret: temporaryPasswordSecret,
+		resyncInterval:          resyncInterval,
+		detectionTimeout:        detectionTimeout,
 	}
 	var err error
 
@@ -72,16 +119,35 @@ func NewOOBReconciler(systemNamespace, ipLabelSelector, macDB, usernamePrefix, t
 	if r.temporaryPasswordSecret == "" {
 		return nil, fmt.Errorf("temporary password secret name cannot be empty")
 	}
+	if r.resyncInterval <= 0 {
+		return nil, fmt.Errorf("resync interval must be positive")
+	}
+	if r.store == nil {
+		return nil, fmt.Errorf("credential store cannot be nil")
+	}
+	r.backoff = workqueue.NewItemExponentialFailureRateLimiter(OOBBackoffBase, r.resyncInterval)
 
 	r.ipLabelSelector, err = labels.Parse(ipLabelSelector)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse IP label selector: %w", err)
 	}
 
-	r.macDB, err = loadMacDB(macDB)
+	sources := 0
+	for _, src := range []string{macDB, r.macDBConfigMap, r.macDBSecret} {
+		if src != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, fmt.Errorf("MAC DB can only be loaded from one of a file, a ConfigMap, or a Secret")
+	}
+
+	var db util.PrefixMap[access]
+	db, err = loadMacDB(macDB)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load MAC DB: %w", err)
 	}
+	r.macDB.Store(&db)
 
 	r.usernameRegex, err = regexp.Compile(r.usernamePrefix + OOBUsernameRegexSuffix)
 	if err != nil {
@@ -101,14 +167,26 @@ type OOBReconciler struct {
 	client.Client
 	systemNamespace         string
 	ipLabelSelector         labels.Selector
-	macDB                   util.PrefixMap[access]
+	macDB                   atomic.Pointer[util.PrefixMap[access]]
+	macDBConfigMap          string
+	macDBSecret             string
 	usernamePrefix          string
 	temporaryPassword       string
 	temporaryPasswordSecret string
 	usernameRegex           *regexp.Regexp
 	macRegex                *regexp.Regexp
+	resyncInterval          time.Duration
+	detectionTimeout        time.Duration
+	backoff                 workqueue.RateLimiter
+	store                   credstore.Store
+	credentials             credentialProvider
+	recorder                record.EventRecorder
 }
 
+type ctxkOOBHost struct{}
+type ctxkBMC struct{}
+type ctxkInfo struct{}
+
 type access struct {
 	Ignore             bool                   `yaml:"ignore"`
 	Protocol           metalv1alpha1.Protocol `yaml:"protocol"`
@@ -117,7 +195,22 @@ type access struct {
 }
 
 func (r *OOBReconciler) PreStart(ctx context.Context) error {
-	return r.ensureTemporaryPassword(ctx)
+	err := r.ensureTemporaryPassword(ctx)
+	if err != nil {
+		return err
+	}
+
+	if r.macDBConfigMap == "" && r.macDBSecret == "" {
+		return nil
+	}
+	var db util.PrefixMap[access]
+	db, err = r.readMacDB(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load MAC DB: %w", err)
+	}
+	r.macDB.Store(&db)
+	log.Info(ctx, "Loaded MAC DB", "entries", len(db))
+	return nil
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -126,13 +219,50 @@ func (r *OOBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	var oob metalv1alpha1.OOB
 	err := r.Get(ctx, req.NamespacedName, &oob)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			r.backoff.Forget(req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("cannot get OOB: %w", err))
 	}
 
 	if !oob.DeletionTimestamp.IsZero() {
+		r.backoff.Forget(oob.Name)
 		return ctrl.Result{}, r.finalize(ctx, &oob)
 	}
-	return r.reconcile(ctx, &oob)
+
+	res, err := r.reconcile(ctx, &oob)
+	return r.resync(ctx, &oob, res, err)
+}
+
+// resync schedules the next reconciliation of an OOB. OOBs are refreshed at a jittered resync
+// interval, while failed reconciliations are retried with a per-OOB exponential backoff. Errors
+// caused by the BMC are already recorded in the status and are retried with the same backoff. The
+// backoff is reset by every successful reconciliation, so that the reconciliations triggered by the
+// updates of the controller itself do not count as failed attempts.
+func (r *OOBReconciler) resync(ctx context.Context, oob *metalv1alpha1.OOB, res ctrl.Result, err error) (ctrl.Result, error) {
+	if err != nil && oob.Status.State != metalv1alpha1.OOBStateError {
+		return res, err
+	}
+	if err == nil {
+		r.backoff.Forget(oob.Name)
+	}
+	if !res.IsZero() {
+		return res, err
+	}
+
+	switch {
+	case oobRestarting(oob):
+		res.RequeueAfter = OOBRestartCheckInterval
+	case err != nil:
+		res.RequeueAfter = r.backoff.When(oob.Name)
+	case oob.Status.State == metalv1alpha1.OOBStateReady || oob.Status.State == metalv1alpha1.OOBStateUnready || oob.Status.State == metalv1alpha1.OOBStateError:
+		res.RequeueAfter = wait.Jitter(r.resyncInterval, 0.1)
+	}
+
+	if err != nil {
+		log.Error(ctx, err, "retryAfter", res.RequeueAfter)
+	}
+	return res, nil
 }
 
 func (r *OOBReconciler) finalize(ctx context.Context, oob *metalv1alpha1.OOB) error {
@@ -202,9 +332,8 @@ func (r *OOBReconciler) reconcile(ctx context.Context, oob *metalv1alpha1.OOB) (
 	var ok bool
 	var err error
 
-	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "IgnoreAnnotation"), oob, r.processIgnoreAnnotation)
-	_, ignored := oob.Annotations[OOBIgnoreAnnotation]
-	if !ok || ignored {
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Ignore"), oob, r.processIgnore)
+	if !ok || oob.Status.State == metalv1alpha1.OOBStateIgnored {
 		if err == nil {
 			log.Debug(ctx, "Reconciled successfully")
 		}
@@ -220,6 +349,63 @@ func (r *OOBReconciler) reconcile(ctx context.Context, oob *metalv1alpha1.OOB) (
 	}
 
 	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Endpoint"), oob, r.processEndpoint)
+	_, found := ctx.Value(ctxkOOBHost{}).(string)
+	if !ok || !found {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	if oobRestartSettling(oob) {
+		log.Debug(ctx, "Waiting for the restarting BMC to go down")
+		return ctrl.Result{}, nil
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Credentials"), oob, r.processCredentials)
+	_, found = ctx.Value(ctxkBMC{}).(bmc.BMC)
+	if !ok || !found {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Operation"), oob, r.processOperation)
+	_, found = ctx.Value(ctxkOperation{}).(string)
+	if !ok || found {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Info"), oob, r.processInfo)
+	_, found = ctx.Value(ctxkInfo{}).(bmc.Info)
+	if !ok || !found {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "CipherSuites"), oob, r.processCipherSuites)
+	if !ok {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Certificate"), oob, r.processCertificate)
+	if !ok {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, ok, err = r.applyOrContinue(log.WithValues(ctx, "phase", "Machine"), oob, r.processMachine)
 	if !ok {
 		if err == nil {
 			log.Debug(ctx, "Reconciled successfully")
@@ -262,23 +448,39 @@ func (r *OOBReconciler) applyOrContinue(ctx context.Context, oob *metalv1alpha1.
 		}
 
 		cond, ok := ssa.GetCondition(status.Conditions, metalv1alpha1.OOBConditionTypeReady)
-		if ok && cond.Status == metav1.ConditionFalse && cond.Reason == metalv1alpha1.OOBConditionReasonError {
-			err = fmt.Errorf(cond.Message)
+		if ok && cond.Status == metav1.ConditionFalse && status.State != nil && *status.State == metalv1alpha1.OOBStateError {
+			err = goerrors.New(cond.Message)
 		}
 	}
 
 	return ctx, apply == nil, err
 }
 
-func (r *OOBReconciler) processIgnoreAnnotation(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+// processIgnore ignores an OOB that has the ignore annotation, or whose OOBProfile or MAC DB entry
+// says to ignore it.
+func (r *OOBReconciler) processIgnore(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
 	_, ok := oob.Annotations[OOBIgnoreAnnotation]
+	var msg string
+	if !ok && oob.Spec.MACAddress != "" {
+		a, profile, err := r.resolveAccess(ctx, oob.Spec.MACAddress)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		ok = a.Ignore
+		if profile != nil {
+			msg = fmt.Sprintf("ignored by OOBProfile %s", profile.Name)
+		} else {
+			msg = "ignored by the MAC DB"
+		}
+	}
 	if ok {
 		var status *metalv1alpha1apply.OOBStatusApplyConfiguration
 		state := metalv1alpha1.OOBStateIgnored
 		conds, mod := ssa.SetCondition(oob.Status.Conditions, metav1.Condition{
-			Type:   metalv1alpha1.OOBConditionTypeReady,
-			Status: metav1.ConditionFalse,
-			Reason: metalv1alpha1.OOBConditionReasonIgnored,
+			Type:    metalv1alpha1.OOBConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.OOBConditionReasonIgnored,
+			Message: msg,
 		})
 		if oob.Status.State != state || mod {
 			applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
@@ -460,8 +662,8 @@ func (r *OOBReconciler) processEndpoint(ctx context.Context, oob *metalv1alpha1.
 
 	if ip.Labels[OOBIPMacLabel] != oob.Spec.MACAddress {
 		state := metalv1alpha1.OOBStateError
-		conds, mod := ssa.SetErrorCondition(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady,
-			fmt.Errorf("BadEndpoint: endpoint has incorrect MAC address: expected %s, actual %s", oob.Spec.MACAddress, ip.Labels[OOBIPMacLabel]))
+		conds, mod := ssa.SetErrorConditionWithReason(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady, metalv1alpha1.OOBConditionReasonBadEndpoint,
+			fmt.Errorf("endpoint has incorrect MAC address: expected %s, actual %s", oob.Spec.MACAddress, ip.Labels[OOBIPMacLabel]))
 		if oob.Status.State != state || mod {
 			applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
 			if err != nil {
@@ -476,8 +678,8 @@ func (r *OOBReconciler) processEndpoint(ctx context.Context, oob *metalv1alpha1.
 
 	if ip.Status.State != ipamv1alpha1.CFinishedIPState || ip.Status.Reserved == nil || !ip.Status.Reserved.Net.IsValid() {
 		state := metalv1alpha1.OOBStateError
-		conds, mod := ssa.SetErrorCondition(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady,
-			fmt.Errorf("BadEndpoint: endpoint has no valid IP address"))
+		conds, mod := ssa.SetErrorConditionWithReason(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady, metalv1alpha1.OOBConditionReasonBadEndpoint,
+			fmt.Errorf("endpoint has no valid IP address"))
 		if oob.Status.State != state || mod {
 			applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
 			if err != nil {
@@ -489,10 +691,11 @@ func (r *OOBReconciler) processEndpoint(ctx context.Context, oob *metalv1alpha1.
 		}
 		return ctx, apply, status, nil
 	}
+	ctx = context.WithValue(ctx, ctxkOOBHost{}, ip.Status.Reserved.String())
 
 	if oob.Status.State == metalv1alpha1.OOBStateError {
 		cond, _ := ssa.GetCondition(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady)
-		if strings.HasPrefix(cond.Message, "BadEndpoint: ") {
+		if cond.Reason == metalv1alpha1.OOBConditionReasonBadEndpoint {
 			state := metalv1alpha1.OOBStateUnready
 			conds, _ := ssa.SetCondition(oob.Status.Conditions, metav1.Condition{
 				Type:   metalv1alpha1.OOBConditionTypeReady,
@@ -514,101 +717,1303 @@ func (r *OOBReconciler) processEndpoint(ctx context.Context, oob *metalv1alpha1.
 	return ctx, apply, status, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *OOBReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	r.Client = mgr.GetClient()
+func (r *OOBReconciler) processCredentials(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	var apply *metalv1alpha1apply.OOBApplyConfiguration
+	var status *metalv1alpha1apply.OOBStatusApplyConfiguration
+	var err error
 
-	c, err := cru.CreateController(mgr, &metalv1alpha1.OOB{}, r)
+	var a access
+	var profile *metalv1alpha1.OOBProfile
+	a, profile, err = r.resolveAccess(ctx, oob.Spec.MACAddress)
 	if err != nil {
-		return err
+		return ctx, nil, nil, err
+	}
+	if profile != nil {
+		ctx = log.WithValues(ctx, "profile", profile.Name)
 	}
 
-	err = c.Watch(source.Kind(mgr.GetCache(), &ipamv1alpha1.IP{}), r.enqueueOOBFromIP())
-	if err != nil {
-		return err
+	host, _ := ctx.Value(ctxkOOBHost{}).(string)
+
+	if oob.Spec.Protocol == nil {
+		if a.Protocol.Name == "" && r.detectionTimeout == 0 {
+			log.Debug(ctx, "No protocol in MAC DB and detection is disabled")
+			status, err = oobStatus(oob, metalv1alpha1.OOBStateUnready, metav1.Condition{
+				Type:    metalv1alpha1.OOBConditionTypeReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  metalv1alpha1.OOBConditionReasonNoProtocol,
+				Message: "no protocol is configured in the MAC DB and protocol detection is disabled",
+			})
+			return ctx, nil, status, err
+		}
+		if a.Protocol.Name == "" {
+			log.Debug(ctx, "Detecting protocol")
+			var d bmc.Detection
+			d, err = bmc.DetectProtocol(ctx, host, r.detectionTimeout)
+			if err != nil {
+				status, err = oobStatus(oob, metalv1alpha1.OOBStateUnready, metav1.Condition{
+					Type:    metalv1alpha1.OOBConditionTypeReady,
+					Status:  metav1.ConditionFalse,
+					Reason:  metalv1alpha1.OOBConditionReasonNoProtocol,
+					Message: err.Error(),
+				})
+				return ctx, nil, status, err
+			}
+
+			log.Info(ctx, "Setting detected protocol", "protocol", d.Type, "port", d.Port)
+			apply, err = metalv1alpha1apply.ExtractOOB(oob, OOBFieldManager)
+			if err != nil {
+				return ctx, nil, nil, err
+			}
+			apply = apply.WithSpec(util.Ensure(apply.Spec).
+				WithProtocol(metalv1alpha1apply.Protocol().
+					WithName(metalv1alpha1.ProtocolName(d.Type)).
+					WithPort(int32(d.Port))))
+			status, err = oobCondition(oob, metav1.Condition{
+				Type:    metalv1alpha1.OOBConditionTypeProtocol,
+				Status:  metav1.ConditionTrue,
+				Reason:  metalv1alpha1.OOBConditionReasonDetected,
+				Message: d.Reason,
+			})
+			return ctx, apply, status, err
+		}
+
+		log.Debug(ctx, "Setting protocol from MAC DB", "protocol", a.Protocol.Name)
+		apply, err = metalv1alpha1apply.ExtractOOB(oob, OOBFieldManager)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		apply = apply.WithSpec(util.Ensure(apply.Spec).
+			WithProtocol(metalv1alpha1apply.Protocol().
+				WithName(a.Protocol.Name).
+				WithPort(a.Protocol.Port)))
+		return ctx, apply, nil, nil
 	}
+	ctx = log.WithValues(ctx, "protocol", oob.Spec.Protocol.Name)
 
-	return mgr.Add(c)
-}
+	var secret metalv1alpha1.OOBSecret
+	if oob.Spec.SecretRef != nil {
+		err = r.Get(ctx, client.ObjectKey{
+			Name: oob.Spec.SecretRef.Name,
+		}, &secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctx, nil, nil, fmt.Errorf("cannot get OOBSecret: %w", err)
+		}
 
-func (r *OOBReconciler) enqueueOOBFromIP() handler.EventHandler {
-	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
-		ip := obj.(*ipamv1alpha1.IP)
+		if errors.IsNotFound(err) || !secret.DeletionTimestamp.IsZero() {
+			oob.Spec.SecretRef = nil
 
-		if ip.Namespace != OOBTemporaryNamespaceHack {
-			return nil
+			apply, err = metalv1alpha1apply.ExtractOOB(oob, OOBFieldManager)
+			if err != nil {
+				return ctx, nil, nil, err
+			}
+			apply = apply.WithSpec(util.Ensure(apply.Spec))
+			apply.Spec.SecretRef = nil
+			return ctx, apply, nil, nil
 		}
-		if !r.ipLabelSelector.Matches(labels.Set(ip.Labels)) {
-			return nil
+	} else {
+		err = r.Get(ctx, client.ObjectKey{
+			Name: oob.Name,
+		}, &secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctx, nil, nil, fmt.Errorf("cannot get OOBSecret: %w", err)
 		}
 
-		mac, ok := ip.Labels[OOBIPMacLabel]
-		if !ok || !r.macRegex.MatchString(mac) {
-			log.Error(ctx, fmt.Errorf("invalid MAC address: %s", mac))
-			return nil
+		if err == nil && secret.DeletionTimestamp.IsZero() && secret.Spec.MACAddress == oob.Spec.MACAddress {
+			log.Debug(ctx, "Adopting existing OOBSecret", "secret", secret.Name)
+			oob.Spec.SecretRef = &v1.LocalObjectReference{
+				Name: secret.Name,
+			}
+
+			apply, err = metalv1alpha1apply.ExtractOOB(oob, OOBFieldManager)
+			if err != nil {
+				return ctx, nil, nil, err
+			}
+			apply = apply.WithSpec(util.Ensure(apply.Spec).
+				WithSecretRef(*oob.Spec.SecretRef))
+			return ctx, apply, nil, nil
 		}
+	}
 
-		oobList := metalv1alpha1.OOBList{}
-		err := r.List(ctx, &oobList, client.MatchingFields{OOBSpecMACAddress: mac})
+	if oob.Spec.SecretRef != nil && secret.Spec.MACAddress != oob.Spec.MACAddress {
+		status, err = oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonBadCredentials, fmt.Errorf("secret has incorrect MAC address: expected %s, actual %s", oob.Spec.MACAddress, secret.Spec.MACAddress))
+		return ctx, nil, status, err
+	}
+
+	var creds bmc.Credentials
+	var exp time.Time
+	if oob.Spec.SecretRef != nil {
+		ctx = log.WithValues(ctx, "secret", secret.Name)
+		creds, err = r.credentials.Credentials(ctx, &secret)
 		if err != nil {
-			log.Error(ctx, fmt.Errorf("cannot list OOBs: %w", err))
-			return nil
+			status, err = oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonBadCredentials, err)
+			return ctx, nil, status, err
+		}
+		if secret.Spec.ExpirationTime != nil {
+			exp = secret.Spec.ExpirationTime.Time
 		}
+	}
 
-		var reqs []reconcile.Request
-		for _, o := range oobList.Items {
-			if o.DeletionTimestamp != nil {
-				continue
+	flags := oobFlags(a, oob)
+	if !maps.Equal(oob.Status.Flags, flags) {
+		log.Debug(ctx, "Resolved flags", "flags", flags)
+		status, err = oobFlagsStatus(oob, flags)
+		return ctx, nil, status, err
+	}
+
+	b, err := bmc.NewBMC(string(oob.Spec.Protocol.Name), flags, host, int(oob.Spec.Protocol.Port), creds, exp)
+	if err != nil {
+		status, err = oobErrorStatus(oob, oobErrorReason(err), err)
+		return ctx, nil, status, err
+	}
+
+	tc, ok := b.(bmc.TLSControl)
+	if ok {
+		pin := bmc.RedfishTLSPolicy(flags) == bmc.TLSPolicyPin
+		if pin && oob.Status.TLSFingerprint == "" {
+			var fingerprint string
+			fingerprint, err = tc.CertificateFingerprint(ctx)
+			if err != nil {
+				status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot read certificate: %w", err))
+				return ctx, nil, status, err
 			}
 
-			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
-				Name: o.Name,
-			}})
+			log.Info(ctx, "Pinning certificate", "fingerprint", fingerprint)
+			status, err = oobTLSFingerprintStatus(oob, fingerprint)
+			return ctx, nil, status, err
+		}
+		if !pin && oob.Status.TLSFingerprint != "" {
+			log.Info(ctx, "Removing pinned certificate")
+			status, err = oobTLSFingerprintStatus(oob, "")
+			return ctx, nil, status, err
 		}
 
-		if len(oobList.Items) == 0 && ip.Status.State == ipamv1alpha1.CFinishedIPState && ip.Status.Reserved != nil {
-			oob := metalv1alpha1.OOB{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: mac,
-				},
+		var trust bmc.TLSTrust
+		trust, err = oobTLSTrust(ctx, r, r.systemNamespace, flags, oob)
+		if err != nil {
+			status, err = oobErrorStatus(oob, oobErrorReason(err), err)
+			return ctx, nil, status, err
+		}
+		tc.SetTLSTrust(trust)
+	}
+
+	hc, ok := b.(bmc.HostKeyControl)
+	if ok {
+		if oob.Status.SSHHostKeyFingerprint == "" {
+			var fingerprint string
+			fingerprint, err = hc.HostKeyFingerprint(ctx)
+			if err != nil {
+				status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot read host key: %w", err))
+				return ctx, nil, status, err
 			}
-			apply := metalv1alpha1apply.OOB(oob.Name, oob.Namespace).
-				WithFinalizers(OOBFinalizer).
-				WithSpec(metalv1alpha1apply.OOBSpec().
-					WithMACAddress(mac))
-			err = r.Patch(ctx, &oob, ssa.Apply(apply), client.FieldOwner(OOBFieldManager), client.ForceOwnership)
+
+			log.Info(ctx, "Pinning host key", "fingerprint", fingerprint)
+			status, err = oobSSHHostKeyStatus(oob, fingerprint)
+			return ctx, nil, status, err
+		}
+		hc.SetHostKey(oob.Status.SSHHostKeyFingerprint)
+	}
+
+	if oob.Spec.SecretRef == nil {
+		if profile != nil {
+			a.DefaultCredentials, err = oobProfileCredentials(ctx, r, r.systemNamespace, profile)
 			if err != nil {
-				log.Error(ctx, fmt.Errorf("cannot apply OOB: %w", err))
+				status, err = oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonBadCredentials, fmt.Errorf("cannot get default credentials: %w", err))
+				return ctx, nil, status, err
 			}
 		}
 
-		return reqs
-	})
+		log.Info(ctx, "Taking over BMC with default credentials")
+		err = b.EnsureInitialCredentials(ctx, a.DefaultCredentials, r.temporaryPassword)
+		if err != nil {
+			status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot ensure initial credentials: %w", err))
+			return ctx, nil, status, err
+		}
+
+		creds.Username, err = password.Generate(6, 0, 0, true, true)
+		if err != nil {
+			return ctx, nil, nil, fmt.Errorf("cannot generate username: %w", err)
+		}
+		creds.Username = r.usernamePrefix + creds.Username
+		creds.Password, err = password.Generate(16, 4, 0, false, true)
+		if err != nil {
+			return ctx, nil, nil, fmt.Errorf("cannot generate password: %w", err)
+		}
+		ctx = log.WithValues(ctx, "user", creds.Username)
+
+		log.Info(ctx, "Creating new BMC user")
+		err = b.CreateUser(ctx, creds, r.temporaryPassword)
+		if err != nil {
+			status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot create user: %w", err))
+			return ctx, nil, status, err
+		}
+		creds, exp = b.Credentials()
+
+		secret = metalv1alpha1.OOBSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: oob.Name,
+			},
+		}
+		var ref string
+		ref, err = r.store.Put(ctx, secret.Name, creds.Password)
+		if err != nil {
+			return ctx, nil, nil, fmt.Errorf("cannot store password: %w", err)
+		}
+		secretSpec := metalv1alpha1apply.OOBSecretSpec().
+			WithMACAddress(oob.Spec.MACAddress).
+			WithUsername(creds.Username).
+			WithPasswordRef(ref)
+		if !exp.IsZero() {
+			secretSpec = secretSpec.WithExpirationTime(metav1.NewTime(exp))
+		}
+		secretApply := metalv1alpha1apply.OOBSecret(secret.Name, "").
+			WithSpec(secretSpec)
+		err = r.Patch(ctx, &secret, ssa.Apply(secretApply), client.FieldOwner(OOBFieldManager), client.ForceOwnership)
+		if err != nil {
+			return ctx, nil, nil, fmt.Errorf("cannot apply OOBSecret: %w", err)
+		}
+
+		oob.Spec.SecretRef = &v1.LocalObjectReference{
+			Name: secret.Name,
+		}
+		apply, err = metalv1alpha1apply.ExtractOOB(oob, OOBFieldManager)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		apply = apply.WithSpec(util.Ensure(apply.Spec).
+			WithSecretRef(*oob.Spec.SecretRef))
+	} else {
+		err = b.Connect(ctx)
+		if err != nil {
+			status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot connect: %w", err))
+			return ctx, nil, status, err
+		}
+	}
+
+	// While the credentials are rotated, the new user is not stored yet and must not be deleted.
+	if !oobSecretRotating(&secret) {
+		err = b.DeleteUsers(ctx, r.usernameRegex)
+		if err != nil {
+			status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot delete stale users: %w", err))
+			return ctx, apply, status, err
+		}
+	}
+	ctx = context.WithValue(ctx, ctxkBMC{}, b)
+
+	return ctx, apply, status, nil
 }
 
-func loadMacDB(dbFile string) (util.PrefixMap[access], error) {
-	if dbFile == "" {
-		return make(util.PrefixMap[access]), nil
+func (r *OOBReconciler) processOperation(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.OOBStatusApplyConfiguration
+	var err error
+
+	op, ok := oob.Annotations[metalv1alpha1.OOBOperationKeyName]
+	if !ok {
+		return ctx, nil, nil, nil
 	}
+	ctx = log.WithValues(ctx, "operation", op)
 
-	data, err := os.ReadFile(dbFile)
+	// The annotation is removed before running the operation, so that it is executed at most once.
+	base := oob.DeepCopy()
+	delete(oob.Annotations, metalv1alpha1.OOBOperationKeyName)
+	err = r.Patch(ctx, oob, client.MergeFrom(base))
 	if err != nil {
-		return nil, fmt.Errorf("cannot read %s: %w", dbFile, err)
+		return ctx, nil, nil, fmt.Errorf("cannot remove operation annotation: %w", err)
 	}
 
-	var dbf struct {
-		MACs []struct {
-			Prefix string `yaml:"prefix"`
-			access `yaml:",inline"`
-		} `yaml:"macs"`
+	b, _ := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	now := metav1.Now()
+	cond := metav1.Condition{
+		Type:               metalv1alpha1.OOBConditionTypeOperation,
+		LastTransitionTime: now,
 	}
-	err = yaml.Unmarshal(data, &dbf)
+	var mrc bmc.ManagerResetControl
+	supported := false
+	switch op {
+	case metalv1alpha1.OOBOperationRestart:
+		mrc, supported = b.(bmc.ManagerResetControl)
+		cond.Message = fmt.Sprintf("Operation %s is not supported by BMC of type %s", op, b.Type())
+	default:
+		cond.Message = fmt.Sprintf("Operation %s is unknown", op)
+	}
+	if !supported {
+		log.Info(ctx, "Rejecting unsupported operation")
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.OOBConditionReasonUnsupported
+		r.recorder.Event(oob, v1.EventTypeWarning, "OperationUnsupported", cond.Message)
+		status, err = oobCondition(oob, cond)
+		return ctx, nil, status, err
+	}
+	ctx = context.WithValue(ctx, ctxkOperation{}, op)
+
+	log.Info(ctx, "Restarting BMC")
+	err = mrc.ResetManager(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("cannot unmarshal %s: %w", dbFile, err)
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.OOBConditionReasonError
+		cond.Message = fmt.Sprintf("Operation %s failed at %s: %s", op, now.UTC().Format(time.RFC3339), err)
+		r.recorder.Event(oob, v1.EventTypeWarning, "OperationFailed", cond.Message)
+		oob.Status.Conditions, _ = ssa.SetCondition(oob.Status.Conditions, cond)
+		status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot restart BMC: %w", err))
+		return ctx, nil, status, err
 	}
 
-	db := make(util.PrefixMap[access], len(dbf.MACs))
-	for _, m := range dbf.MACs {
+	cond.Status = metav1.ConditionTrue
+	cond.Reason = metalv1alpha1.OOBConditionReasonCompleted
+	cond.Message = fmt.Sprintf("Operation %s completed at %s", op, now.UTC().Format(time.RFC3339))
+	r.recorder.Event(oob, v1.EventTypeNormal, "OperationCompleted", cond.Message)
+	oob.Status.Conditions, _ = ssa.SetCondition(oob.Status.Conditions, cond)
+	status, err = oobStatus(oob, metalv1alpha1.OOBStateUnready, metav1.Condition{
+		Type:               metalv1alpha1.OOBConditionTypeReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             metalv1alpha1.OOBConditionReasonRestarting,
+		Message:            fmt.Sprintf("BMC restart requested at %s", now.UTC().Format(time.RFC3339)),
+		LastTransitionTime: now,
+	})
+	return ctx, nil, status, err
+}
+
+func (r *OOBReconciler) processInfo(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.OOBStatusApplyConfiguration
+	var err error
+
+	b, ok := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	if !ok {
+		return ctx, nil, nil, fmt.Errorf("BMC is not available")
+	}
+
+	var info bmc.Info
+	info, err = b.ReadInfo(ctx)
+	if err != nil {
+		status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot read BMC info: %w", err))
+		return ctx, nil, status, err
+	}
+	ctx = context.WithValue(ctx, ctxkInfo{}, info)
+
+	var typ metalv1alpha1.OOBType
+	switch info.Type {
+	case "BMC":
+		typ = metalv1alpha1.OOBTypeMachine
+	case "Router":
+		typ = metalv1alpha1.OOBTypeRouter
+	case "Switch":
+		typ = metalv1alpha1.OOBTypeSwitch
+	default:
+		status, err = oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonUnsupportedType, fmt.Errorf("BMC reports unsupported type: %s", info.Type))
+		return ctx, nil, status, err
+	}
+
+	state := metalv1alpha1.OOBStateReady
+	conds, mod := ssa.SetCondition(oob.Status.Conditions, metav1.Condition{
+		Type:   metalv1alpha1.OOBConditionTypeReady,
+		Status: metav1.ConditionTrue,
+		Reason: metalv1alpha1.OOBConditionReasonReady,
+	})
+	if oob.Status.Type != typ ||
+		oob.Status.Manufacturer != info.Manufacturer ||
+		oob.Status.SKU != info.SKU ||
+		oob.Status.SerialNumber != info.SerialNumber ||
+		oob.Status.FirmwareVersion != info.FWVersion ||
+		oob.Status.State != state || mod {
+		var applyst *metalv1alpha1apply.OOBApplyConfiguration
+		applyst, err = metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		status = util.Ensure(applyst.Status).
+			WithType(typ).
+			WithManufacturer(info.Manufacturer).
+			WithSKU(info.SKU).
+			WithSerialNumber(info.SerialNumber).
+			WithFirmwareVersion(info.FWVersion).
+			WithState(state)
+		status.Conditions = conds
+	}
+
+	return ctx, nil, status, nil
+}
+
+func (r *OOBReconciler) processCipherSuites(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.OOBStatusApplyConfiguration
+	var err error
+
+	b, _ := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	csc, ok := b.(bmc.CipherSuiteControl)
+	if !ok {
+		return ctx, nil, nil, nil
+	}
+
+	var weak []int
+	weak, err = csc.EnsureCipherSuites(ctx)
+	if err != nil {
+		status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot ensure cipher suites: %w", err))
+		return ctx, nil, status, err
+	}
+
+	cond := metav1.Condition{
+		Type:   metalv1alpha1.OOBConditionTypeCipherSuites,
+		Status: metav1.ConditionTrue,
+		Reason: metalv1alpha1.OOBConditionReasonSecure,
+	}
+	if len(weak) > 0 {
+		cond = metav1.Condition{
+			Type:    metalv1alpha1.OOBConditionTypeCipherSuites,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.OOBConditionReasonWeakCipherSuites,
+			Message: fmt.Sprintf("BMC has weak cipher suites enabled: %v", weak),
+		}
+	}
+	status, err = oobCondition(oob, cond)
+	return ctx, nil, status, err
+}
+
+// processCertificate replaces the certificate of the BMC with one issued by the CA of the flags,
+// unless the BMC presents a certificate of the CA that is not due for renewal. After installing a
+// certificate, it waits for the BMC to present it for OOBCertificateInstallTimeout.
+func (r *OOBReconciler) processCertificate(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.OOBStatusApplyConfiguration
+	var err error
+
+	b, _ := ctx.Value(ctxkBMC{}).(bmc.BMC)
+	flags := b.Tags()
+	name := flags[bmc.FlagRedfishCertificateCASecret]
+	if name == "" {
+		return ctx, nil, nil, nil
+	}
+	cc, ok := b.(bmc.CertificateControl)
+	if !ok {
+		return ctx, nil, nil, nil
+	}
+
+	var caCert *x509.Certificate
+	var caKey crypto.Signer
+	caCert, caKey, err = oobCertificateCA(ctx, r, r.systemNamespace, name)
+	if err != nil {
+		status, err = oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonError, err)
+		return ctx, nil, status, err
+	}
+
+	var cert *x509.Certificate
+	cert, err = cc.Certificate(ctx)
+	if err != nil {
+		status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot read certificate: %w", err))
+		return ctx, nil, status, err
+	}
+
+	validity := bmc.RedfishCertificateValidity(flags)
+	renewal := cert.NotAfter.Add(-validity / 3)
+	if oobCertificateIssuedBy(cert, caCert) && time.Now().Before(renewal) {
+		fingerprint := ""
+		if oob.Status.PendingTLSFingerprint != "" && oob.Status.PendingTLSFingerprint == bmc.Fingerprint(cert) {
+			fingerprint = oob.Status.PendingTLSFingerprint
+			log.Info(ctx, "Pinning presented certificate", "fingerprint", fingerprint)
+		}
+		status, err = oobCertificateStatus(oob, cert.NotAfter, fingerprint, "", metav1.Condition{
+			Type:    metalv1alpha1.OOBConditionTypeCertificate,
+			Status:  metav1.ConditionTrue,
+			Reason:  metalv1alpha1.OOBConditionReasonIssued,
+			Message: fmt.Sprintf("certificate is valid until %s and is renewed after %s", cert.NotAfter.UTC().Format(time.RFC3339), renewal.UTC().Format(time.RFC3339)),
+		})
+		return ctx, nil, status, err
+	}
+
+	cond := apimeta.FindStatusCondition(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeCertificate)
+	if cond != nil && cond.Reason == metalv1alpha1.OOBConditionReasonInstalling && time.Since(cond.LastTransitionTime.Time) < OOBCertificateInstallTimeout {
+		log.Debug(ctx, "Waiting for the BMC to present the new certificate")
+		return ctx, nil, nil, nil
+	}
+
+	host, _ := ctx.Value(ctxkOOBHost{}).(string)
+	log.Info(ctx, "Issuing certificate", "notAfter", cert.NotAfter)
+	var csr []byte
+	csr, err = cc.GenerateCSR(ctx, host, []string{host})
+	if goerrors.Is(err, bmc.ErrNoCertificateService) {
+		status, err = oobCondition(oob, metav1.Condition{
+			Type:    metalv1alpha1.OOBConditionTypeCertificate,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.OOBConditionReasonNoCertificateService,
+			Message: err.Error(),
+		})
+		return ctx, nil, status, err
+	}
+	if err != nil {
+		status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot generate CSR: %w", err))
+		return ctx, nil, status, err
+	}
+
+	var certPEM []byte
+	certPEM, cert, err = oobIssueCertificate(csr, host, validity, caCert, caKey)
+	if err != nil {
+		status, err = oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonError, err)
+		return ctx, nil, status, err
+	}
+
+	err = cc.InstallCertificate(ctx, certPEM)
+	if err != nil {
+		status, err = oobErrorStatus(oob, oobErrorReason(err), fmt.Errorf("cannot install certificate: %w", err))
+		return ctx, nil, status, err
+	}
+
+	// The pinned certificate is kept until the BMC presents the issued one, since the BMC may take a
+	// while to install it.
+	pending := ""
+	if oob.Status.TLSFingerprint != "" {
+		pending = bmc.Fingerprint(cert)
+		log.Info(ctx, "Accepting issued certificate", "fingerprint", pending)
+	}
+	status, err = oobCertificateStatus(oob, cert.NotAfter, "", pending, metav1.Condition{
+		Type:    metalv1alpha1.OOBConditionTypeCertificate,
+		Status:  metav1.ConditionFalse,
+		Reason:  metalv1alpha1.OOBConditionReasonInstalling,
+		Message: "waiting for the BMC to present the issued certificate",
+	})
+	return ctx, nil, status, err
+}
+
+func (r *OOBReconciler) processMachine(ctx context.Context, oob *metalv1alpha1.OOB) (context.Context, *metalv1alpha1apply.OOBApplyConfiguration, *metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	var status *metalv1alpha1apply.OOBStatusApplyConfiguration
+	var err error
+
+	info, _ := ctx.Value(ctxkInfo{}).(bmc.Info)
+	if oob.Status.Type != metalv1alpha1.OOBTypeMachine || info.UUID == "" {
+		return ctx, nil, nil, nil
+	}
+
+	// Only BMCs that manage more than one system get a Machine per system, identified by its system ID.
+	systems := info.Systems
+	if len(systems) <= 1 {
+		systems = []bmc.SystemInfo{{UUID: info.UUID}}
+	}
+
+	for _, sys := range systems {
+		if sys.UUID == "" {
+			continue
+		}
+
+		var reason, msg string
+		reason, msg, err = r.applyMachine(ctx, oob, sys)
+		if err != nil {
+			return ctx, nil, nil, err
+		}
+		if reason != "" {
+			status, err = oobMachineStatus(oob, metav1.ConditionFalse, reason, msg)
+			return ctx, nil, status, err
+		}
+	}
+
+	status, err = oobMachineStatus(oob, metav1.ConditionTrue, metalv1alpha1.OOBConditionReasonOwned, "")
+	return ctx, nil, status, err
+}
+
+// applyMachine creates or adopts the Machine of a system. It returns the reason and message of a
+// conflict if the Machine cannot be owned by the OOB.
+func (r *OOBReconciler) applyMachine(ctx context.Context, oob *metalv1alpha1.OOB, sys bmc.SystemInfo) (string, string, error) {
+	ctx = log.WithValues(ctx, "uuid", sys.UUID)
+	if sys.ID != "" {
+		ctx = log.WithValues(ctx, "system", sys.ID)
+	}
+
+	var machineList metalv1alpha1.MachineList
+	err := r.List(ctx, &machineList, client.MatchingFields{MachineSpecUUID: sys.UUID})
+	if err != nil {
+		return "", "", fmt.Errorf("cannot list Machines: %w", err)
+	}
+
+	var machine metalv1alpha1.Machine
+	switch len(machineList.Items) {
+	case 0:
+		err = r.Get(ctx, client.ObjectKey{
+			Name: sys.UUID,
+		}, &machine)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", "", fmt.Errorf("cannot get Machine: %w", err)
+		}
+		if err == nil {
+			return metalv1alpha1.OOBConditionReasonNameConflict,
+				fmt.Sprintf("Machine %s already exists with UUID %s", machine.Name, machine.Spec.UUID), nil
+		}
+		machine = metalv1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: sys.UUID,
+			},
+		}
+	case 1:
+		machine = machineList.Items[0]
+	default:
+		return metalv1alpha1.OOBConditionReasonUUIDConflict,
+			fmt.Sprintf("%d Machines exist with UUID %s", len(machineList.Items), sys.UUID), nil
+	}
+	ctx = log.WithValues(ctx, "machine", machine.Name)
+
+	if machine.Spec.OOBRef.Name != "" && machine.Spec.OOBRef.Name != oob.Name {
+		return metalv1alpha1.OOBConditionReasonUUIDConflict,
+			fmt.Sprintf("Machine %s with UUID %s belongs to OOB %s", machine.Name, sys.UUID, machine.Spec.OOBRef.Name), nil
+	}
+	owner := metav1.GetControllerOf(&machine)
+	if owner != nil && owner.UID != oob.UID {
+		return metalv1alpha1.OOBConditionReasonUUIDConflict,
+			fmt.Sprintf("Machine %s with UUID %s is controlled by %s %s", machine.Name, sys.UUID, owner.Kind, owner.Name), nil
+	}
+
+	if owner == nil || machine.Spec.UUID != sys.UUID || machine.Spec.OOBRef.Name != oob.Name || machine.Spec.SystemID != sys.ID {
+		if machine.UID == "" {
+			log.Info(ctx, "Creating Machine")
+		} else {
+			log.Info(ctx, "Adopting Machine")
+		}
+		spec := metalv1alpha1apply.MachineSpec().
+			WithUUID(sys.UUID).
+			WithOOBRef(v1.LocalObjectReference{
+				Name: oob.Name,
+			})
+		if sys.ID != "" {
+			spec = spec.WithSystemID(sys.ID)
+		}
+		machineApply := metalv1alpha1apply.Machine(machine.Name, "").
+			WithOwnerReferences(metav1apply.OwnerReference().
+				WithAPIVersion(metalv1alpha1.GroupVersion.String()).
+				WithKind("OOB").
+				WithName(oob.Name).
+				WithUID(oob.UID).
+				WithController(true).
+				WithBlockOwnerDeletion(true)).
+			WithSpec(spec)
+		err = r.Patch(ctx, &machine, ssa.Apply(machineApply), client.FieldOwner(OOBFieldManager), client.ForceOwnership)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot apply Machine: %w", err)
+		}
+	}
+
+	return "", "", nil
+}
+
+func oobMachineStatus(oob *metalv1alpha1.OOB, condStatus metav1.ConditionStatus, reason, msg string) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(oob.Status.Conditions, metav1.Condition{
+		Type:    metalv1alpha1.OOBConditionTypeMachine,
+		Status:  condStatus,
+		Reason:  reason,
+		Message: msg,
+	})
+	if !mod {
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status)
+	status.Conditions = conds
+	return status, nil
+}
+
+func oobCondition(oob *metalv1alpha1.OOB, cond metav1.Condition) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(oob.Status.Conditions, cond)
+	if !mod {
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status)
+	status.Conditions = conds
+	return status, nil
+}
+
+// oobTLSTrust returns the TLS trust of the BMC of an OOB. The CAs of the ca policy are read from
+// the Secret named by the flags in the system namespace.
+func oobTLSTrust(ctx context.Context, c client.Client, namespace string, flags map[string]string, oob *metalv1alpha1.OOB) (bmc.TLSTrust, error) {
+	trust := bmc.TLSTrust{
+		Fingerprint:        oob.Status.TLSFingerprint,
+		PendingFingerprint: oob.Status.PendingTLSFingerprint,
+	}
+	if bmc.RedfishTLSPolicy(flags) != bmc.TLSPolicyCA {
+		return trust, nil
+	}
+
+	name := flags[bmc.FlagRedfishTLSCASecret]
+	if name == "" {
+		return bmc.TLSTrust{}, fmt.Errorf("%w: flag %s is required by the ca policy", bmc.ErrInvalidFlags, bmc.FlagRedfishTLSCASecret)
+	}
+	var secret v1.Secret
+	err := c.Get(ctx, client.ObjectKey{
+		Namespace: namespace,
+		Name:      name,
+	}, &secret)
+	if err != nil {
+		return bmc.TLSTrust{}, fmt.Errorf("cannot get CA Secret %s: %w", name, err)
+	}
+	trust.CA = secret.Data["ca.crt"]
+	if len(trust.CA) == 0 {
+		return bmc.TLSTrust{}, fmt.Errorf("CA Secret %s has no ca.crt", name)
+	}
+	return trust, nil
+}
+
+// oobCertificateCA returns the CA that issues the certificates of BMCs, read from a Secret of type
+// kubernetes.io/tls in the system namespace.
+func oobCertificateCA(ctx context.Context, c client.Client, namespace, name string) (*x509.Certificate, crypto.Signer, error) {
+	var secret v1.Secret
+	err := c.Get(ctx, client.ObjectKey{
+		Namespace: namespace,
+		Name:      name,
+	}, &secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get CA Secret %s: %w", name, err)
+	}
+
+	pair, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("CA Secret %s has no valid key pair: %w", name, err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse certificate of CA Secret %s: %w", name, err)
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok || !cert.IsCA {
+		return nil, nil, fmt.Errorf("CA Secret %s does not hold a CA", name)
+	}
+	return cert, key, nil
+}
+
+// oobCertificateIssuedBy returns whether a certificate of a BMC is issued by the CA and valid.
+func oobCertificateIssuedBy(cert, caCert *x509.Certificate) bool {
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return err == nil
+}
+
+// oobIssueCertificate issues a certificate for the key of a CSR generated by a BMC. Only the public
+// key is taken from the CSR, the name of the certificate is the host of the OOB.
+func oobIssueCertificate(csrPEM []byte, host string, validity time.Duration, caCert *x509.Certificate, caKey crypto.Signer) ([]byte, *x509.Certificate, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("BMC returned an invalid CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse CSR: %w", err)
+	}
+	err = csr.CheckSignature()
+	if err != nil {
+		return nil, nil, fmt.Errorf("CSR has an invalid signature: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate serial number: %w", err)
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if tmpl.NotAfter.After(caCert.NotAfter) {
+		tmpl.NotAfter = caCert.NotAfter
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		tmpl.IPAddresses = []net.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, csr.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot issue certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse issued certificate: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), cert, nil
+}
+
+// oobCertificateStatus sets the expiry of the certificate, the certificate condition, the pending
+// fingerprint and, if it is not empty, the pinned fingerprint. It returns nil if nothing changes.
+func oobCertificateStatus(oob *metalv1alpha1.OOB, notAfter time.Time, fingerprint, pending string, cond metav1.Condition) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(oob.Status.Conditions, cond)
+	if oob.Status.CertificateNotAfter == nil || !oob.Status.CertificateNotAfter.Time.Equal(notAfter) {
+		mod = true
+	}
+	if fingerprint != "" && fingerprint != oob.Status.TLSFingerprint {
+		mod = true
+	}
+	if pending != oob.Status.PendingTLSFingerprint {
+		mod = true
+	}
+	if !mod {
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status).
+		WithCertificateNotAfter(metav1.NewTime(notAfter))
+	if fingerprint != "" {
+		status = status.WithTLSFingerprint(fingerprint)
+	}
+	status.PendingTLSFingerprint = nil
+	if pending != "" {
+		status = status.WithPendingTLSFingerprint(pending)
+	}
+	status.Conditions = conds
+	return status, nil
+}
+
+func oobTLSFingerprintStatus(oob *metalv1alpha1.OOB, fingerprint string) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status)
+	status.TLSFingerprint = nil
+	status.PendingTLSFingerprint = nil
+	if fingerprint != "" {
+		status = status.WithTLSFingerprint(fingerprint)
+	}
+	return status, nil
+}
+
+func oobSSHHostKeyStatus(oob *metalv1alpha1.OOB, fingerprint string) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	return util.Ensure(applyst.Status).
+		WithSSHHostKeyFingerprint(fingerprint), nil
+}
+
+func oobFlagsStatus(oob *metalv1alpha1.OOB, flags map[string]string) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status)
+	status.Flags = nil
+	if len(flags) > 0 {
+		status = status.WithFlags(flags)
+	}
+	return status, nil
+}
+
+// oobResolvedFlags returns the flags the OOB controller resolved for an OOB. Before they are
+// resolved, only the flags of the OOB are known.
+func oobResolvedFlags(oob *metalv1alpha1.OOB) map[string]string {
+	if oob.Status.Flags != nil {
+		return oob.Status.Flags
+	}
+	return oob.Spec.Flags
+}
+
+// oobFlags returns the flags of the MAC DB entry or profile, overridden by the flags of the OOB.
+func oobFlags(a access, oob *metalv1alpha1.OOB) map[string]string {
+	flags := make(map[string]string, len(a.Flags)+len(oob.Spec.Flags))
+	maps.Copy(flags, a.Flags)
+	maps.Copy(flags, oob.Spec.Flags)
+	return flags
+}
+
+func oobErrorReason(err error) string {
+	switch {
+	case goerrors.Is(err, bmc.ErrUnreachable):
+		return metalv1alpha1.OOBConditionReasonUnreachable
+	case goerrors.Is(err, bmc.ErrAuthFailed):
+		return metalv1alpha1.OOBConditionReasonAuthFailed
+	case goerrors.Is(err, bmc.ErrUnsupportedProtocol):
+		return metalv1alpha1.OOBConditionReasonUnsupportedProtocol
+	case goerrors.Is(err, bmc.ErrInvalidFlags):
+		return metalv1alpha1.OOBConditionReasonBadFlags
+	case goerrors.Is(err, bmc.ErrUntrustedCertificate):
+		return metalv1alpha1.OOBConditionReasonUntrustedCertificate
+	case goerrors.Is(err, bmc.ErrCertificateChanged):
+		return metalv1alpha1.OOBConditionReasonCertificateChanged
+	case goerrors.Is(err, bmc.ErrHostKeyChanged):
+		return metalv1alpha1.OOBConditionReasonHostKeyChanged
+	default:
+		return metalv1alpha1.OOBConditionReasonError
+	}
+}
+
+func oobStatus(oob *metalv1alpha1.OOB, state metalv1alpha1.OOBState, cond metav1.Condition) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	conds, mod := ssa.SetCondition(oob.Status.Conditions, cond)
+	if oob.Status.State == state && !mod {
+		return nil, nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status).
+		WithState(state)
+	status.Conditions = conds
+	return status, nil
+}
+
+// oobErrorStatus returns the original error if the error state is already
+// recorded, so that the caller does not proceed to the next phase. Errors are
+// not recorded while the BMC is restarting, since it is expected to be unavailable.
+func oobErrorStatus(oob *metalv1alpha1.OOB, reason string, err error) (*metalv1alpha1apply.OOBStatusApplyConfiguration, error) {
+	if oobRestarting(oob) {
+		return nil, nil
+	}
+
+	state := metalv1alpha1.OOBStateError
+	conds, mod := ssa.SetErrorConditionWithReason(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady, reason, err)
+	if oob.Status.State == state && !mod {
+		return nil, err
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractOOBStatus(oob, OOBFieldManager)
+	if err != nil {
+		return nil, err
+	}
+	status := util.Ensure(applyst.Status).
+		WithState(state)
+	status.Conditions = conds
+	return status, nil
+}
+
+func oobRestarting(oob *metalv1alpha1.OOB) bool {
+	cond, ok := ssa.GetCondition(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady)
+	return ok && cond.Reason == metalv1alpha1.OOBConditionReasonRestarting && time.Since(cond.LastTransitionTime.Time) < OOBRestartTimeout
+}
+
+// oobRestartSettling returns whether a BMC was asked to restart too recently to have gone down, so
+// that a successful connection would not prove that it is back.
+func oobRestartSettling(oob *metalv1alpha1.OOB) bool {
+	cond, ok := ssa.GetCondition(oob.Status.Conditions, metalv1alpha1.OOBConditionTypeReady)
+	return ok && cond.Reason == metalv1alpha1.OOBConditionReasonRestarting && time.Since(cond.LastTransitionTime.Time) < OOBRestartMinDelay
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OOBReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+	r.credentials = newStoreCredentialProvider(r.store)
+	r.recorder = mgr.GetEventRecorderFor(OOBEventSource)
+
+	c, err := cru.CreateController(mgr, &metalv1alpha1.OOB{}, r)
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(source.Kind(mgr.GetCache(), &ipamv1alpha1.IP{}), r.enqueueOOBFromIP())
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(source.Kind(mgr.GetCache(), &metalv1alpha1.Machine{}), handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &metalv1alpha1.OOB{}, handler.OnlyControllerOwner()))
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(source.Kind(mgr.GetCache(), &metalv1alpha1.OOBProfile{}), r.enqueueOOBsFromOOBProfile())
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(source.Kind(mgr.GetCache(), &metalv1alpha1.OOBSecret{}), r.enqueueOOBFromOOBSecret())
+	if err != nil {
+		return err
+	}
+
+	if r.macDBConfigMap != "" {
+		err = c.Watch(source.Kind(mgr.GetCache(), &v1.ConfigMap{}), r.enqueueOOBsFromMacDB(), r.isMacDB(r.macDBConfigMap))
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.macDBSecret != "" {
+		err = c.Watch(source.Kind(mgr.GetCache(), &v1.Secret{}), r.enqueueOOBsFromMacDB(), r.isMacDB(r.macDBSecret))
+		if err != nil {
+			return err
+		}
+	}
+
+	return mgr.Add(c)
+}
+
+// enqueueOOBsFromOOBProfile enqueues all OOBs matching any prefix of a profile. On updates, this is
+// called for both the old and the new profile.
+func (r *OOBReconciler) enqueueOOBsFromOOBProfile() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		profile := obj.(*metalv1alpha1.OOBProfile)
+
+		oobList := metalv1alpha1.OOBList{}
+		err := r.List(ctx, &oobList)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot list OOBs: %w", err))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, o := range oobList.Items {
+			if o.DeletionTimestamp != nil {
+				continue
+			}
+
+			if !slices.ContainsFunc(profile.Spec.MACPrefixes, func(prefix metalv1alpha1.MACPrefix) bool {
+				return strings.HasPrefix(o.Spec.MACAddress, string(prefix))
+			}) {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name: o.Name,
+			}})
+		}
+		return reqs
+	})
+}
+
+// enqueueOOBFromOOBSecret enqueues the OOB using an OOBSecret, so that rotated credentials are used
+// and the previous user is deleted.
+func (r *OOBReconciler) enqueueOOBFromOOBSecret() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		secret := obj.(*metalv1alpha1.OOBSecret)
+
+		oobList := metalv1alpha1.OOBList{}
+		err := r.List(ctx, &oobList, client.MatchingFields{OOBSpecMACAddress: secret.Spec.MACAddress})
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot list OOBs: %w", err))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, o := range oobList.Items {
+			if o.DeletionTimestamp != nil || o.Spec.SecretRef == nil || o.Spec.SecretRef.Name != secret.Name {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name: o.Name,
+			}})
+		}
+		return reqs
+	})
+}
+
+func (r *OOBReconciler) isMacDB(name string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == r.systemNamespace && obj.GetName() == name
+	})
+}
+
+// enqueueOOBsFromMacDB reloads the MAC DB and enqueues all OOBs whose matching entry has changed. An
+// invalid MAC DB is rejected and the previous one is kept.
+func (r *OOBReconciler) enqueueOOBsFromMacDB() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		db, err := r.readMacDB(ctx)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot reload MAC DB, keeping the previous one: %w", err))
+			return nil
+		}
+		old := r.macDB.Swap(&db)
+		log.Info(ctx, "Reloaded MAC DB", "entries", len(db))
+
+		oobList := metalv1alpha1.OOBList{}
+		err = r.List(ctx, &oobList)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot list OOBs: %w", err))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, o := range oobList.Items {
+			if o.DeletionTimestamp != nil {
+				continue
+			}
+
+			oa, _ := old.Get(o.Spec.MACAddress)
+			na, _ := db.Get(o.Spec.MACAddress)
+			if reflect.DeepEqual(oa, na) {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name: o.Name,
+			}})
+		}
+		return reqs
+	})
+}
+
+func (r *OOBReconciler) enqueueOOBFromIP() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		ip := obj.(*ipamv1alpha1.IP)
+
+		if ip.Namespace != OOBTemporaryNamespaceHack {
+			return nil
+		}
+		if !r.ipLabelSelector.Matches(labels.Set(ip.Labels)) {
+			return nil
+		}
+
+		mac, ok := ip.Labels[OOBIPMacLabel]
+		if !ok || !r.macRegex.MatchString(mac) {
+			log.Error(ctx, fmt.Errorf("invalid MAC address: %s", mac))
+			return nil
+		}
+
+		oobList := metalv1alpha1.OOBList{}
+		err := r.List(ctx, &oobList, client.MatchingFields{OOBSpecMACAddress: mac})
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot list OOBs: %w", err))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, o := range oobList.Items {
+			if o.DeletionTimestamp != nil {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name: o.Name,
+			}})
+		}
+
+		if len(oobList.Items) == 0 && ip.Status.State == ipamv1alpha1.CFinishedIPState && ip.Status.Reserved != nil {
+			oob := metalv1alpha1.OOB{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: mac,
+				},
+			}
+			apply := metalv1alpha1apply.OOB(oob.Name, oob.Namespace).
+				WithFinalizers(OOBFinalizer).
+				WithSpec(metalv1alpha1apply.OOBSpec().
+					WithMACAddress(mac))
+			err = r.Patch(ctx, &oob, ssa.Apply(apply), client.FieldOwner(OOBFieldManager), client.ForceOwnership)
+			if err != nil {
+				log.Error(ctx, fmt.Errorf("cannot apply OOB: %w", err))
+			}
+		}
+
+		return reqs
+	})
+}
+
+// resolveAccess returns the access settings for a MAC address. A matching OOBProfile takes precedence
+// over the MAC DB. The default credentials of a profile are not resolved, since they are only needed
+// to take over a BMC.
+func (r *OOBReconciler) resolveAccess(ctx context.Context, mac string) (access, *metalv1alpha1.OOBProfile, error) {
+	var profileList metalv1alpha1.OOBProfileList
+	err := r.List(ctx, &profileList)
+	if err != nil {
+		return access{}, nil, fmt.Errorf("cannot list OOBProfiles: %w", err)
+	}
+
+	profile := matchOOBProfile(profileList.Items, mac)
+	if profile == nil {
+		a, _ := r.macDB.Load().Get(mac)
+		return a, nil, nil
+	}
+
+	a := access{
+		Ignore: profile.Spec.Ignore,
+		Flags:  profile.Spec.Flags,
+	}
+	if profile.Spec.Protocol != nil {
+		a.Protocol = *profile.Spec.Protocol
+	}
+	return a, profile, nil
+}
+
+func loadMacDB(dbFile string) (util.PrefixMap[access], error) {
+	if dbFile == "" {
+		return make(util.PrefixMap[access]), nil
+	}
+
+	data, err := os.ReadFile(dbFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", dbFile, err)
+	}
+
+	var db util.PrefixMap[access]
+	db, err = parseMacDB(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", dbFile, err)
+	}
+	return db, nil
+}
+
+// readMacDB reads the MAC DB from the ConfigMap or Secret in the system namespace. A missing object
+// results in an empty MAC DB.
+func (r *OOBReconciler) readMacDB(ctx context.Context) (util.PrefixMap[access], error) {
+	var data []byte
+	switch {
+	case r.macDBConfigMap != "":
+		var cm v1.ConfigMap
+		err := r.Get(ctx, client.ObjectKey{
+			Namespace: r.systemNamespace,
+			Name:      r.macDBConfigMap,
+		}, &cm)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("cannot get ConfigMap %s: %w", r.macDBConfigMap, err)
+		}
+		data = []byte(cm.Data[OOBMacDBKey])
+	case r.macDBSecret != "":
+		var secret v1.Secret
+		err := r.Get(ctx, client.ObjectKey{
+			Namespace: r.systemNamespace,
+			Name:      r.macDBSecret,
+		}, &secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("cannot get Secret %s: %w", r.macDBSecret, err)
+		}
+		data = secret.Data[OOBMacDBKey]
+	}
+
+	return parseMacDB(data)
+}
+
+func parseMacDB(data []byte) (util.PrefixMap[access], error) {
+	var dbf struct {
+		MACs []struct {
+			Prefix string `yaml:"prefix"`
+			access `yaml:",inline"`
+		} `yaml:"macs"`
+	}
+	err := yaml.Unmarshal(data, &dbf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal MAC DB: %w", err)
+	}
+
+	prefixRegex := regexp.MustCompile(OOBMacPrefixRegex)
+	db := make(util.PrefixMap[access], len(dbf.MACs))
+	for _, m := range dbf.MACs {
+		if !prefixRegex.MatchString(m.Prefix) {
+			return nil, fmt.Errorf("invalid MAC prefix: %s", m.Prefix)
+		}
+		_, ok := db[m.Prefix]
+		if ok {
+			return nil, fmt.Errorf("duplicate MAC prefix: %s", m.Prefix)
+		}
+		switch m.Protocol.Name {
+		case "", metalv1alpha1.ProtocolNameRedfish, metalv1alpha1.ProtocolNameIPMI, metalv1alpha1.ProtocolNameSSH:
+		default:
+			return nil, fmt.Errorf("invalid protocol for MAC prefix %s: %s", m.Prefix, m.Protocol.Name)
+		}
+		if m.Protocol.Port < 0 || m.Protocol.Port > 65535 {
+			return nil, fmt.Errorf("invalid port for MAC prefix %s: %d", m.Prefix, m.Protocol.Port)
+		}
+		err = bmc.ValidateFlags(m.Flags)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flags for MAC prefix %s: %w", m.Prefix, err)
+		}
 		db[m.Prefix] = m.access
 	}
 	return db, nil
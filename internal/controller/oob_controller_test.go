@@ -4,17 +4,27 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	ipamv1alpha1 "github.com/ironcore-dev/ipam/api/ipam/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	. "sigs.k8s.io/controller-runtime/pkg/envtest/komega"
 
 	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/bmc"
 	"github.com/ironcore-dev/metal/internal/ssa"
 )
 
@@ -56,7 +66,7 @@ var _ = Describe("OOB Controller", func() {
 			HaveField("Spec.MACAddress", "aabbccddeeff"),
 			HaveField("Spec.EndpointRef.Name", ip.Name),
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
 		))
 
 		By("Expecting finalizer to be correct on the IP")
@@ -130,10 +140,57 @@ var _ = Describe("OOB Controller", func() {
 		By("Expecting OOB not to be ignored")
 		Eventually(Object(oob)).Should(SatisfyAll(
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
 		))
 	})
 
+	It("should set the OOB to ignored if its OOBProfile or MAC DB entry says so", func(ctx SpecContext) {
+		By("Creating an OOBProfile that ignores the OOB")
+		profile := &metalv1alpha1.OOBProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+			},
+			Spec: metalv1alpha1.OOBProfileSpec{
+				MACPrefixes: []metalv1alpha1.MACPrefix{"aabbcc"},
+				Ignore:      true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, profile))).To(Succeed())
+			Eventually(Get(profile)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		oob := createOOBWithEndpoint(ctx, "aabbccddeeff", "1.2.3.4")
+
+		By("Expecting OOB to be ignored")
+		Eventually(Object(oob)).Should(SatisfyAll(
+			HaveField("Status.State", metalv1alpha1.OOBStateIgnored),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonIgnored)),
+		))
+
+		By("Deleting the OOBProfile")
+		Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+
+		By("Expecting OOB not to be ignored")
+		Eventually(Object(oob)).Should(SatisfyAll(
+			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
+		))
+
+		By("Ignoring the OOB in the MAC DB")
+		r := &OOBReconciler{
+			Client: k8sClient,
+		}
+		db, err := parseMacDB([]byte("macs:\n- prefix: aabbcc\n  ignore: true\n"))
+		Expect(err).NotTo(HaveOccurred())
+		r.macDB.Store(&db)
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(oob), oob)).To(Succeed())
+		_, _, status, err := r.processIgnore(ctx, oob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(HaveField("State", PointTo(Equal(metalv1alpha1.OOBStateIgnored))))
+	})
+
 	It("should handle an unavailable endpoint", func(ctx SpecContext) {
 		By("Creating an IP")
 		ip := &ipamv1alpha1.IP{
@@ -176,7 +233,7 @@ var _ = Describe("OOB Controller", func() {
 			HaveField("Spec.MACAddress", "aabbccddeeff"),
 			HaveField("Spec.EndpointRef.Name", ip.Name),
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
 		))
 
 		By("Deleting the IP")
@@ -211,7 +268,7 @@ var _ = Describe("OOB Controller", func() {
 		Eventually(Object(oob)).Should(SatisfyAll(
 			HaveField("Spec.EndpointRef.Name", ip.Name),
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
 		))
 	})
 
@@ -257,7 +314,7 @@ var _ = Describe("OOB Controller", func() {
 			HaveField("Spec.MACAddress", "aabbccddeeff"),
 			HaveField("Spec.EndpointRef.Name", ip.Name),
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
 		))
 
 		By("Setting an incorrect MAC on the IP")
@@ -268,7 +325,7 @@ var _ = Describe("OOB Controller", func() {
 		By("Expecting the OOB to be in an error state")
 		Eventually(Object(oob)).Should(SatisfyAll(
 			HaveField("Status.State", metalv1alpha1.OOBStateError),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonError)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonBadEndpoint)),
 		))
 
 		By("Restoring the MAC on the IP")
@@ -279,7 +336,7 @@ var _ = Describe("OOB Controller", func() {
 		By("Expecting the OOB to recover")
 		Eventually(Object(oob)).Should(SatisfyAll(
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
 		))
 
 		By("Setting a failed state on the IP")
@@ -290,7 +347,7 @@ var _ = Describe("OOB Controller", func() {
 		By("Expecting the OOB to be in an error state")
 		Eventually(Object(oob)).Should(SatisfyAll(
 			HaveField("Status.State", metalv1alpha1.OOBStateError),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonError)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonBadEndpoint)),
 		))
 
 		By("Restoring the state on the IP")
@@ -301,11 +358,589 @@ var _ = Describe("OOB Controller", func() {
 		By("Expecting the OOB to recover")
 		Eventually(Object(oob)).Should(SatisfyAll(
 			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
-			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonInProgress)),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
+		))
+	})
+
+	It("should fail to take over a BMC without default credentials", func(ctx SpecContext) {
+		By("Creating an IP")
+		ip := &ipamv1alpha1.IP{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+				Namespace:    OOBTemporaryNamespaceHack,
+				Labels: map[string]string{
+					OOBIPMacLabel: "aabbccddeeff",
+					"test":        "test",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, ip)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, ip)).To(Succeed())
+			Eventually(Get(ip)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Patching IP reservation and state")
+		ipAddr, err := ipamv1alpha1.IPAddrFromString("1.2.3.4")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(UpdateStatus(ip, func() {
+			ip.Status.Reserved = ipAddr
+			ip.Status.State = ipamv1alpha1.CFinishedIPState
+		})).Should(Succeed())
+
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "aabbccddeeff",
+			},
+		}
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, oob)).To(Succeed())
+			Eventually(Get(oob)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Expecting the OOB to have no protocol")
+		Eventually(Object(oob)).Should(SatisfyAll(
+			HaveField("Spec.EndpointRef.Name", ip.Name),
+			HaveField("Status.State", metalv1alpha1.OOBStateUnready),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonNoProtocol)),
+		))
+
+		By("Setting a protocol on the OOB")
+		Eventually(Update(oob, func() {
+			oob.Spec.Protocol = &metalv1alpha1.Protocol{
+				Name: metalv1alpha1.ProtocolNameRedfish,
+				Port: 443,
+			}
+		})).Should(Succeed())
+
+		By("Expecting the OOB to be in an error state")
+		Eventually(Object(oob)).Should(SatisfyAll(
+			HaveField("Spec.SecretRef", BeNil()),
+			HaveField("Status.State", metalv1alpha1.OOBStateError),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonError)),
+		))
+	})
+
+	It("should reject an unknown protocol", func(ctx SpecContext) {
+		oob := createOOBWithEndpoint(ctx, "aabbccddeeff", "1.2.3.4")
+
+		By("Setting an unknown protocol on the OOB")
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(oob), oob)).To(Succeed())
+		oob.Spec.Protocol = &metalv1alpha1.Protocol{
+			Name: metalv1alpha1.ProtocolName("Telnet"),
+			Port: 23,
+		}
+		Expect(k8sClient.Update(ctx, oob)).To(Satisfy(errors.IsInvalid))
+	})
+
+	It("should take over an SSH device", func(ctx SpecContext) {
+		dev, oob := createSSHOOB(ctx, "a1b2c3ddeeff")
+
+		By("Expecting the OOB to be ready")
+		Expect(oob.Spec.SecretRef).NotTo(BeNil())
+		Expect(oob.Status.SSHHostKeyFingerprint).To(Equal(dev.HostKey()))
+		Expect(oob.Status.Type).To(Equal(metalv1alpha1.OOBTypeSwitch))
+		Expect(oob.Status.Manufacturer).To(Equal("Edgecore"))
+
+		By("Expecting a new user on the device")
+		Expect(dev.UserNames()).To(ContainElement(HavePrefix("metal-")))
+	})
+
+	It("should retry an unreachable BMC until it comes back", func(ctx SpecContext) {
+		dev, oob := createSSHOOB(ctx, "a1b2c5ddeeff")
+
+		By("Stopping the device")
+		Expect(dev.Close()).To(Succeed())
+		Eventually(Update(oob, func() {
+			oob.Annotations = map[string]string{
+				"test": "unreachable",
+			}
+		})).Should(Succeed())
+
+		By("Expecting the OOB to be unreachable")
+		Eventually(Object(oob)).Should(SatisfyAll(
+			HaveField("Status.State", metalv1alpha1.OOBStateError),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonUnreachable)),
+		))
+
+		By("Starting the device again")
+		Expect(dev.Start()).To(Succeed())
+
+		By("Expecting the OOB to be ready without another event")
+		Eventually(Object(oob)).WithTimeout(time.Minute).Should(SatisfyAll(
+			HaveField("Status.State", metalv1alpha1.OOBStateReady),
+			WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonReady)),
+		))
+	})
+
+	It("should resolve the flags of the OOBProfile and the OOB", func(ctx SpecContext) {
+		By("Creating an OOBProfile")
+		profile := &metalv1alpha1.OOBProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+			},
+			Spec: metalv1alpha1.OOBProfileSpec{
+				MACPrefixes: []metalv1alpha1.MACPrefix{"aabbcc"},
+				Protocol: &metalv1alpha1.Protocol{
+					Name: metalv1alpha1.ProtocolNameRedfish,
+					Port: 443,
+				},
+				Flags: map[string]string{
+					bmc.FlagRedfishTimeout:   "5s",
+					bmc.FlagRedfishTLSPolicy: bmc.TLSPolicySystem,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+			Eventually(Get(profile)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		oob := createOOBWithEndpoint(ctx, "aabbccddeeff", "1.2.3.4")
+
+		By("Overriding a flag on the OOB")
+		Eventually(Update(oob, func() {
+			oob.Spec.Flags = map[string]string{
+				bmc.FlagRedfishTimeout: "10s",
+			}
+		})).Should(Succeed())
+
+		By("Expecting the resolved flags in the status")
+		Eventually(Object(oob)).Should(HaveField("Status.Flags", Equal(map[string]string{
+			bmc.FlagRedfishTimeout:   "10s",
+			bmc.FlagRedfishTLSPolicy: bmc.TLSPolicySystem,
+		})))
+		Expect(oobResolvedFlags(oob)).To(Equal(oob.Status.Flags))
+	})
+
+	It("should create and adopt the Machines of the systems of an OOB", func(ctx SpecContext) {
+		r := &OOBReconciler{
+			Client: mgrClient,
+		}
+
+		By("Creating an OOB")
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "a1b2c3000001",
+			},
+			Spec: metalv1alpha1.OOBSpec{
+				MACAddress: "a1b2c3000001",
+			},
+		}
+		Expect(k8sClient.Create(ctx, oob)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, oob)).To(Succeed())
+			Eventually(Get(oob)).Should(Satisfy(errors.IsNotFound))
+		})
+		oob.Status.Type = metalv1alpha1.OOBTypeMachine
+		processMachine := func(info bmc.Info) (string, error) {
+			_, _, status, err := r.processMachine(context.WithValue(ctx, ctxkInfo{}, info), oob)
+			if err != nil {
+				return "", err
+			}
+			if status == nil {
+				return "", fmt.Errorf("no status was applied")
+			}
+			cond, ok := ssa.GetCondition(status.Conditions, metalv1alpha1.OOBConditionTypeMachine)
+			if !ok {
+				return "", fmt.Errorf("no condition of type %s was applied", metalv1alpha1.OOBConditionTypeMachine)
+			}
+			return cond.Reason, nil
+		}
+		ownedBy := func(oob *metalv1alpha1.OOB) OmegaMatcher {
+			return HaveField("OwnerReferences", ConsistOf(SatisfyAll(
+				HaveField("Kind", "OOB"),
+				HaveField("Name", oob.Name),
+				HaveField("UID", oob.UID),
+				HaveField("Controller", PointTo(BeTrue())),
+			)))
+		}
+
+		By("Creating a Machine named by the UUID of the only system")
+		Expect(processMachine(bmc.Info{
+			UUID: "11111111-2222-3333-4444-000000000001",
+		})).To(Equal(metalv1alpha1.OOBConditionReasonOwned))
+		machine := &metalv1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "11111111-2222-3333-4444-000000000001",
+			},
+		}
+		deleteMachineOnCleanup(machine)
+		Eventually(Object(machine)).Should(SatisfyAll(
+			HaveField("Spec.UUID", "11111111-2222-3333-4444-000000000001"),
+			HaveField("Spec.OOBRef.Name", oob.Name),
+			HaveField("Spec.SystemID", ""),
+			ownedBy(oob),
+		))
+
+		By("Creating a Machine per system of a BMC with more than one system")
+		Expect(processMachine(bmc.Info{
+			UUID: "11111111-2222-3333-4444-000000000002",
+			Systems: []bmc.SystemInfo{
+				{ID: "1", UUID: "11111111-2222-3333-4444-000000000002"},
+				{ID: "2", UUID: "11111111-2222-3333-4444-000000000003"},
+			},
+		})).To(Equal(metalv1alpha1.OOBConditionReasonOwned))
+		for i, uuid := range []string{"11111111-2222-3333-4444-000000000002", "11111111-2222-3333-4444-000000000003"} {
+			machine := &metalv1alpha1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: uuid,
+				},
+			}
+			deleteMachineOnCleanup(machine)
+			Eventually(Object(machine)).Should(SatisfyAll(
+				HaveField("Spec.UUID", uuid),
+				HaveField("Spec.OOBRef.Name", oob.Name),
+				HaveField("Spec.SystemID", fmt.Sprint(i+1)),
+				ownedBy(oob),
+			))
+		}
+
+		By("Adopting a Machine that was created by hand")
+		machine = createMachine(ctx, "", "11111111-2222-3333-4444-000000000004", oob.Name)
+		Expect(processMachine(bmc.Info{
+			UUID: "11111111-2222-3333-4444-000000000004",
+		})).To(Equal(metalv1alpha1.OOBConditionReasonOwned))
+		Eventually(Object(machine)).Should(ownedBy(oob))
+		Expect(Get(&metalv1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "11111111-2222-3333-4444-000000000004",
+			},
+		})()).To(Satisfy(errors.IsNotFound))
+
+		By("Reporting a Machine with the name of the UUID but another UUID")
+		machine = createMachine(ctx, "11111111-2222-3333-4444-000000000005", "11111111-2222-3333-4444-000000000006", "a1b2c3000002")
+		Expect(processMachine(bmc.Info{
+			UUID: "11111111-2222-3333-4444-000000000005",
+		})).To(Equal(metalv1alpha1.OOBConditionReasonNameConflict))
+		Consistently(Object(machine)).Should(HaveField("OwnerReferences", BeEmpty()))
+
+		By("Reporting a Machine with the UUID that belongs to another OOB")
+		machine = createMachine(ctx, "", "11111111-2222-3333-4444-000000000007", "a1b2c3000002")
+		Expect(processMachine(bmc.Info{
+			UUID: "11111111-2222-3333-4444-000000000007",
+		})).To(Equal(metalv1alpha1.OOBConditionReasonUUIDConflict))
+		Consistently(Object(machine)).Should(SatisfyAll(
+			HaveField("Spec.OOBRef.Name", "a1b2c3000002"),
+			HaveField("OwnerReferences", BeEmpty()),
+		))
+
+		By("Reporting more than one Machine with the UUID")
+		createMachine(ctx, "", "11111111-2222-3333-4444-000000000008", "a1b2c3000002")
+		createMachine(ctx, "", "11111111-2222-3333-4444-000000000008", "a1b2c3000002")
+		Expect(processMachine(bmc.Info{
+			UUID: "11111111-2222-3333-4444-000000000008",
+		})).To(Equal(metalv1alpha1.OOBConditionReasonUUIDConflict))
+	})
+
+	It("should restart the BMC and wait for it to come back", func(ctx SpecContext) {
+		recorder := record.NewFakeRecorder(8)
+		r := &OOBReconciler{
+			Client:         k8sClient,
+			resyncInterval: time.Hour,
+			backoff:        workqueue.NewItemExponentialFailureRateLimiter(OOBBackoffBase, time.Hour),
+			recorder:       recorder,
+		}
+		b := &fakeBMC{}
+
+		By("Creating an OOB")
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "a1b2c3000004",
+			},
+			Spec: metalv1alpha1.OOBSpec{
+				MACAddress: "a1b2c3000004",
+			},
+		}
+		Expect(k8sClient.Create(ctx, oob)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, oob)).To(Succeed())
+			Eventually(Get(oob)).Should(Satisfy(errors.IsNotFound))
+		})
+		processOperation := func(b bmc.BMC, op string) (context.Context, *metalv1alpha1apply.OOBStatusApplyConfiguration) {
+			Eventually(Update(oob, func() {
+				oob.Annotations = map[string]string{
+					metalv1alpha1.OOBOperationKeyName: op,
+				}
+			})).Should(Succeed())
+			bctx, _, status, err := r.processOperation(context.WithValue(ctx, ctxkBMC{}, b), oob)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(Object(oob)).Should(HaveField("Annotations", Not(HaveKey(metalv1alpha1.OOBOperationKeyName))))
+			return bctx, status
+		}
+
+		operationCondition := func(reason string) OmegaMatcher {
+			return HaveField("Conditions", ContainElement(SatisfyAll(
+				HaveField("Type", metalv1alpha1.OOBConditionTypeOperation),
+				HaveField("Reason", reason),
+			)))
+		}
+
+		By("Rejecting the operation if the BMC cannot be restarted")
+		bctx, status := processOperation(struct{ bmc.BMC }{b}, metalv1alpha1.OOBOperationRestart)
+		Expect(bctx.Value(ctxkOperation{})).To(BeNil())
+		Expect(status).To(operationCondition(metalv1alpha1.OOBConditionReasonUnsupported))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning OperationUnsupported")))
+		Expect(b.Calls()).To(BeEmpty())
+
+		By("Rejecting an unknown operation")
+		bctx, status = processOperation(b, "Explode")
+		Expect(bctx.Value(ctxkOperation{})).To(BeNil())
+		Expect(status).To(operationCondition(metalv1alpha1.OOBConditionReasonUnsupported))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Operation Explode is unknown")))
+		Expect(b.Calls()).To(BeEmpty())
+
+		By("Restarting the BMC")
+		bctx, status = processOperation(b, metalv1alpha1.OOBOperationRestart)
+		Expect(bctx.Value(ctxkOperation{})).To(Equal(metalv1alpha1.OOBOperationRestart))
+		Expect(b.Calls()).To(Equal([]string{"ResetManager"}))
+		Expect(status).To(SatisfyAll(
+			HaveField("State", PointTo(Equal(metalv1alpha1.OOBStateUnready))),
+			HaveField("Conditions", ContainElement(SatisfyAll(
+				HaveField("Type", metalv1alpha1.OOBConditionTypeReady),
+				HaveField("Reason", metalv1alpha1.OOBConditionReasonRestarting),
+			))),
+			operationCondition(metalv1alpha1.OOBConditionReasonCompleted),
 		))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal OperationCompleted")))
+		oob.Status.State = *status.State
+		oob.Status.Conditions = status.Conditions
+
+		By("Giving the BMC time to go down")
+		Expect(oobRestartSettling(oob)).To(BeTrue())
+		setRestartTime := func(t time.Time) {
+			for i := range oob.Status.Conditions {
+				if oob.Status.Conditions[i].Type == metalv1alpha1.OOBConditionTypeReady {
+					oob.Status.Conditions[i].LastTransitionTime = metav1.NewTime(t)
+				}
+			}
+		}
+		setRestartTime(time.Now().Add(-OOBRestartMinDelay))
+		Expect(oobRestartSettling(oob)).To(BeFalse())
+
+		By("Waiting for the BMC to come back")
+		Expect(oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonError, bmc.ErrUnreachable)).To(BeNil())
+		Expect(r.resync(ctx, oob, ctrl.Result{}, nil)).To(HaveField("RequeueAfter", OOBRestartCheckInterval))
+
+		By("Recording errors once the BMC did not come back in time")
+		setRestartTime(time.Now().Add(-OOBRestartTimeout))
+		Expect(oobErrorStatus(oob, metalv1alpha1.OOBConditionReasonError, bmc.ErrUnreachable)).To(
+			HaveField("State", PointTo(Equal(metalv1alpha1.OOBStateError))),
+		)
+	})
+
+	It("should keep the pinned certificate until the BMC presents the issued one", func(ctx SpecContext) {
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "aabbccddeeff",
+			},
+			Status: metalv1alpha1.OOBStatus{
+				TLSFingerprint: "old",
+			},
+		}
+		notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+		cond := func(reason string) metav1.Condition {
+			return metav1.Condition{
+				Type:   metalv1alpha1.OOBConditionTypeCertificate,
+				Status: metav1.ConditionFalse,
+				Reason: reason,
+			}
+		}
+		apply := func(status *metalv1alpha1apply.OOBStatusApplyConfiguration) {
+			if status.TLSFingerprint != nil {
+				oob.Status.TLSFingerprint = *status.TLSFingerprint
+			}
+			oob.Status.PendingTLSFingerprint = ptr.Deref(status.PendingTLSFingerprint, "")
+			oob.Status.CertificateNotAfter = status.CertificateNotAfter
+			oob.Status.Conditions = status.Conditions
+		}
+
+		By("Accepting the issued certificate besides the pinned one")
+		status, err := oobCertificateStatus(oob, notAfter, "", "new", cond(metalv1alpha1.OOBConditionReasonInstalling))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(HaveField("PendingTLSFingerprint", PointTo(Equal("new"))))
+		apply(status)
+		Expect(oob.Status.TLSFingerprint).To(Equal("old"))
+		trust, err := oobTLSTrust(ctx, nil, "", nil, oob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trust).To(Equal(bmc.TLSTrust{Fingerprint: "old", PendingFingerprint: "new"}))
+
+		By("Pinning the issued certificate once the BMC presents it")
+		status, err = oobCertificateStatus(oob, notAfter, "new", "", cond(metalv1alpha1.OOBConditionReasonIssued))
+		Expect(err).NotTo(HaveOccurred())
+		apply(status)
+		Expect(oob.Status.TLSFingerprint).To(Equal("new"))
+		Expect(oob.Status.PendingTLSFingerprint).To(BeEmpty())
+	})
+
+	It("should back off failed reconciliations until one succeeds", func(ctx SpecContext) {
+		r := &OOBReconciler{
+			resyncInterval: time.Minute,
+			backoff:        workqueue.NewItemExponentialFailureRateLimiter(OOBBackoffBase, time.Minute),
+		}
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "aabbccddeeff",
+			},
+		}
+		reconcile := func(state metalv1alpha1.OOBState, err error) time.Duration {
+			oob.Status.State = state
+			res, rerr := r.resync(ctx, oob, ctrl.Result{}, err)
+			Expect(rerr).NotTo(HaveOccurred())
+			return res.RequeueAfter
+		}
+		bmcErr := fmt.Errorf("cannot connect: %w", bmc.ErrUnreachable)
+
+		By("Doubling the delay of every failed attempt up to the resync interval")
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(5 * time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(10 * time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(20 * time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(40 * time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(time.Minute))
+
+		By("Resetting the delay after a successful attempt")
+		Expect(reconcile(metalv1alpha1.OOBStateUnready, nil)).To(BeNumerically("~", 63*time.Second, 3*time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateUnready, nil)).To(BeNumerically("~", 63*time.Second, 3*time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(5 * time.Second))
+
+		By("Not counting reconciliations without errors")
+		Expect(reconcile(metalv1alpha1.OOBStateReady, nil)).To(BeNumerically("~", 63*time.Second, 3*time.Second))
+		Expect(reconcile(metalv1alpha1.OOBStateError, bmcErr)).To(Equal(5 * time.Second))
 	})
 })
 
+// createOOBWithEndpoint creates an IP with an address for a MAC address and returns the OOB the OOB
+// controller creates for it.
+func createOOBWithEndpoint(ctx SpecContext, mac, addr string) *metalv1alpha1.OOB {
+	By("Creating an IP")
+	ip := &ipamv1alpha1.IP{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-",
+			Namespace:    OOBTemporaryNamespaceHack,
+			Labels: map[string]string{
+				OOBIPMacLabel: mac,
+				"test":        "test",
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, ip)).To(Succeed())
+	DeferCleanup(func(ctx SpecContext) {
+		Expect(k8sClient.Delete(ctx, ip)).To(Succeed())
+		Eventually(Get(ip)).Should(Satisfy(errors.IsNotFound))
+	})
+
+	By("Patching IP reservation and state")
+	ipAddr, err := ipamv1alpha1.IPAddrFromString(addr)
+	Expect(err).NotTo(HaveOccurred())
+	Eventually(UpdateStatus(ip, func() {
+		ip.Status.Reserved = ipAddr
+		ip.Status.State = ipamv1alpha1.CFinishedIPState
+	})).Should(Succeed())
+
+	oob := &metalv1alpha1.OOB{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: mac,
+		},
+	}
+	DeferCleanup(func(ctx SpecContext) {
+		Expect(k8sClient.Delete(ctx, oob)).To(Succeed())
+		Eventually(Get(oob)).Should(Satisfy(errors.IsNotFound))
+	})
+	Eventually(Object(oob)).Should(HaveField("Spec.EndpointRef.Name", ip.Name))
+	return oob
+}
+
+// createSSHOOB starts a network device, creates an OOBProfile with its default credentials for the
+// first three bytes of a MAC address and returns the device and the OOB once the OOB controller
+// has taken it over.
+func createSSHOOB(ctx SpecContext, mac string) (*bmc.SSHDevice, *metalv1alpha1.OOB) {
+	By("Starting a network device")
+	dev, err := bmc.NewSSHDevice(map[string]string{"admin": "YourPaSsWoRd"})
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(dev.Close)
+
+	By("Creating a Secret with default credentials")
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    systemNamespace,
+			GenerateName: "test-",
+		},
+		Type: v1.SecretTypeBasicAuth,
+		StringData: map[string]string{
+			v1.BasicAuthUsernameKey: "admin",
+			v1.BasicAuthPasswordKey: "YourPaSsWoRd",
+		},
+	}
+	Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+	DeferCleanup(k8sClient.Delete, secret)
+
+	By("Creating an OOBProfile for the device")
+	profile := &metalv1alpha1.OOBProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-",
+		},
+		Spec: metalv1alpha1.OOBProfileSpec{
+			MACPrefixes: []metalv1alpha1.MACPrefix{metalv1alpha1.MACPrefix(mac[:6])},
+			Protocol: &metalv1alpha1.Protocol{
+				Name: metalv1alpha1.ProtocolNameSSH,
+				Port: int32(dev.Port()),
+			},
+			DefaultCredentialsSecretRefs: []v1.LocalObjectReference{{
+				Name: secret.Name,
+			}},
+		},
+	}
+	Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+	DeferCleanup(func(ctx SpecContext) {
+		Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+		Eventually(Get(profile)).Should(Satisfy(errors.IsNotFound))
+	})
+
+	oob := createOOBWithEndpoint(ctx, mac, "127.0.0.1")
+
+	By("Expecting the OOB controller to take over the device")
+	Eventually(Object(oob)).Should(SatisfyAll(
+		HaveField("Status.State", metalv1alpha1.OOBStateReady),
+		WithTransform(readyReason, Equal(metalv1alpha1.OOBConditionReasonReady)),
+	))
+	return dev, oob
+}
+
+// createMachine creates a Machine for an OOB, with a generated name if name is empty, and waits for
+// the manager to see it.
+func createMachine(ctx SpecContext, name, uuid, oobName string) *metalv1alpha1.Machine {
+	machine := &metalv1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: metalv1alpha1.MachineSpec{
+			UUID: uuid,
+			OOBRef: v1.LocalObjectReference{
+				Name: oobName,
+			},
+		},
+	}
+	if name == "" {
+		machine.GenerateName = "test-"
+	}
+	Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+	deleteMachineOnCleanup(machine)
+	Eventually(func(ctx SpecContext) error {
+		return mgrClient.Get(ctx, client.ObjectKeyFromObject(machine), &metalv1alpha1.Machine{})
+	}).WithContext(ctx).Should(Succeed())
+	return machine
+}
+
+// deleteMachineOnCleanup deletes a Machine at the end of the spec, since there is no garbage collector
+// to delete the Machines of deleted OOBs.
+func deleteMachineOnCleanup(machine *metalv1alpha1.Machine) {
+	DeferCleanup(func(ctx SpecContext) {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, machine))).To(Succeed())
+		Eventually(Get(machine)).Should(Satisfy(errors.IsNotFound))
+	})
+}
+
 func readyReason(o client.Object) (string, error) {
 	oob, ok := o.(*metalv1alpha1.OOB)
 	if !ok {
@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/bmc"
+	"github.com/ironcore-dev/metal/internal/log"
+	"github.com/ironcore-dev/metal/internal/ssa"
+	"github.com/ironcore-dev/metal/internal/util"
+)
+
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobprofiles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+const (
+	OOBProfileFieldManager = "metal.ironcore.dev/oobprofile"
+)
+
+func NewOOBProfileReconciler(systemNamespace string) (*OOBProfileReconciler, error) {
+	if systemNamespace == "" {
+		return nil, fmt.Errorf("system namespace cannot be empty")
+	}
+
+	return &OOBProfileReconciler{
+		systemNamespace: systemNamespace,
+	}, nil
+}
+
+// OOBProfileReconciler reconciles a OOBProfile object
+type OOBProfileReconciler struct {
+	client.Client
+	systemNamespace string
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *OOBProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var profile metalv1alpha1.OOBProfile
+	err := r.Get(ctx, req.NamespacedName, &profile)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("cannot get OOBProfile: %w", err))
+	}
+
+	if !profile.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return r.reconcile(ctx, &profile)
+}
+
+func (r *OOBProfileReconciler) reconcile(ctx context.Context, profile *metalv1alpha1.OOBProfile) (ctrl.Result, error) {
+	log.Debug(ctx, "Reconciling")
+
+	var profileList metalv1alpha1.OOBProfileList
+	err := r.List(ctx, &profileList)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("cannot list OOBProfiles: %w", err)
+	}
+
+	var oobList metalv1alpha1.OOBList
+	err = r.List(ctx, &oobList)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("cannot list OOBs: %w", err)
+	}
+
+	var matched []string
+	for _, o := range oobList.Items {
+		p := matchOOBProfile(profileList.Items, o.Spec.MACAddress)
+		if p != nil && p.Name == profile.Name {
+			matched = append(matched, o.Name)
+		}
+	}
+	slices.Sort(matched)
+
+	cond := metav1.Condition{
+		Type:   metalv1alpha1.OOBProfileConditionTypeReady,
+		Status: metav1.ConditionTrue,
+		Reason: metalv1alpha1.OOBProfileConditionReasonReady,
+	}
+	_, err = oobProfileCredentials(ctx, r, r.systemNamespace, profile)
+	if err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.OOBProfileConditionReasonBadSecrets
+		cond.Message = err.Error()
+	}
+	err = bmc.ValidateFlags(profile.Spec.Flags)
+	if err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = metalv1alpha1.OOBProfileConditionReasonBadFlags
+		cond.Message = err.Error()
+	}
+
+	conds, mod := ssa.SetCondition(profile.Status.Conditions, cond)
+	if slices.Equal(profile.Status.MatchedOOBs, matched) && !mod {
+		log.Debug(ctx, "Reconciled successfully")
+		return ctrl.Result{}, nil
+	}
+
+	var apply *metalv1alpha1apply.OOBProfileApplyConfiguration
+	apply, err = metalv1alpha1apply.ExtractOOBProfileStatus(profile, OOBProfileFieldManager)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	status := util.Ensure(apply.Status).
+		WithMatchedOOBs(matched...)
+	status.Conditions = conds
+	apply = metalv1alpha1apply.OOBProfile(profile.Name, "").WithStatus(status)
+
+	log.Debug(ctx, "Applying status")
+	err = r.Status().Patch(ctx, profile, ssa.Apply(apply), client.FieldOwner(OOBProfileFieldManager), client.ForceOwnership)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("cannot apply OOBProfile status: %w", err)
+	}
+
+	log.Debug(ctx, "Reconciled successfully")
+	return ctrl.Result{}, nil
+}
+
+// matchOOBProfile returns the profile with the longest MAC prefix matching the MAC address, or nil
+// if no profile matches. Profiles that are being deleted are skipped.
+func matchOOBProfile(profiles []metalv1alpha1.OOBProfile, mac string) *metalv1alpha1.OOBProfile {
+	var match *metalv1alpha1.OOBProfile
+	longest := 0
+	for i := range profiles {
+		if !profiles[i].DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, prefix := range profiles[i].Spec.MACPrefixes {
+			if len(prefix) > longest && strings.HasPrefix(mac, string(prefix)) {
+				match = &profiles[i]
+				longest = len(prefix)
+			}
+		}
+	}
+	return match
+}
+
+// oobProfileCredentials reads the default credentials of a profile from Secrets in the system namespace.
+func oobProfileCredentials(ctx context.Context, c client.Client, namespace string, profile *metalv1alpha1.OOBProfile) ([]bmc.Credentials, error) {
+	creds := make([]bmc.Credentials, 0, len(profile.Spec.DefaultCredentialsSecretRefs))
+	for _, ref := range profile.Spec.DefaultCredentialsSecretRefs {
+		var secret v1.Secret
+		err := c.Get(ctx, client.ObjectKey{
+			Namespace: namespace,
+			Name:      ref.Name,
+		}, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get Secret %s: %w", ref.Name, err)
+		}
+		if secret.Type != v1.SecretTypeBasicAuth {
+			return nil, fmt.Errorf("cannot use Secret %s with incorrect type: %s", ref.Name, secret.Type)
+		}
+
+		username := string(secret.Data[v1.BasicAuthUsernameKey])
+		if username == "" {
+			return nil, fmt.Errorf("cannot use Secret %s with missing or empty username", ref.Name)
+		}
+		creds = append(creds, bmc.Credentials{
+			Username: username,
+			Password: string(secret.Data[v1.BasicAuthPasswordKey]),
+		})
+	}
+	return creds, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OOBProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&metalv1alpha1.OOBProfile{}).
+		Watches(&metalv1alpha1.OOBProfile{}, r.enqueueAllOOBProfiles()).
+		Watches(&metalv1alpha1.OOB{}, r.enqueueAllOOBProfiles()).
+		Watches(&v1.Secret{}, r.enqueueAllOOBProfiles()).
+		Complete(r)
+}
+
+// enqueueAllOOBProfiles enqueues every profile, since a change to one profile or OOB can change
+// which profile an OOB matches.
+func (r *OOBProfileReconciler) enqueueAllOOBProfiles() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		secret, ok := obj.(*v1.Secret)
+		if ok && (secret.Namespace != r.systemNamespace || secret.Type != v1.SecretTypeBasicAuth) {
+			return nil
+		}
+
+		profileList := metalv1alpha1.OOBProfileList{}
+		err := r.List(ctx, &profileList)
+		if err != nil {
+			log.Error(ctx, fmt.Errorf("cannot list OOBProfiles: %w", err))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, p := range profileList.Items {
+			if p.DeletionTimestamp != nil {
+				continue
+			}
+
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name: p.Name,
+			}})
+		}
+		return reqs
+	})
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	. "sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/ssa"
+)
+
+var _ = Describe("OOBProfile Controller", func() {
+	It("should report the OOBs matched by the longest prefix", func(ctx SpecContext) {
+		By("Creating an OOBProfile")
+		profile := &metalv1alpha1.OOBProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+			},
+			Spec: metalv1alpha1.OOBProfileSpec{
+				MACPrefixes: []metalv1alpha1.MACPrefix{"0a0b0c"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, profile)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, profile)).To(Succeed())
+			Eventually(Get(profile)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Creating an OOB")
+		oob := &metalv1alpha1.OOB{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "0a0b0c0d0e0f",
+			},
+			Spec: metalv1alpha1.OOBSpec{
+				MACAddress: "0a0b0c0d0e0f",
+			},
+		}
+		Expect(k8sClient.Create(ctx, oob)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, oob)).To(Succeed())
+			Eventually(Get(oob)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Expecting the OOBProfile to match the OOB")
+		Eventually(Object(profile)).Should(SatisfyAll(
+			HaveField("Status.MatchedOOBs", ConsistOf(oob.Name)),
+			WithTransform(oobProfileReadyReason, Equal(metalv1alpha1.OOBProfileConditionReasonReady)),
+		))
+
+		By("Creating a more specific OOBProfile with a missing Secret")
+		specific := &metalv1alpha1.OOBProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+			},
+			Spec: metalv1alpha1.OOBProfileSpec{
+				MACPrefixes: []metalv1alpha1.MACPrefix{"0a0b0c0d"},
+				DefaultCredentialsSecretRefs: []v1.LocalObjectReference{{
+					Name: "missing",
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, specific)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, specific)).To(Succeed())
+			Eventually(Get(specific)).Should(Satisfy(errors.IsNotFound))
+		})
+
+		By("Expecting the more specific OOBProfile to match the OOB")
+		Eventually(Object(specific)).Should(SatisfyAll(
+			HaveField("Status.MatchedOOBs", ConsistOf(oob.Name)),
+			WithTransform(oobProfileReadyReason, Equal(metalv1alpha1.OOBProfileConditionReasonBadSecrets)),
+		))
+		Eventually(Object(profile)).Should(HaveField("Status.MatchedOOBs", BeEmpty()))
+	})
+})
+
+func oobProfileReadyReason(o client.Object) (string, error) {
+	profile, ok := o.(*metalv1alpha1.OOBProfile)
+	if !ok {
+		return "", fmt.Errorf("%s is not an OOBProfile", o.GetName())
+	}
+	var cond metav1.Condition
+	cond, ok = ssa.GetCondition(profile.Status.Conditions, metalv1alpha1.OOBProfileConditionTypeReady)
+	if !ok {
+		return "", fmt.Errorf("%s has no condition of type %s", profile.Name, metalv1alpha1.OOBProfileConditionTypeReady)
+	}
+	return cond.Reason, nil
+}
@@ -5,38 +5,523 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
 
+	"github.com/sethvargo/go-password/password"
+	v1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/bmc"
+	"github.com/ironcore-dev/metal/internal/credstore"
+	"github.com/ironcore-dev/metal/internal/log"
+	"github.com/ironcore-dev/metal/internal/ssa"
+	"github.com/ironcore-dev/metal/internal/util"
 )
 
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobsecrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobsecrets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobsecrets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=metal.ironcore.dev,resources=oobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ipam.metal.ironcore.dev,resources=ips,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
-func NewOOBSecretReconciler() (*OOBSecretReconciler, error) {
-	return &OOBSecretReconciler{}, nil
+const (
+	OOBSecretFieldManager = "metal.ironcore.dev/oobsecret"
+	OOBSecretFinalizer    = "metal.ironcore.dev/oobsecret"
+)
+
+func NewOOBSecretReconciler(systemNamespace, usernamePrefix, temporaryPasswordSecret string, rotationWindow time.Duration, store credstore.Store) (*OOBSecretReconciler, error) {
+	if systemNamespace == "" {
+		return nil, fmt.Errorf("system namespace cannot be empty")
+	}
+	if usernamePrefix == "" {
+		return nil, fmt.Errorf("username prefix cannot be empty")
+	}
+	if temporaryPasswordSecret == "" {
+		return nil, fmt.Errorf("temporary password secret name cannot be empty")
+	}
+	if rotationWindow <= 0 {
+		return nil, fmt.Errorf("rotation window must be positive")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("credential store cannot be nil")
+	}
+
+	return &OOBSecretReconciler{
+		systemNamespace:         systemNamespace,
+		usernamePrefix:          usernamePrefix,
+		temporaryPasswordSecret: temporaryPasswordSecret,
+		rotationWindow:          rotationWindow,
+		store:                   store,
+	}, nil
 }
 
 // OOBSecretReconciler reconciles a OOBSecret object
 type OOBSecretReconciler struct {
 	client.Client
+	systemNamespace         string
+	usernamePrefix          string
+	temporaryPasswordSecret string
+	rotationWindow          time.Duration
+	store                   credstore.Store
+	credentials             credentialProvider
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *OOBSecretReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+func (r *OOBSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var secret metalv1alpha1.OOBSecret
+	err := r.Get(ctx, req.NamespacedName, &secret)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("cannot get OOBSecret: %w", err))
+	}
+
+	if !secret.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, &secret)
+	}
+	return r.reconcile(ctx, &secret)
+}
+
+// finalize deletes the passwords of an OOBSecret from the credential store.
+func (r *OOBSecretReconciler) finalize(ctx context.Context, secret *metalv1alpha1.OOBSecret) error {
+	if !controllerutil.ContainsFinalizer(secret, OOBSecretFinalizer) {
+		return nil
+	}
+	log.Debug(ctx, "Finalizing")
+
+	err := r.store.Delete(ctx, secret.Name)
+	if err != nil {
+		return fmt.Errorf("cannot delete passwords: %w", err)
+	}
+
+	log.Debug(ctx, "Removing finalizer")
+	var apply *metalv1alpha1apply.OOBSecretApplyConfiguration
+	apply, err = metalv1alpha1apply.ExtractOOBSecret(secret, OOBSecretFieldManager)
+	if err != nil {
+		return err
+	}
+	apply.Finalizers = util.Clear(apply.Finalizers, OOBSecretFinalizer)
+	err = r.Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBSecretFieldManager), client.ForceOwnership)
+	if err != nil {
+		return fmt.Errorf("cannot apply OOBSecret: %w", err)
+	}
+
+	log.Debug(ctx, "Finalized successfully")
+	return nil
+}
+
+// reconcile rotates the credentials of a BMC before they expire. A rotation is first marked in the
+// status, so that the OOB controller keeps the new BMC user until it is stored in the OOBSecret.
+func (r *OOBSecretReconciler) reconcile(ctx context.Context, secret *metalv1alpha1.OOBSecret) (ctrl.Result, error) {
+	log.Debug(ctx, "Reconciling")
+
+	if !controllerutil.ContainsFinalizer(secret, OOBSecretFinalizer) {
+		apply, err := metalv1alpha1apply.ExtractOOBSecret(secret, OOBSecretFieldManager)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		apply.Finalizers = util.Set(apply.Finalizers, OOBSecretFinalizer)
+
+		log.Debug(ctx, "Adding finalizer")
+		err = r.Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBSecretFieldManager), client.ForceOwnership)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("cannot apply OOBSecret: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	changed, err := r.migratePassword(ctx, secret)
+	if err != nil || changed {
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Passwords of previous rotations are kept until the rotation is finished, so that the OOB and
+	// Machine controllers can still use them. They are deleted once for every new password.
+	if secret.Spec.PasswordRef != "" && secret.Spec.PasswordRef != secret.Status.RotatedPasswordRef &&
+		secret.Status.PendingPasswordRef == "" && !oobSecretRotating(secret) {
+		log.Debug(ctx, "Deleting previous passwords")
+		err = r.store.Rotate(ctx, secret.Name, secret.Spec.PasswordRef)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("cannot delete previous passwords: %w", err)
+		}
+
+		var applyst *metalv1alpha1apply.OOBSecretApplyConfiguration
+		applyst, err = metalv1alpha1apply.ExtractOOBSecretStatus(secret, OOBSecretFieldManager)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		apply := metalv1alpha1apply.OOBSecret(secret.Name, "").
+			WithStatus(util.Ensure(applyst.Status).
+				WithRotatedPasswordRef(secret.Spec.PasswordRef))
+		err = r.Status().Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBSecretFieldManager), client.ForceOwnership)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("cannot apply OOBSecret status: %w", err)
+		}
+	}
+
+	if secret.Spec.ExpirationTime == nil {
+		err := r.applyStatus(ctx, secret, nil, metav1.Condition{
+			Type:   metalv1alpha1.OOBSecretConditionTypeRotation,
+			Status: metav1.ConditionTrue,
+			Reason: metalv1alpha1.OOBSecretConditionReasonNotExpiring,
+		})
+		if err == nil {
+			log.Debug(ctx, "Reconciled successfully")
+		}
+		return ctrl.Result{}, err
+	}
+
+	rotation := oobSecretRotationTime(secret, r.rotationWindow)
+	if time.Now().Before(rotation) {
+		err := r.applyStatus(ctx, secret, nil, metav1.Condition{
+			Type:    metalv1alpha1.OOBSecretConditionTypeRotation,
+			Status:  metav1.ConditionTrue,
+			Reason:  metalv1alpha1.OOBSecretConditionReasonScheduled,
+			Message: fmt.Sprintf("credentials expire at %s and are rotated after %s", secret.Spec.ExpirationTime.UTC().Format(time.RFC3339), rotation.UTC().Format(time.RFC3339)),
+		})
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Debug(ctx, "Reconciled successfully")
+		return ctrl.Result{RequeueAfter: time.Until(rotation)}, nil
+	}
 
+	cond := apimeta.FindStatusCondition(secret.Status.Conditions, metalv1alpha1.OOBSecretConditionTypeRotation)
+	if cond == nil || cond.Reason != metalv1alpha1.OOBSecretConditionReasonRotating {
+		log.Info(ctx, "Rotating credentials", "expiration", secret.Spec.ExpirationTime.Time)
+		return ctrl.Result{}, r.applyStatus(ctx, secret, nil, metav1.Condition{
+			Type:   metalv1alpha1.OOBSecretConditionTypeRotation,
+			Status: metav1.ConditionFalse,
+			Reason: metalv1alpha1.OOBSecretConditionReasonRotating,
+		})
+	}
+
+	err = r.rotate(ctx, secret)
+	if err != nil {
+		serr := r.applyStatus(ctx, secret, nil, metav1.Condition{
+			Type:    metalv1alpha1.OOBSecretConditionTypeRotation,
+			Status:  metav1.ConditionFalse,
+			Reason:  metalv1alpha1.OOBSecretConditionReasonFailed,
+			Message: err.Error(),
+		})
+		if serr != nil {
+			log.Error(ctx, serr)
+		}
+		return ctrl.Result{}, err
+	}
+
+	log.Debug(ctx, "Reconciled successfully")
 	return ctrl.Result{}, nil
 }
 
+// rotate creates a new BMC user and stores its credentials. The password is stored and the user is
+// recorded as pending in the status before the user is created, so that a rotation that fails after
+// the user was created removes the user and creates it again with the same credentials. The previous
+// user is deleted by the OOB controller once it uses the new credentials.
+func (r *OOBSecretReconciler) rotate(ctx context.Context, secret *metalv1alpha1.OOBSecret) error {
+	creds, resumed, err := r.pendingCredentials(ctx, secret)
+	if err != nil {
+		return err
+	}
+	ctx = log.WithValues(ctx, "user", creds.Username)
+
+	if resumed && secret.Spec.PasswordRef == secret.Status.PendingPasswordRef {
+		return r.finishRotation(ctx, secret)
+	}
+
+	oob, err := r.oobForSecret(ctx, secret)
+	if err != nil {
+		return err
+	}
+	ctx = log.WithValues(ctx, "oob", oob.Name)
+
+	b, err := newBMCForOOB(ctx, r, r.credentials, r.systemNamespace, oob)
+	if err != nil {
+		return fmt.Errorf("cannot create BMC: %w", err)
+	}
+
+	var tempSecret v1.Secret
+	err = r.Get(ctx, client.ObjectKey{
+		Namespace: r.systemNamespace,
+		Name:      r.temporaryPasswordSecret,
+	}, &tempSecret)
+	if err != nil {
+		return fmt.Errorf("cannot get temporary password Secret: %w", err)
+	}
+	tempPassword := string(tempSecret.Data[v1.BasicAuthPasswordKey])
+
+	if resumed {
+		log.Info(ctx, "Removing BMC user of a failed rotation")
+		err = b.DeleteUsers(ctx, regexp.MustCompile("^"+regexp.QuoteMeta(creds.Username)+"$"))
+		if err != nil {
+			return fmt.Errorf("cannot delete user: %w", err)
+		}
+	}
+
+	log.Info(ctx, "Creating new BMC user")
+	err = b.CreateUser(ctx, creds, tempPassword)
+	if err != nil {
+		return fmt.Errorf("cannot create user: %w", err)
+	}
+
+	err = b.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot verify new credentials: %w", err)
+	}
+	newCreds, exp := b.Credentials()
+
+	// Some BMCs require the password of a new user to be changed to the temporary password.
+	ref := secret.Status.PendingPasswordRef
+	if newCreds.Password != creds.Password {
+		ref, err = r.store.Put(ctx, secret.Name, newCreds.Password)
+		if err != nil {
+			return fmt.Errorf("cannot store password: %w", err)
+		}
+	}
+
+	// The credentials are applied with the field manager of the OOB controller, which created them,
+	// so that an expiration time that no longer applies is removed.
+	spec := metalv1alpha1apply.OOBSecretSpec().
+		WithMACAddress(secret.Spec.MACAddress).
+		WithUsername(newCreds.Username).
+		WithPasswordRef(ref)
+	if !exp.IsZero() {
+		spec = spec.WithExpirationTime(metav1.NewTime(exp))
+	}
+	apply := metalv1alpha1apply.OOBSecret(secret.Name, "").
+		WithSpec(spec)
+
+	log.Info(ctx, "Storing new credentials")
+	err = r.Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBFieldManager), client.ForceOwnership)
+	if err != nil {
+		return fmt.Errorf("cannot apply OOBSecret: %w", err)
+	}
+
+	return r.finishRotation(ctx, secret)
+}
+
+// pendingCredentials returns the credentials of the pending BMC user of a rotation and whether they
+// were recorded by a previous attempt. If there is no pending user, new credentials are generated,
+// stored and recorded in the status.
+func (r *OOBSecretReconciler) pendingCredentials(ctx context.Context, secret *metalv1alpha1.OOBSecret) (bmc.Credentials, bool, error) {
+	creds := bmc.Credentials{
+		Username: secret.Status.PendingUsername,
+	}
+	if secret.Status.PendingPasswordRef != "" {
+		var err error
+		creds.Password, err = r.store.Get(ctx, secret.Status.PendingPasswordRef)
+		if err == nil {
+			return creds, true, nil
+		}
+		if !errors.Is(err, credstore.ErrNotFound) {
+			return bmc.Credentials{}, false, fmt.Errorf("cannot get pending password: %w", err)
+		}
+		log.Info(ctx, "Pending password is missing, starting over", "ref", secret.Status.PendingPasswordRef)
+	}
+
+	var err error
+	creds.Username, err = password.Generate(6, 0, 0, true, true)
+	if err != nil {
+		return bmc.Credentials{}, false, fmt.Errorf("cannot generate username: %w", err)
+	}
+	creds.Username = r.usernamePrefix + creds.Username
+	creds.Password, err = password.Generate(16, 4, 0, false, true)
+	if err != nil {
+		return bmc.Credentials{}, false, fmt.Errorf("cannot generate password: %w", err)
+	}
+
+	var ref string
+	ref, err = r.store.Put(ctx, secret.Name, creds.Password)
+	if err != nil {
+		return bmc.Credentials{}, false, fmt.Errorf("cannot store password: %w", err)
+	}
+
+	log.Debug(ctx, "Recording pending user", "user", creds.Username)
+	err = r.applyRotationStatus(ctx, secret, nil, creds.Username, ref)
+	if err != nil {
+		return bmc.Credentials{}, false, err
+	}
+	return creds, false, nil
+}
+
+// finishRotation records the time of the rotation and removes the pending user from the status.
+func (r *OOBSecretReconciler) finishRotation(ctx context.Context, secret *metalv1alpha1.OOBSecret) error {
+	now := metav1.Now()
+	return r.applyRotationStatus(ctx, secret, &now, "", "")
+}
+
+// applyRotationStatus sets the pending user and, if it is not nil, the last rotation time.
+func (r *OOBSecretReconciler) applyRotationStatus(ctx context.Context, secret *metalv1alpha1.OOBSecret, rotated *metav1.Time, username, ref string) error {
+	applyst, err := metalv1alpha1apply.ExtractOOBSecretStatus(secret, OOBSecretFieldManager)
+	if err != nil {
+		return err
+	}
+	status := util.Ensure(applyst.Status)
+	if rotated != nil {
+		status = status.WithLastRotationTime(*rotated)
+	}
+	status.PendingUsername = nil
+	status.PendingPasswordRef = nil
+	if username != "" {
+		status = status.WithPendingUsername(username).
+			WithPendingPasswordRef(ref)
+	}
+	apply := metalv1alpha1apply.OOBSecret(secret.Name, "").WithStatus(status)
+
+	log.Debug(ctx, "Applying status")
+	err = r.Status().Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBSecretFieldManager), client.ForceOwnership)
+	if err != nil {
+		return fmt.Errorf("cannot apply OOBSecret status: %w", err)
+	}
+	return nil
+}
+
+// migratePassword moves a password that is stored in an OOBSecret to the credential store. It returns true if the OOBSecret was changed.
+func (r *OOBSecretReconciler) migratePassword(ctx context.Context, secret *metalv1alpha1.OOBSecret) (bool, error) {
+	if secret.Spec.PasswordRef != "" || secret.Spec.Password == "" {
+		return false, nil
+	}
+
+	creds, err := r.credentials.Credentials(ctx, secret)
+	if err != nil {
+		return false, err
+	}
+	var ref string
+	ref, err = r.store.Put(ctx, secret.Name, creds.Password)
+	if err != nil {
+		return false, fmt.Errorf("cannot store password: %w", err)
+	}
+
+	log.Info(ctx, "Moving password to credential store")
+	base := secret.DeepCopy()
+	secret.Spec.Password = ""
+	secret.Spec.PasswordRef = ref
+	// The password may be owned by any field manager, so it is removed with a merge patch.
+	err = r.Patch(ctx, secret, client.MergeFrom(base))
+	if err != nil {
+		return false, fmt.Errorf("cannot patch OOBSecret: %w", err)
+	}
+
+	return true, nil
+}
+
+// oobForSecret returns the OOB that uses an OOBSecret.
+func (r *OOBSecretReconciler) oobForSecret(ctx context.Context, secret *metalv1alpha1.OOBSecret) (*metalv1alpha1.OOB, error) {
+	var oobList metalv1alpha1.OOBList
+	err := r.List(ctx, &oobList, client.MatchingFields{OOBSpecMACAddress: secret.Spec.MACAddress})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list OOBs: %w", err)
+	}
+
+	for i := range oobList.Items {
+		o := &oobList.Items[i]
+		if o.DeletionTimestamp.IsZero() && o.Spec.SecretRef != nil && o.Spec.SecretRef.Name == secret.Name {
+			return o, nil
+		}
+	}
+	return nil, fmt.Errorf("no OOB uses OOBSecret %s", secret.Name)
+}
+
+// applyStatus sets the rotation condition and, if it is not nil, the last rotation time. It does
+// nothing if the status would not change.
+func (r *OOBSecretReconciler) applyStatus(ctx context.Context, secret *metalv1alpha1.OOBSecret, rotated *metav1.Time, cond metav1.Condition) error {
+	conds, mod := ssa.SetCondition(secret.Status.Conditions, cond)
+	if !mod && rotated == nil {
+		return nil
+	}
+
+	applyst, err := metalv1alpha1apply.ExtractOOBSecretStatus(secret, OOBSecretFieldManager)
+	if err != nil {
+		return err
+	}
+	status := util.Ensure(applyst.Status)
+	if rotated != nil {
+		status = status.WithLastRotationTime(*rotated)
+	}
+	status.Conditions = conds
+	apply := metalv1alpha1apply.OOBSecret(secret.Name, "").WithStatus(status)
+
+	log.Debug(ctx, "Applying status")
+	err = r.Status().Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBSecretFieldManager), client.ForceOwnership)
+	if err != nil {
+		return fmt.Errorf("cannot apply OOBSecret status: %w", err)
+	}
+	return nil
+}
+
+// oobSecretRotationTime returns the time to rotate the credentials of an OOBSecret: the rotation
+// window before they expire, but not before half of their lifetime, so that credentials with a
+// short lifetime are not rotated in a loop.
+func oobSecretRotationTime(secret *metalv1alpha1.OOBSecret, window time.Duration) time.Time {
+	start := secret.CreationTimestamp.Time
+	if secret.Status.LastRotationTime != nil {
+		start = secret.Status.LastRotationTime.Time
+	}
+	exp := secret.Spec.ExpirationTime.Time
+
+	rotation := exp.Add(-window)
+	half := start.Add(exp.Sub(start) / 2)
+	if rotation.Before(half) {
+		rotation = half
+	}
+	return rotation
+}
+
+// credentialProvider returns the BMC credentials of OOBSecrets.
+type credentialProvider interface {
+	Credentials(ctx context.Context, secret *metalv1alpha1.OOBSecret) (bmc.Credentials, error)
+}
+
+// storeCredentialProvider reads passwords from the credential store. The password of an OOBSecret
+// that has not been moved to the credential store yet is read from the OOBSecret itself.
+type storeCredentialProvider struct {
+	store credstore.Store
+}
+
+func newStoreCredentialProvider(store credstore.Store) *storeCredentialProvider {
+	return &storeCredentialProvider{
+		store: store,
+	}
+}
+
+func (p *storeCredentialProvider) Credentials(ctx context.Context, secret *metalv1alpha1.OOBSecret) (bmc.Credentials, error) {
+	creds := bmc.Credentials{
+		Username: secret.Spec.Username,
+		Password: secret.Spec.Password,
+	}
+
+	if secret.Spec.PasswordRef != "" {
+		pw, err := p.store.Get(ctx, secret.Spec.PasswordRef)
+		if err != nil {
+			return bmc.Credentials{}, fmt.Errorf("cannot get password: %w", err)
+		}
+		creds.Password = pw
+	}
+	return creds, nil
+}
+
+// oobSecretRotating returns whether the credentials of an OOBSecret are being rotated.
+func oobSecretRotating(secret *metalv1alpha1.OOBSecret) bool {
+	cond := apimeta.FindStatusCondition(secret.Status.Conditions, metalv1alpha1.OOBSecretConditionTypeRotation)
+	return cond != nil && cond.Reason == metalv1alpha1.OOBSecretConditionReasonRotating
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *OOBSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Client = mgr.GetClient()
+	r.credentials = newStoreCredentialProvider(r.store)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&metalv1alpha1.OOBSecret{}).
@@ -4,8 +4,178 @@
 package controller
 
 import (
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	. "sigs.k8s.io/controller-runtime/pkg/envtest/komega"
+
+	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	metalv1alpha1apply "github.com/ironcore-dev/metal/client/applyconfiguration/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/credstore"
+	"github.com/ironcore-dev/metal/internal/ssa"
 )
 
 var _ = Describe("OOBSecret Controller", func() {
+	createOOBSecret := func(ctx SpecContext, exp *metav1.Time) *metalv1alpha1.OOBSecret {
+		secret := &metalv1alpha1.OOBSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-",
+			},
+			Spec: metalv1alpha1.OOBSecretSpec{
+				MACAddress:     "0a0b0c0d0e0f",
+				Username:       "metal-abcdef",
+				Password:       "password",
+				ExpirationTime: exp,
+			},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			Eventually(Get(secret)).Should(Satisfy(errors.IsNotFound))
+		})
+		return secret
+	}
+
+	It("should not rotate credentials that do not expire", func(ctx SpecContext) {
+		secret := createOOBSecret(ctx, nil)
+
+		By("Expecting the rotation to be not required")
+		Eventually(Object(secret)).Should(
+			WithTransform(oobSecretRotationReason, Equal(metalv1alpha1.OOBSecretConditionReasonNotExpiring)))
+	})
+
+	It("should schedule the rotation of expiring credentials", func(ctx SpecContext) {
+		exp := metav1.NewTime(time.Now().Add(30 * 24 * time.Hour))
+		secret := createOOBSecret(ctx, &exp)
+
+		By("Expecting the rotation to be scheduled")
+		Eventually(Object(secret)).Should(SatisfyAll(
+			WithTransform(oobSecretRotationReason, Equal(metalv1alpha1.OOBSecretConditionReasonScheduled)),
+			HaveField("Status.LastRotationTime", BeNil()),
+		))
+	})
+
+	It("should report a failed rotation if no OOB uses the credentials", func(ctx SpecContext) {
+		exp := metav1.NewTime(time.Now().Add(time.Hour))
+		secret := createOOBSecret(ctx, &exp)
+
+		By("Expecting the rotation to fail")
+		Eventually(Object(secret)).Should(
+			WithTransform(oobSecretRotationReason, Equal(metalv1alpha1.OOBSecretConditionReasonFailed)))
+		Expect(secret.Spec.Username).To(Equal("metal-abcdef"))
+	})
+
+	It("should move an inline password to the credential store", func(ctx SpecContext) {
+		secret := createOOBSecret(ctx, nil)
+
+		By("Expecting the password to be replaced by a reference")
+		Eventually(Object(secret)).Should(SatisfyAll(
+			HaveField("Finalizers", ContainElement(OOBSecretFinalizer)),
+			HaveField("Spec.Password", BeEmpty()),
+			HaveField("Spec.PasswordRef", Not(BeEmpty())),
+		))
+
+		By("Expecting the Secret to hold the password")
+		passwordSecret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: OOBTemporaryNamespaceHack,
+				Name:      secret.Spec.PasswordRef,
+			},
+		}
+		Eventually(Object(passwordSecret)).Should(SatisfyAll(
+			HaveField("Data", HaveKeyWithValue(v1.BasicAuthPasswordKey, []byte("password"))),
+			HaveField("Labels", HaveKeyWithValue(credstore.OwnerLabel, secret.Name)),
+		))
+	})
+
+	It("should resume a rotation that failed after the user was created", func(ctx SpecContext) {
+		dev, oob := createSSHOOB(ctx, "a1b2c4ddeeff")
+		secret := &metalv1alpha1.OOBSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: oob.Spec.SecretRef.Name,
+			},
+		}
+		DeferCleanup(func(ctx SpecContext) {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+			Eventually(Get(secret)).Should(Satisfy(errors.IsNotFound))
+		})
+		Eventually(Get(secret)).Should(Succeed())
+		username := secret.Spec.Username
+
+		By("Making the device reject new users")
+		dev.BlockNewUsers(true)
+
+		By("Letting the credentials expire, as reported by the BMC")
+		apply := metalv1alpha1apply.OOBSecret(secret.Name, "").
+			WithSpec(metalv1alpha1apply.OOBSecretSpec().
+				WithMACAddress(secret.Spec.MACAddress).
+				WithUsername(secret.Spec.Username).
+				WithPasswordRef(secret.Spec.PasswordRef).
+				WithExpirationTime(metav1.Now()))
+		Expect(k8sClient.Patch(ctx, secret, ssa.Apply(apply), client.FieldOwner(OOBFieldManager), client.ForceOwnership)).To(Succeed())
+
+		By("Expecting the rotation to fail with a pending user")
+		Eventually(Object(secret)).Should(SatisfyAll(
+			WithTransform(oobSecretRotationReason, Equal(metalv1alpha1.OOBSecretConditionReasonFailed)),
+			HaveField("Status.PendingUsername", HavePrefix("metal-")),
+			HaveField("Status.PendingPasswordRef", Not(BeEmpty())),
+			HaveField("Spec.Username", username),
+		))
+		pendingUsername := secret.Status.PendingUsername
+		pendingPasswordRef := secret.Status.PendingPasswordRef
+
+		By("Making the device accept new users")
+		dev.BlockNewUsers(false)
+
+		By("Expecting the rotation to finish with the pending user")
+		Eventually(Object(secret)).Should(SatisfyAll(
+			HaveField("Spec.Username", pendingUsername),
+			HaveField("Spec.PasswordRef", pendingPasswordRef),
+			HaveField("Status.PendingUsername", BeEmpty()),
+			HaveField("Status.PendingPasswordRef", BeEmpty()),
+			HaveField("Status.LastRotationTime", Not(BeNil())),
+		))
+
+		By("Expecting the previous passwords to be deleted once")
+		Eventually(Object(secret)).Should(HaveField("Status.RotatedPasswordRef", pendingPasswordRef))
+		var secretList v1.SecretList
+		Expect(k8sClient.List(ctx, &secretList, client.MatchingLabels{credstore.OwnerLabel: secret.Name})).To(Succeed())
+		Expect(secretList.Items).To(ConsistOf(HaveField("Name", pendingPasswordRef)))
+
+		By("Expecting the previous user to be deleted")
+		Eventually(dev.UserNames).Should(ConsistOf("admin", pendingUsername))
+	})
+
+	It("should not rotate before half of the lifetime of the credentials", func() {
+		now := time.Now()
+		secret := &metalv1alpha1.OOBSecret{
+			Spec: metalv1alpha1.OOBSecretSpec{
+				ExpirationTime: &metav1.Time{Time: now.Add(4 * 24 * time.Hour)},
+			},
+			Status: metalv1alpha1.OOBSecretStatus{
+				LastRotationTime: &metav1.Time{Time: now},
+			},
+		}
+		Expect(oobSecretRotationTime(secret, 7*24*time.Hour)).To(BeTemporally("==", now.Add(2*24*time.Hour)))
+		Expect(oobSecretRotationTime(secret, 24*time.Hour)).To(BeTemporally("==", now.Add(3*24*time.Hour)))
+	})
 })
+
+func oobSecretRotationReason(o client.Object) (string, error) {
+	secret, ok := o.(*metalv1alpha1.OOBSecret)
+	if !ok {
+		return "", fmt.Errorf("%s is not an OOBSecret", o.GetName())
+	}
+	var cond metav1.Condition
+	cond, ok = ssa.GetCondition(secret.Status.Conditions, metalv1alpha1.OOBSecretConditionTypeRotation)
+	if !ok {
+		return "", fmt.Errorf("%s has no condition of type %s", secret.Name, metalv1alpha1.OOBSecretConditionTypeRotation)
+	}
+	return cond.Reason, nil
+}
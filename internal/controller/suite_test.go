@@ -29,12 +29,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	metalv1alpha1 "github.com/ironcore-dev/metal/api/v1alpha1"
+	"github.com/ironcore-dev/metal/internal/credstore"
 	"github.com/ironcore-dev/metal/internal/log"
 	//+kubebuilder:scaffold:imports
 )
 
 var (
-	k8sClient client.Client
+	k8sClient       client.Client
+	mgrClient       client.Client
+	systemNamespace string
 )
 
 func TestControllers(t *testing.T) {
@@ -88,6 +91,7 @@ var _ = BeforeSuite(func() {
 	DeferCleanup(func(ctx SpecContext) {
 		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
 	})
+	systemNamespace = ns.Name
 
 	ns = &v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -109,9 +113,14 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(mgr).NotTo(BeNil())
 	Expect(CreateIndexes(ctx, mgr)).To(Succeed())
+	mgrClient = mgr.GetClient()
+
+	var store credstore.Store
+	store, err = credstore.NewSecretStore(mgr.GetClient(), ns.Name)
+	Expect(err).NotTo(HaveOccurred())
 
 	var machineReconciler *MachineReconciler
-	machineReconciler, err = NewMachineReconciler()
+	machineReconciler, err = NewMachineReconciler(ns.Name, time.Minute, store)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(machineReconciler).NotTo(BeNil())
 	Expect(machineReconciler.SetupWithManager(mgr)).To(Succeed())
@@ -123,17 +132,23 @@ var _ = BeforeSuite(func() {
 	Expect(machineClaimReconciler.SetupWithManager(mgr)).To(Succeed())
 
 	var oobReconciler *OOBReconciler
-	oobReconciler, err = NewOOBReconciler(ns.Name, "", "", "metal-", "bmc-temporary-password")
+	oobReconciler, err = NewOOBReconciler(ns.Name, "", "", "", "", "metal-", "bmc-temporary-password", time.Hour, 0, store)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(oobReconciler).NotTo(BeNil())
 	Expect(oobReconciler.SetupWithManager(mgr)).To(Succeed())
 
 	var oobSecretReconciler *OOBSecretReconciler
-	oobSecretReconciler, err = NewOOBSecretReconciler()
+	oobSecretReconciler, err = NewOOBSecretReconciler(ns.Name, "metal-", "bmc-temporary-password", 24*time.Hour, store)
 	Expect(err).NotTo(HaveOccurred())
 	Expect(oobSecretReconciler).NotTo(BeNil())
 	Expect(oobSecretReconciler.SetupWithManager(mgr)).To(Succeed())
 
+	var oobProfileReconciler *OOBProfileReconciler
+	oobProfileReconciler, err = NewOOBProfileReconciler(ns.Name)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(oobProfileReconciler).NotTo(BeNil())
+	Expect(oobProfileReconciler.SetupWithManager(mgr)).To(Succeed())
+
 	mgrCtx, mgrCancel := context.WithCancel(ctx)
 	DeferCleanup(mgrCancel)
 
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package credstore stores the passwords of BMC users outside of OOBSecrets, which only reference
+// them.
+package credstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sethvargo/go-password/password"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var ErrNotFound = errors.New("password not found")
+
+// Store stores the passwords of the BMC users of OOBSecrets. Every password is stored under a new
+// reference, so that an OOBSecret switches to a new password atomically and the previous password
+// remains available until it does.
+type Store interface {
+	// Get returns the password stored under a reference.
+	Get(ctx context.Context, ref string) (string, error)
+	// Put stores a new password of an owner and returns its reference.
+	Put(ctx context.Context, owner, password string) (string, error)
+	// Rotate finishes a rotation by deleting all passwords of an owner other than the one of ref.
+	Rotate(ctx context.Context, owner, ref string) error
+	// Delete deletes all passwords of an owner.
+	Delete(ctx context.Context, owner string) error
+}
+
+// Open returns the store of a URI:
+//   - secret: Secrets in the system namespace
+//   - file:///path: files in a directory
+//   - vault+https://host:port/mount/prefix?tokenFile=/path&caFile=/path: a Vault KV version 2
+//     secrets engine, authenticated with a token that is read from a file for every request
+func Open(uri string, c client.Client, namespace string) (Store, error) {
+	if uri == "" || uri == "secret" {
+		return NewSecretStore(c, namespace)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse credential store URI: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		return NewFileStore(u.Path)
+	case "vault+http", "vault+https":
+		q := u.Query()
+		address := url.URL{
+			Scheme: strings.TrimPrefix(u.Scheme, "vault+"),
+			Host:   u.Host,
+		}
+		return NewVaultStore(address.String(), strings.Trim(u.Path, "/"), q.Get("tokenFile"), q.Get("caFile"))
+	default:
+		return nil, fmt.Errorf("credential store of type %s is not supported", u.Scheme)
+	}
+}
+
+func newSuffix() (string, error) {
+	suffix, err := password.Generate(5, 0, 0, true, true)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate reference: %w", err)
+	}
+	return suffix, nil
+}
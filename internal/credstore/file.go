@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore stores every password in a file in a directory, which is not shared with the Kubernetes
+// API. The reference of a password is the name of its file: the owner and a random suffix, separated
+// by an underscore, which cannot occur in the name of an owner.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("directory cannot be empty")
+	}
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create directory: %w", err)
+	}
+
+	return &FileStore{
+		dir: dir,
+	}, nil
+}
+
+func (s *FileStore) Get(_ context.Context, ref string) (string, error) {
+	if !validFileRef(ref) {
+		return "", fmt.Errorf("%w: invalid reference %s", ErrNotFound, ref)
+	}
+
+	pw, err := os.ReadFile(filepath.Join(s.dir, ref))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: file %s does not exist", ErrNotFound, ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read file: %w", err)
+	}
+	return string(pw), nil
+}
+
+// Put writes the password to a temporary file first, so that a reference never refers to a
+// partially written password.
+func (s *FileStore) Put(_ context.Context, owner, password string) (string, error) {
+	suffix, err := newSuffix()
+	if err != nil {
+		return "", err
+	}
+	ref := owner + "_" + suffix
+	if !validFileRef(ref) {
+		return "", fmt.Errorf("invalid owner %s", owner)
+	}
+
+	var f *os.File
+	f, err = os.CreateTemp(s.dir, ".tmp-")
+	if err != nil {
+		return "", fmt.Errorf("cannot create file: %w", err)
+	}
+	_, err = f.WriteString(password)
+	if err == nil {
+		err = f.Sync()
+	}
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(s.dir, ref))
+	}
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("cannot write file: %w", err), os.Remove(f.Name()))
+	}
+	return ref, nil
+}
+
+func (s *FileStore) Rotate(_ context.Context, owner, ref string) error {
+	return s.deleteAll(owner, ref)
+}
+
+func (s *FileStore) Delete(_ context.Context, owner string) error {
+	return s.deleteAll(owner, "")
+}
+
+func (s *FileStore) deleteAll(owner, except string) error {
+	if !validFileRef(owner + "_") {
+		return fmt.Errorf("invalid owner %s", owner)
+	}
+
+	refs, err := filepath.Glob(filepath.Join(s.dir, owner+"_*"))
+	if err != nil {
+		return fmt.Errorf("cannot list files: %w", err)
+	}
+
+	for _, r := range refs {
+		if filepath.Base(r) == except {
+			continue
+		}
+		err = os.Remove(r)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("cannot delete file: %w", err)
+		}
+	}
+	return nil
+}
+
+func validFileRef(ref string) bool {
+	return ref != "" && !strings.HasPrefix(ref, ".") && filepath.Base(ref) == ref && strings.Count(ref, "_") == 1 &&
+		!strings.ContainsAny(ref, `*?[]\`)
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File Store", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	testStore(func() Store {
+		s, err := NewFileStore(dir)
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	})
+
+	It("should only allow the owner to read passwords", func(ctx SpecContext) {
+		s, err := NewFileStore(dir)
+		Expect(err).NotTo(HaveOccurred())
+		var ref string
+		ref, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		var fi os.FileInfo
+		fi, err = os.Stat(filepath.Join(dir, ref))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+	})
+
+	It("should reject references outside of the directory", func(ctx SpecContext) {
+		Expect(os.WriteFile(filepath.Join(dir, "..", "other_abcde"), []byte("secret"), 0o600)).To(Succeed())
+		s, err := NewFileStore(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = s.Get(ctx, "../other_abcde")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+})
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// SealedStore encrypts passwords with AES-256-GCM before storing them in another store, so that the
+// other store only holds passwords that are useless without the key.
+type SealedStore struct {
+	Store
+	aead cipher.AEAD
+}
+
+func Seal(s Store, key []byte) (*SealedStore, error) {
+	if s == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes long, not %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cipher: %w", err)
+	}
+	var aead cipher.AEAD
+	aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cipher: %w", err)
+	}
+
+	return &SealedStore{
+		Store: s,
+		aead:  aead,
+	}, nil
+}
+
+func (s *SealedStore) Get(ctx context.Context, ref string) (string, error) {
+	sealed, err := s.Store.Get(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	var b []byte
+	b, err = base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(b) < s.aead.NonceSize() {
+		return "", fmt.Errorf("password %s is not sealed", ref)
+	}
+	var pw []byte
+	pw, err = s.aead.Open(nil, b[:s.aead.NonceSize()], b[s.aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot unseal password %s: %w", ref, err)
+	}
+	return string(pw), nil
+}
+
+func (s *SealedStore) Put(ctx context.Context, owner, password string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("cannot generate nonce: %w", err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(password), nil)
+	return s.Store.Put(ctx, owner, base64.StdEncoding.EncodeToString(sealed))
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sealed Store", func() {
+	var fs *FileStore
+	key := bytes.Repeat([]byte{0x2a}, 32)
+
+	BeforeEach(func() {
+		var err error
+		fs, err = NewFileStore(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	testStore(func() Store {
+		s, err := Seal(fs, key)
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	})
+
+	It("should not store plaintext passwords", func(ctx SpecContext) {
+		s, err := Seal(fs, key)
+		Expect(err).NotTo(HaveOccurred())
+		var ref string
+		ref, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fs.Get(ctx, ref)).NotTo(ContainSubstring("secret"))
+	})
+
+	It("should not unseal passwords with another key", func(ctx SpecContext) {
+		s, err := Seal(fs, key)
+		Expect(err).NotTo(HaveOccurred())
+		var ref string
+		ref, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err = Seal(fs, bytes.Repeat([]byte{0x2b}, 32))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = s.Get(ctx, ref)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject keys of the wrong size", func() {
+		_, err := Seal(fs, []byte("short"))
+		Expect(err).To(HaveOccurred())
+	})
+})
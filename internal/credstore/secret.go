@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// OwnerLabel is set on the Secrets of a SecretStore to the owner of the password.
+	OwnerLabel = "metal.ironcore.dev/oobsecret"
+)
+
+// SecretStore stores every password in a Secret of type kubernetes.io/basic-auth. The reference of
+// a password is the name of its Secret.
+type SecretStore struct {
+	client    client.Client
+	namespace string
+}
+
+func NewSecretStore(c client.Client, namespace string) (*SecretStore, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	return &SecretStore{
+		client:    c,
+		namespace: namespace,
+	}, nil
+}
+
+func (s *SecretStore) Get(ctx context.Context, ref string) (string, error) {
+	var secret v1.Secret
+	err := s.client.Get(ctx, client.ObjectKey{
+		Namespace: s.namespace,
+		Name:      ref,
+	}, &secret)
+	if errors.IsNotFound(err) {
+		return "", fmt.Errorf("%w: Secret %s does not exist", ErrNotFound, ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot get Secret %s: %w", ref, err)
+	}
+
+	pw := string(secret.Data[v1.BasicAuthPasswordKey])
+	if pw == "" {
+		return "", fmt.Errorf("%w: Secret %s has no password", ErrNotFound, ref)
+	}
+	return pw, nil
+}
+
+func (s *SecretStore) Put(ctx context.Context, owner, password string) (string, error) {
+	suffix, err := newSuffix()
+	if err != nil {
+		return "", err
+	}
+
+	secret := v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      fmt.Sprintf("oobsecret-%s-%s", owner, suffix),
+			Labels: map[string]string{
+				OwnerLabel: owner,
+			},
+		},
+		Type: v1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			v1.BasicAuthPasswordKey: []byte(password),
+		},
+	}
+	err = s.client.Create(ctx, &secret)
+	if err != nil {
+		return "", fmt.Errorf("cannot create Secret: %w", err)
+	}
+	return secret.Name, nil
+}
+
+func (s *SecretStore) Rotate(ctx context.Context, owner, ref string) error {
+	return s.deleteAll(ctx, owner, ref)
+}
+
+func (s *SecretStore) Delete(ctx context.Context, owner string) error {
+	return s.deleteAll(ctx, owner, "")
+}
+
+func (s *SecretStore) deleteAll(ctx context.Context, owner, except string) error {
+	var secretList v1.SecretList
+	err := s.client.List(ctx, &secretList, client.InNamespace(s.namespace), client.MatchingLabels{OwnerLabel: owner})
+	if err != nil {
+		return fmt.Errorf("cannot list Secrets: %w", err)
+	}
+
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if secret.Name == except {
+			continue
+		}
+		err = s.client.Delete(ctx, secret)
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("cannot delete Secret %s: %w", secret.Name, err)
+		}
+	}
+	return nil
+}
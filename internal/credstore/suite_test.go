@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCredStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "CredStore")
+}
+
+// testStore checks the behavior that all stores have in common.
+func testStore(newStore func() Store) {
+	var s Store
+
+	BeforeEach(func() {
+		s = newStore()
+	})
+
+	It("should get a stored password", func(ctx SpecContext) {
+		ref, err := s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref).To(HavePrefix("oob-0a0b0c0d0e0f"))
+		Expect(s.Get(ctx, ref)).To(Equal("secret"))
+	})
+
+	It("should report a missing password", func(ctx SpecContext) {
+		_, err := s.Get(ctx, "oob-0a0b0c0d0e0f/abcde")
+		Expect(err).To(MatchError(ErrNotFound))
+		_, err = s.Get(ctx, "oob-0a0b0c0d0e0f_abcde")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("should keep only the current password after a rotation", func(ctx SpecContext) {
+		prev, err := s.Put(ctx, "oob-0a0b0c0d0e0f", "previous")
+		Expect(err).NotTo(HaveOccurred())
+		var cur string
+		cur, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "current")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cur).NotTo(Equal(prev))
+		var other string
+		other, err = s.Put(ctx, "oob-0a0b0c0d0e0f0", "other")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Rotating the password")
+		Expect(s.Rotate(ctx, "oob-0a0b0c0d0e0f", cur)).To(Succeed())
+		_, err = s.Get(ctx, prev)
+		Expect(err).To(MatchError(ErrNotFound))
+		Expect(s.Get(ctx, cur)).To(Equal("current"))
+		Expect(s.Get(ctx, other)).To(Equal("other"))
+
+		By("Deleting all passwords")
+		Expect(s.Delete(ctx, "oob-0a0b0c0d0e0f")).To(Succeed())
+		_, err = s.Get(ctx, cur)
+		Expect(err).To(MatchError(ErrNotFound))
+		Expect(s.Get(ctx, other)).To(Equal("other"))
+	})
+}
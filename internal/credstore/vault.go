@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ironcore-dev/metal/internal/log"
+)
+
+// VaultStore stores every password in a secret of a Vault KV version 2 secrets engine, so that
+// passwords never reach the Kubernetes API. The reference of a password is the owner and a random
+// suffix, separated by a slash. All passwords of an owner are listed through the metadata of the
+// owner.
+type VaultStore struct {
+	client    *http.Client
+	address   string
+	mount     string
+	prefix    string
+	tokenFile string
+}
+
+// NewVaultStore returns a store for the secrets engine mounted at the first element of path. The
+// remaining elements of path are prepended to all references. The token is read from tokenFile for
+// every request, so that it can be short-lived and renewed by an agent.
+func NewVaultStore(address, path, tokenFile, caFile string) (*VaultStore, error) {
+	if address == "" {
+		return nil, fmt.Errorf("address cannot be empty")
+	}
+	mount, prefix, _ := strings.Cut(path, "/")
+	if mount == "" {
+		return nil, fmt.Errorf("mount cannot be empty")
+	}
+	if tokenFile == "" {
+		return nil, fmt.Errorf("token file cannot be empty")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("CA file %s contains no certificates", caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return &VaultStore{
+		client: &http.Client{
+			Transport: transport,
+		},
+		address:   strings.TrimSuffix(address, "/"),
+		mount:     mount,
+		prefix:    strings.Trim(prefix, "/"),
+		tokenFile: tokenFile,
+	}, nil
+}
+
+type vaultSecret struct {
+	Data struct {
+		Data struct {
+			Password string `json:"password"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+type vaultKeys struct {
+	Data struct {
+		Keys []string `json:"keys"`
+	} `json:"data"`
+}
+
+func (s *VaultStore) Get(ctx context.Context, ref string) (string, error) {
+	if !validVaultRef(ref) {
+		return "", fmt.Errorf("%w: invalid reference %s", ErrNotFound, ref)
+	}
+
+	var secret vaultSecret
+	found, err := s.do(ctx, http.MethodGet, "data", ref, nil, &secret)
+	if err != nil {
+		return "", err
+	}
+	if !found || secret.Data.Data.Password == "" {
+		return "", fmt.Errorf("%w: secret %s does not exist", ErrNotFound, ref)
+	}
+	return secret.Data.Data.Password, nil
+}
+
+// Put writes the password with a check-and-set version of zero, so that an existing secret is never
+// overwritten.
+func (s *VaultStore) Put(ctx context.Context, owner, password string) (string, error) {
+	suffix, err := newSuffix()
+	if err != nil {
+		return "", err
+	}
+	ref := owner + "/" + suffix
+	if !validVaultRef(ref) {
+		return "", fmt.Errorf("invalid owner %s", owner)
+	}
+
+	body := map[string]any{
+		"options": map[string]int{"cas": 0},
+		"data":    map[string]string{"password": password},
+	}
+	var found bool
+	found, err = s.do(ctx, http.MethodPost, "data", ref, body, nil)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("secrets engine %s does not exist", s.mount)
+	}
+	return ref, nil
+}
+
+func (s *VaultStore) Rotate(ctx context.Context, owner, ref string) error {
+	return s.deleteAll(ctx, owner, ref)
+}
+
+func (s *VaultStore) Delete(ctx context.Context, owner string) error {
+	return s.deleteAll(ctx, owner, "")
+}
+
+func (s *VaultStore) deleteAll(ctx context.Context, owner, except string) error {
+	if !validVaultRef(owner + "/x") {
+		return fmt.Errorf("invalid owner %s", owner)
+	}
+
+	var keys vaultKeys
+	found, err := s.do(ctx, "LIST", "metadata", owner+"/", nil, &keys)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	for _, k := range keys.Data.Keys {
+		ref := owner + "/" + k
+		if ref == except || !validVaultRef(ref) {
+			continue
+		}
+		log.Debug(ctx, "Deleting password", "ref", ref)
+		_, err = s.do(ctx, http.MethodDelete, "metadata", ref, nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do performs a request for a path below the prefix of the store. It returns false if the path does
+// not exist.
+func (s *VaultStore) do(ctx context.Context, method, api, path string, in, out any) (bool, error) {
+	token, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return false, fmt.Errorf("cannot read token file: %w", err)
+	}
+
+	elems := []string{"v1", s.mount, api}
+	if s.prefix != "" {
+		elems = append(elems, s.prefix)
+	}
+	var endpoint string
+	endpoint, err = url.JoinPath(s.address, append(elems, path)...)
+	if err != nil {
+		return false, fmt.Errorf("cannot build URL: %w", err)
+	}
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+
+	var body io.Reader
+	if in != nil {
+		var b []byte
+		b, err = json.Marshal(in)
+		if err != nil {
+			return false, fmt.Errorf("cannot encode request body: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return false, fmt.Errorf("cannot create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("cannot perform %s request: %w", method, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		return false, fmt.Errorf("%s request failed with status %s: %s", method, resp.Status, strings.Join(e.Errors, ", "))
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		err = json.NewDecoder(resp.Body).Decode(out)
+		if err != nil {
+			return false, fmt.Errorf("cannot decode response body: %w", err)
+		}
+	}
+	return true, nil
+}
+
+func validVaultRef(ref string) bool {
+	owner, suffix, ok := strings.Cut(ref, "/")
+	return ok && owner != "" && suffix != "" && owner != "." && owner != ".." && suffix != "." && suffix != ".." &&
+		!strings.ContainsAny(suffix, "/?#") && !strings.ContainsAny(owner, "?#")
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package credstore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vault Store", func() {
+	var v *vaultServer
+	var tokenFile string
+
+	BeforeEach(func() {
+		By("Starting a Vault server")
+		v = startVaultServer("secret", "token")
+		tokenFile = filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte("token\n"), 0o600)).To(Succeed())
+	})
+
+	testStore(func() Store {
+		s, err := Open("vault+"+v.URL+"/secret/metal?tokenFile="+tokenFile, nil, "")
+		Expect(err).NotTo(HaveOccurred())
+		return s
+	})
+
+	It("should store passwords below the prefix", func(ctx SpecContext) {
+		s, err := NewVaultStore(v.URL, "secret/metal", tokenFile, "")
+		Expect(err).NotTo(HaveOccurred())
+		var ref string
+		ref, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(v.paths()).To(ConsistOf("metal/" + ref))
+	})
+
+	It("should read the token for every request", func(ctx SpecContext) {
+		s, err := NewVaultStore(v.URL, "secret/metal", tokenFile, "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Replacing the token")
+		Expect(os.WriteFile(tokenFile, []byte("expired"), 0o600)).To(Succeed())
+		_, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).To(MatchError(ContainSubstring("permission denied")))
+	})
+
+	It("should report a missing secrets engine", func(ctx SpecContext) {
+		s, err := NewVaultStore(v.URL, "kv/metal", tokenFile, "")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = s.Put(ctx, "oob-0a0b0c0d0e0f", "secret")
+		Expect(err).To(MatchError(ContainSubstring("does not exist")))
+	})
+})
+
+// vaultServer is a local stand-in for the KV version 2 secrets engine of Vault, mounted at a single
+// path.
+type vaultServer struct {
+	*httptest.Server
+	mount   string
+	token   string
+	lock    sync.Mutex
+	secrets map[string]map[string]string
+}
+
+func startVaultServer(mount, token string) *vaultServer {
+	v := &vaultServer{
+		mount:   mount,
+		token:   token,
+		secrets: make(map[string]map[string]string),
+	}
+	v.Server = httptest.NewServer(http.HandlerFunc(v.serve))
+	DeferCleanup(v.Close)
+	return v
+}
+
+func (v *vaultServer) paths() []string {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	paths := make([]string, 0, len(v.secrets))
+	for p := range v.secrets {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (v *vaultServer) serve(w http.ResponseWriter, r *http.Request) {
+	defer GinkgoRecover()
+
+	if r.Header.Get("X-Vault-Token") != v.token {
+		writeVaultErrors(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	api, path, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/"+v.mount+"/"), "/")
+	if !ok || !strings.HasPrefix(r.URL.Path, "/v1/"+v.mount+"/") {
+		writeVaultErrors(w, http.StatusNotFound, "no handler for route")
+		return
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	switch {
+	case api == "data" && r.Method == http.MethodGet:
+		data, ok := v.secrets[path]
+		if !ok {
+			writeVaultErrors(w, http.StatusNotFound)
+			return
+		}
+		writeVaultJSON(w, map[string]any{"data": map[string]any{"data": data}})
+
+	case api == "data" && r.Method == http.MethodPost:
+		var body struct {
+			Options struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+			Data map[string]string `json:"data"`
+		}
+		Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		_, exists := v.secrets[path]
+		if body.Options.CAS != nil && *body.Options.CAS == 0 && exists {
+			writeVaultErrors(w, http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+		v.secrets[path] = body.Data
+		writeVaultJSON(w, map[string]any{"data": map[string]any{"version": 1}})
+
+	case api == "metadata" && r.Method == "LIST":
+		var keys []string
+		for p := range v.secrets {
+			k, ok := strings.CutPrefix(p, path)
+			if ok && k != "" && !strings.Contains(k, "/") {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			writeVaultErrors(w, http.StatusNotFound)
+			return
+		}
+		sort.Strings(keys)
+		writeVaultJSON(w, map[string]any{"data": map[string]any{"keys": keys}})
+
+	case api == "metadata" && r.Method == http.MethodDelete:
+		delete(v.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeVaultErrors(w, http.StatusMethodNotAllowed, "unsupported operation")
+	}
+}
+
+func writeVaultJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	Expect(json.NewEncoder(w).Encode(v)).To(Succeed())
+}
+
+func writeVaultErrors(w http.ResponseWriter, status int, errs ...string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	Expect(json.NewEncoder(w).Encode(map[string]any{"errors": append([]string{}, errs...)})).To(Succeed())
+}
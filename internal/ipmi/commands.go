@@ -0,0 +1,354 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+type ChassisControl byte
+
+const (
+	ChassisPowerDown    ChassisControl = 0x00
+	ChassisPowerUp      ChassisControl = 0x01
+	ChassisPowerCycle   ChassisControl = 0x02
+	ChassisHardReset    ChassisControl = 0x03
+	ChassisSoftShutdown ChassisControl = 0x05
+)
+
+const (
+	passwordOpDisable     byte = 0x00
+	passwordOpEnable      byte = 0x01
+	passwordOpSetPassword byte = 0x02
+
+	maxPassword = 20
+)
+
+const (
+	solParamEnable byte = 0x01
+
+	lanParamCipherSuiteEntryCount byte = 0x16
+	lanParamCipherSuiteEntries    byte = 0x17
+	lanParamCipherSuitePrivileges byte = 0x18
+
+	maxCipherSuiteEntries = 16
+)
+
+// DeviceID is the response to Get Device ID.
+type DeviceID struct {
+	DeviceID         byte
+	FirmwareMajor    byte
+	FirmwareMinor    byte
+	IPMIVersion      byte
+	ManufacturerID   uint32
+	ProductID        uint16
+	DeviceRevision   byte
+	DeviceAvailable  bool
+	AdditionalDevice byte
+}
+
+// FirmwareRevision formats the firmware revision like ipmitool does.
+func (d DeviceID) FirmwareRevision() string {
+	return fmt.Sprintf("%d.%02x", d.FirmwareMajor, d.FirmwareMinor)
+}
+
+func (s *Session) GetDeviceID(ctx context.Context) (DeviceID, error) {
+	data, err := s.Send(ctx, NetFnApp, CmdGetDeviceID, nil)
+	if err != nil {
+		return DeviceID{}, err
+	}
+	if len(data) < 11 {
+		return DeviceID{}, fmt.Errorf("device ID is too short: %d bytes", len(data))
+	}
+	return DeviceID{
+		DeviceID:         data[0],
+		DeviceRevision:   data[1] & 0x0f,
+		DeviceAvailable:  data[2]&0x80 == 0,
+		FirmwareMajor:    data[2] & 0x7f,
+		FirmwareMinor:    data[3],
+		IPMIVersion:      data[4],
+		AdditionalDevice: data[5],
+		ManufacturerID:   uint32(data[6]) | uint32(data[7])<<8 | uint32(data[8]&0x0f)<<16,
+		ProductID:        binary.LittleEndian.Uint16(data[9:11]),
+	}, nil
+}
+
+// GetSystemGUID returns the system GUID formatted as a UUID. The GUID is decoded like an SMBIOS UUID,
+// which most BMCs use, so that it matches the UUID reported by the firmware and by Redfish.
+func (s *Session) GetSystemGUID(ctx context.Context) (string, error) {
+	data, err := s.Send(ctx, NetFnApp, CmdGetSystemGUID, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < 16 {
+		return "", fmt.Errorf("system GUID is too short: %d bytes", len(data))
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(data[0:4]),
+		binary.LittleEndian.Uint16(data[4:6]),
+		binary.LittleEndian.Uint16(data[6:8]),
+		data[8:10],
+		data[10:16]), nil
+}
+
+// IdentifyState is the state of the chassis identify LED.
+type IdentifyState byte
+
+const (
+	IdentifyOff        IdentifyState = 0x00
+	IdentifyTemporary  IdentifyState = 0x01
+	IdentifyIndefinite IdentifyState = 0x02
+)
+
+// ChassisStatus is the response to Get Chassis Status.
+type ChassisStatus struct {
+	PowerOn bool
+	// IdentifySupported is set if the BMC reports the identify state.
+	IdentifySupported bool
+	Identify          IdentifyState
+}
+
+func (s *Session) GetChassisStatus(ctx context.Context) (ChassisStatus, error) {
+	data, err := s.Send(ctx, NetFnChassis, CmdGetChassisStatus, nil)
+	if err != nil {
+		return ChassisStatus{}, err
+	}
+	if len(data) < 3 {
+		return ChassisStatus{}, fmt.Errorf("chassis status is too short: %d bytes", len(data))
+	}
+	status := ChassisStatus{
+		PowerOn: data[0]&0x01 != 0,
+	}
+	// The misc chassis state holds the identify state if the BMC reports it.
+	if data[2]&0x40 != 0 {
+		status.IdentifySupported = true
+		status.Identify = IdentifyState(data[2] >> 4 & 0x03)
+	}
+	return status, nil
+}
+
+func (s *Session) ChassisControl(ctx context.Context, c ChassisControl) error {
+	_, err := s.Send(ctx, NetFnChassis, CmdChassisControl, []byte{byte(c)})
+	return err
+}
+
+// ChassisIdentify turns the identify LED on until it is turned off, or turns it off.
+func (s *Session) ChassisIdentify(ctx context.Context, on bool) error {
+	var force byte
+	if on {
+		force = 0x01
+	}
+	_, err := s.Send(ctx, NetFnChassis, CmdChassisIdentify, []byte{0x00, force})
+	return err
+}
+
+// ColdReset resets the BMC. The BMC usually resets before it answers, so a missing response is not
+// an error.
+func (s *Session) ColdReset(ctx context.Context) error {
+	_, err := s.Send(ctx, NetFnApp, CmdColdReset, nil)
+	if err != nil && !errors.Is(err, ErrNoResponse) {
+		return err
+	}
+	return nil
+}
+
+// UserAccess is the response to Get User Access.
+type UserAccess struct {
+	MaxUsers  byte
+	Enabled   bool
+	Privilege PrivilegeLevel
+}
+
+func (s *Session) GetUserAccess(ctx context.Context, id byte) (UserAccess, error) {
+	data, err := s.Send(ctx, NetFnApp, CmdGetUserAccess, []byte{currentChannel, id})
+	if err != nil {
+		return UserAccess{}, err
+	}
+	if len(data) < 4 {
+		return UserAccess{}, fmt.Errorf("user access is too short: %d bytes", len(data))
+	}
+	return UserAccess{
+		MaxUsers:  data[0] & 0x3f,
+		Enabled:   data[1]>>6 == 0x01,
+		Privilege: PrivilegeLevel(data[3] & 0x0f),
+	}, nil
+}
+
+// SetUserAccess enables link authentication and IPMI messaging for a user on the current channel and
+// sets its privilege limit.
+func (s *Session) SetUserAccess(ctx context.Context, id byte, privilege PrivilegeLevel) error {
+	// Bit 7 enables changing bits 6 to 4: call-in is allowed, link authentication and IPMI messaging
+	// are enabled.
+	_, err := s.Send(ctx, NetFnApp, CmdSetUserAccess, []byte{0x80 | 0x20 | 0x10 | currentChannel, id, byte(privilege)})
+	return err
+}
+
+func (s *Session) GetUserName(ctx context.Context, id byte) (string, error) {
+	data, err := s.Send(ctx, NetFnApp, CmdGetUserName, []byte{id})
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(data, "\x00")), nil
+}
+
+func (s *Session) SetUserName(ctx context.Context, id byte, name string) error {
+	if len(name) > maxUsername {
+		return fmt.Errorf("username is longer than %d bytes", maxUsername)
+	}
+	data := make([]byte, 1+maxUsername)
+	data[0] = id
+	copy(data[1:], name)
+	_, err := s.Send(ctx, NetFnApp, CmdSetUserName, data)
+	return err
+}
+
+// SetUserPassword sets a password of up to 20 bytes.
+func (s *Session) SetUserPassword(ctx context.Context, id byte, password string) error {
+	if len(password) > maxPassword {
+		return fmt.Errorf("password is longer than %d bytes", maxPassword)
+	}
+	size := 16
+	if len(password) > 16 {
+		size = maxPassword
+		// Bit 7 selects a 20 byte password.
+		id |= 0x80
+	}
+	data := make([]byte, 2+size)
+	data[0] = id
+	data[1] = passwordOpSetPassword
+	copy(data[2:], password)
+	_, err := s.Send(ctx, NetFnApp, CmdSetUserPassword, data)
+	return err
+}
+
+func (s *Session) EnableUser(ctx context.Context, id byte, enable bool) error {
+	op := passwordOpDisable
+	if enable {
+		op = passwordOpEnable
+	}
+	_, err := s.Send(ctx, NetFnApp, CmdSetUserPassword, []byte{id, op})
+	return err
+}
+
+// ReadFRU reads the whole FRU inventory area of a device.
+func (s *Session) ReadFRU(ctx context.Context, id byte) ([]byte, error) {
+	data, err := s.Send(ctx, NetFnStorage, CmdGetFRUInventoryAreaInfo, []byte{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 3 {
+		return nil, fmt.Errorf("FRU inventory area info is too short: %d bytes", len(data))
+	}
+	size := int(binary.LittleEndian.Uint16(data[0:2]))
+	if data[2]&0x01 != 0 {
+		return nil, fmt.Errorf("FRU devices accessed by words are not supported")
+	}
+
+	// Small reads keep the response within the limits of all BMCs.
+	const chunk = 32
+	fru := make([]byte, 0, size)
+	for len(fru) < size {
+		n := min(chunk, size-len(fru))
+		req := []byte{id}
+		req = binary.LittleEndian.AppendUint16(req, uint16(len(fru)))
+		req = append(req, byte(n))
+		data, err = s.Send(ctx, NetFnStorage, CmdReadFRUData, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < 1 || int(data[0]) == 0 || len(data) < 1+int(data[0]) {
+			return nil, fmt.Errorf("FRU data is invalid")
+		}
+		fru = append(fru, data[1:1+int(data[0])]...)
+	}
+	return fru, nil
+}
+
+// GetSOLEnabled reads the SOL Enable configuration parameter of the current channel.
+func (s *Session) GetSOLEnabled(ctx context.Context) (bool, error) {
+	data, err := s.Send(ctx, NetFnTransport, CmdGetSOLConfigurationParameters, []byte{currentChannel, solParamEnable, 0x00, 0x00})
+	if err != nil {
+		return false, err
+	}
+	if len(data) < 2 {
+		return false, fmt.Errorf("SOL configuration parameter is too short: %d bytes", len(data))
+	}
+	return data[1]&0x01 != 0, nil
+}
+
+// CipherSuitePrivilege is a cipher suite entry of the LAN channel with the maximum privilege level
+// a session using it may have. PrivilegeUnspecified means the cipher suite is disabled.
+type CipherSuitePrivilege struct {
+	ID           int
+	MaxPrivilege PrivilegeLevel
+}
+
+func (s *Session) getLANConfig(ctx context.Context, param byte) ([]byte, error) {
+	data, err := s.Send(ctx, NetFnTransport, CmdGetLANConfigurationParameters, []byte{currentChannel, param, 0x00, 0x00})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1 {
+		return nil, fmt.Errorf("LAN configuration parameter 0x%02x is too short: %d bytes", param, len(data))
+	}
+	// The first byte is the parameter revision.
+	return data[1:], nil
+}
+
+// GetCipherSuitePrivileges reads the cipher suite entries of the LAN channel and their maximum
+// privilege levels.
+func (s *Session) GetCipherSuitePrivileges(ctx context.Context) ([]CipherSuitePrivilege, error) {
+	count, err := s.getLANConfig(ctx, lanParamCipherSuiteEntryCount)
+	if err != nil {
+		return nil, err
+	}
+	if len(count) < 1 {
+		return nil, fmt.Errorf("cipher suite entry count is missing")
+	}
+	n := min(int(count[0]&0x1f), maxCipherSuiteEntries)
+
+	entries, err := s.getLANConfig(ctx, lanParamCipherSuiteEntries)
+	if err != nil {
+		return nil, err
+	}
+	privs, err := s.getLANConfig(ctx, lanParamCipherSuitePrivileges)
+	if err != nil {
+		return nil, err
+	}
+	// Both parameters start with a reserved byte. Privilege levels are packed two per byte, the
+	// first entry in the low nibble.
+	if len(entries) < 1+n || len(privs) < 1+(n+1)/2 {
+		return nil, fmt.Errorf("cipher suite entries are too short")
+	}
+
+	csps := make([]CipherSuitePrivilege, n)
+	for i := range csps {
+		csps[i] = CipherSuitePrivilege{
+			ID:           int(entries[1+i]),
+			MaxPrivilege: PrivilegeLevel(privs[1+i/2] >> (4 * (i % 2)) & 0x0f),
+		}
+	}
+	return csps, nil
+}
+
+// SetCipherSuitePrivileges sets the maximum privilege levels of the cipher suite entries of the LAN
+// channel. The entries must be in the order returned by GetCipherSuitePrivileges.
+func (s *Session) SetCipherSuitePrivileges(ctx context.Context, csps []CipherSuitePrivilege) error {
+	if len(csps) > maxCipherSuiteEntries {
+		return fmt.Errorf("too many cipher suite entries: %d", len(csps))
+	}
+
+	data := make([]byte, 3+maxCipherSuiteEntries/2)
+	data[0] = currentChannel
+	data[1] = lanParamCipherSuitePrivileges
+	for i, csp := range csps {
+		data[3+i/2] |= byte(csp.MaxPrivilege&0x0f) << (4 * (i % 2))
+	}
+	_, err := s.Send(ctx, NetFnTransport, CmdSetLANConfigurationParameters, data)
+	return err
+}
@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	fruEndOfFields byte = 0xc1
+
+	fruTypeBCDPlus   byte = 0x01
+	fruType6BitASCII byte = 0x02
+	fruType8BitASCII byte = 0x03
+)
+
+// FRU holds the board and product fields of a FRU inventory area.
+type FRU struct {
+	BoardManufacturer   string
+	BoardProductName    string
+	BoardSerialNumber   string
+	BoardPartNumber     string
+	ProductManufacturer string
+	ProductName         string
+	ProductPartNumber   string
+	ProductVersion      string
+	ProductSerialNumber string
+}
+
+// ParseFRU parses the common header and the board and product areas of a FRU inventory area as
+// defined in the Platform Management FRU Information Storage Definition v1.0.
+func ParseFRU(b []byte) (FRU, error) {
+	if len(b) < 8 {
+		return FRU{}, fmt.Errorf("FRU is too short: %d bytes", len(b))
+	}
+	if b[0]&0x0f != 0x01 {
+		return FRU{}, fmt.Errorf("FRU has an unsupported format version: 0x%02x", b[0])
+	}
+	if checksum(b[:7]) != b[7] {
+		return FRU{}, fmt.Errorf("FRU common header has an invalid checksum")
+	}
+
+	var fru FRU
+	if b[3] != 0 {
+		// Board area: version, length, language, manufacturing date (3 bytes), then the fields.
+		fields, err := fruAreaFields(b, int(b[3])*8, 6)
+		if err != nil {
+			return FRU{}, fmt.Errorf("cannot parse board area: %w", err)
+		}
+		fru.BoardManufacturer = fieldAt(fields, 0)
+		fru.BoardProductName = fieldAt(fields, 1)
+		fru.BoardSerialNumber = fieldAt(fields, 2)
+		fru.BoardPartNumber = fieldAt(fields, 3)
+	}
+	if b[4] != 0 {
+		// Product area: version, length, language, then the fields.
+		fields, err := fruAreaFields(b, int(b[4])*8, 3)
+		if err != nil {
+			return FRU{}, fmt.Errorf("cannot parse product area: %w", err)
+		}
+		fru.ProductManufacturer = fieldAt(fields, 0)
+		fru.ProductName = fieldAt(fields, 1)
+		fru.ProductPartNumber = fieldAt(fields, 2)
+		fru.ProductVersion = fieldAt(fields, 3)
+		fru.ProductSerialNumber = fieldAt(fields, 4)
+	}
+	return fru, nil
+}
+
+func fieldAt(fields []string, i int) string {
+	if i >= len(fields) {
+		return ""
+	}
+	return fields[i]
+}
+
+func fruAreaFields(b []byte, offset, skip int) ([]string, error) {
+	if offset+2 > len(b) {
+		return nil, fmt.Errorf("area is out of bounds")
+	}
+	end := offset + int(b[offset+1])*8
+	if end > len(b) || end < offset+skip {
+		return nil, fmt.Errorf("area is out of bounds")
+	}
+	if checksum(b[offset:end-1]) != b[end-1] {
+		return nil, fmt.Errorf("area has an invalid checksum")
+	}
+
+	var fields []string
+	for i := offset + skip; i < end-1 && b[i] != fruEndOfFields; {
+		typ := b[i] >> 6
+		n := int(b[i] & 0x3f)
+		i++
+		if i+n > end-1 {
+			return nil, fmt.Errorf("field is out of bounds")
+		}
+		fields = append(fields, decodeFRUField(typ, b[i:i+n]))
+		i += n
+	}
+	return fields, nil
+}
+
+func decodeFRUField(typ byte, b []byte) string {
+	switch typ {
+	case fruType8BitASCII:
+		return strings.TrimSpace(string(b))
+	case fruType6BitASCII:
+		// Characters are packed into 6 bits each, least significant bits first.
+		var s strings.Builder
+		for bit := 0; bit+6 <= len(b)*8; bit += 6 {
+			v := uint16(b[bit/8])
+			if bit/8+1 < len(b) {
+				v |= uint16(b[bit/8+1]) << 8
+			}
+			s.WriteByte(byte(v>>(bit%8))&0x3f + 0x20)
+		}
+		return strings.TrimSpace(s.String())
+	case fruTypeBCDPlus:
+		const digits = "0123456789 -.???"
+		var s strings.Builder
+		for _, c := range b {
+			s.WriteByte(digits[c>>4])
+			s.WriteByte(digits[c&0x0f])
+		}
+		return strings.TrimSpace(s.String())
+	default:
+		return hex.EncodeToString(b)
+	}
+}
+
+// encodeFRU encodes board and product areas with 8-bit ASCII fields.
+func encodeFRU(fru FRU) []byte {
+	board := fruArea([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00},
+		fru.BoardManufacturer, fru.BoardProductName, fru.BoardSerialNumber, fru.BoardPartNumber, "")
+	product := fruArea([]byte{0x01, 0x00, 0x00},
+		fru.ProductManufacturer, fru.ProductName, fru.ProductPartNumber, fru.ProductVersion, fru.ProductSerialNumber, "", "")
+
+	header := []byte{0x01, 0x00, 0x00, 0x01, byte(1 + len(board)/8), 0x00, 0x00}
+	header = append(header, checksum(header))
+	b := append(header, board...)
+	return append(b, product...)
+}
+
+func fruArea(head []byte, fields ...string) []byte {
+	b := head
+	for _, f := range fields {
+		b = append(b, fruType8BitASCII<<6|byte(len(f)))
+		b = append(b, f...)
+	}
+	b = append(b, fruEndOfFields)
+	for (len(b)+1)%8 != 0 {
+		b = append(b, 0x00)
+	}
+	b[1] = byte((len(b) + 1) / 8)
+	return append(b, checksum(b))
+}
@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FRU", func() {
+	It("should decode field types", func() {
+		Expect(decodeFRUField(fruType8BitASCII, []byte("Vendor "))).To(Equal("Vendor"))
+		Expect(decodeFRUField(fruType6BitASCII, []byte{0x29, 0xdc, 0xa6})).To(Equal("IPMI"))
+		Expect(decodeFRUField(fruTypeBCDPlus, []byte{0x12, 0xb3})).To(Equal("12-3"))
+	})
+
+	It("should reject a corrupted FRU", func() {
+		b := encodeFRU(FRU{ProductName: "Server"})
+		b[len(b)-2] ^= 0xff
+		_, err := ParseFRU(b)
+		Expect(err).To(MatchError(ContainSubstring("invalid checksum")))
+	})
+})
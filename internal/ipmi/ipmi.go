@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ipmi implements IPMI v2.0 over LAN (RMCP+) and the commands needed to manage a BMC.
+package ipmi
+
+import (
+	"errors"
+	"fmt"
+)
+
+type NetFn byte
+
+const (
+	NetFnChassis   NetFn = 0x00
+	NetFnApp       NetFn = 0x06
+	NetFnStorage   NetFn = 0x0a
+	NetFnTransport NetFn = 0x0c
+)
+
+type Command byte
+
+const (
+	CmdGetChassisStatus Command = 0x01
+	CmdChassisControl   Command = 0x02
+	CmdChassisIdentify  Command = 0x04
+
+	CmdGetDeviceID                Command = 0x01
+	CmdColdReset                  Command = 0x02
+	CmdGetSystemGUID              Command = 0x37
+	CmdGetChannelAuthCapabilities Command = 0x38
+	CmdSetSessionPrivilegeLevel   Command = 0x3b
+	CmdCloseSession               Command = 0x3c
+	CmdSetUserAccess              Command = 0x43
+	CmdGetUserAccess              Command = 0x44
+	CmdSetUserName                Command = 0x45
+	CmdGetUserName                Command = 0x46
+	CmdSetUserPassword            Command = 0x47
+	CmdGetChannelCipherSuites     Command = 0x54
+
+	CmdGetFRUInventoryAreaInfo Command = 0x10
+	CmdReadFRUData             Command = 0x11
+
+	CmdSetLANConfigurationParameters Command = 0x01
+	CmdGetLANConfigurationParameters Command = 0x02
+	CmdGetSOLConfigurationParameters Command = 0x22
+)
+
+type PrivilegeLevel byte
+
+const (
+	// PrivilegeUnspecified disables a cipher suite entry of a channel.
+	PrivilegeUnspecified   PrivilegeLevel = 0x00
+	PrivilegeCallback      PrivilegeLevel = 0x01
+	PrivilegeUser          PrivilegeLevel = 0x02
+	PrivilegeOperator      PrivilegeLevel = 0x03
+	PrivilegeAdministrator PrivilegeLevel = 0x04
+)
+
+const (
+	completionCodeOK byte = 0x00
+
+	bmcAddress           byte = 0x20
+	remoteConsoleAddress byte = 0x81
+	// currentChannel addresses the channel a request was received on.
+	currentChannel byte = 0x0e
+)
+
+var (
+	// ErrAuthentication is returned if the BMC rejects the credentials.
+	ErrAuthentication = errors.New("IPMI authentication failed")
+	// ErrNoResponse is returned if the BMC does not answer within the timeout.
+	ErrNoResponse = errors.New("no response from IPMI BMC")
+)
+
+// CompletionCodeError is returned if the BMC completes a command with an error.
+type CompletionCodeError struct {
+	NetFn NetFn
+	Cmd   Command
+	Code  byte
+}
+
+func (e *CompletionCodeError) Error() string {
+	return fmt.Sprintf("IPMI command 0x%02x/0x%02x completed with code 0x%02x", byte(e.NetFn), byte(e.Cmd), e.Code)
+}
+
+// message is an IPMI request or response as carried in a LAN packet.
+type message struct {
+	rsAddr byte
+	netFn  NetFn
+	rqAddr byte
+	rqSeq  byte
+	cmd    Command
+	data   []byte
+}
+
+func (m *message) encode() []byte {
+	b := []byte{m.rsAddr, byte(m.netFn) << 2}
+	b = append(b, checksum(b))
+	body := append([]byte{m.rqAddr, m.rqSeq << 2, byte(m.cmd)}, m.data...)
+	b = append(b, body...)
+	return append(b, checksum(body))
+}
+
+func decodeMessage(b []byte) (*message, error) {
+	if len(b) < 7 {
+		return nil, fmt.Errorf("message is too short: %d bytes", len(b))
+	}
+	if checksum(b[:2]) != b[2] || checksum(b[3:len(b)-1]) != b[len(b)-1] {
+		return nil, fmt.Errorf("message has an invalid checksum")
+	}
+	return &message{
+		rsAddr: b[0],
+		netFn:  NetFn(b[1] >> 2),
+		rqAddr: b[3],
+		rqSeq:  b[4] >> 2,
+		cmd:    Command(b[5]),
+		data:   b[6 : len(b)-1],
+	}, nil
+}
+
+func checksum(b []byte) byte {
+	var sum byte
+	for _, c := range b {
+		sum += c
+	}
+	return -sum
+}
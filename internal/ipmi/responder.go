@@ -0,0 +1,669 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+const (
+	completionCodeInvalidCommand byte = 0xc1
+	completionCodeInvalidData    byte = 0xcc
+	completionCodeOutOfRange     byte = 0xc9
+	// completionCodeParameterNotSupported is specific to configuration parameter commands.
+	completionCodeParameterNotSupported byte = 0x80
+
+	responderMaxUsers = 10
+)
+
+// Responder is a minimal in-process BMC that answers IPMI v2.0 over LAN. It implements the commands
+// of this package and is meant for tests.
+type Responder struct {
+	conn net.PacketConn
+
+	mtx      sync.Mutex
+	suites   []CipherSuitePrivilege
+	users    [responderMaxUsers]ResponderUser
+	guid     []byte
+	deviceID DeviceID
+	fru      []byte
+	powerOn  bool
+	identify IdentifyState
+	noIdent  bool
+	sol      bool
+	insecure bool
+	requests []ResponderRequest
+	sessions map[uint32]*responderSession
+}
+
+// ResponderUser is a user slot of a Responder.
+type ResponderUser struct {
+	Name      string
+	Password  string
+	Enabled   bool
+	Privilege PrivilegeLevel
+}
+
+// ResponderRequest is a request received by a Responder within a session.
+type ResponderRequest struct {
+	NetFn NetFn
+	Cmd   Command
+	Data  []byte
+}
+
+type responderSession struct {
+	consoleSID uint32
+	managedSID uint32
+	suite      CipherSuite
+	rakp       *rakp
+	user       int
+	keys       *keys
+	seq        uint32
+}
+
+// NewResponder starts a responder on a random local UDP port. It supports all cipher suites of this
+// package and has an administrator in user slot 2.
+func NewResponder(username, password string) (*Responder, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Responder{
+		conn:   conn,
+		suites: cipherSuitePrivileges(SupportedCipherSuites()),
+		guid:   []byte{0x67, 0x45, 0x23, 0x01, 0xab, 0x89, 0xef, 0xcd, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		deviceID: DeviceID{
+			DeviceID:      0x20,
+			FirmwareMajor: 1,
+			FirmwareMinor: 0x23,
+			IPMIVersion:   0x02,
+		},
+		fru:      encodeFRU(FRU{}),
+		sol:      true,
+		sessions: make(map[uint32]*responderSession),
+	}
+	r.users[1] = ResponderUser{
+		Name:      username,
+		Password:  password,
+		Enabled:   true,
+		Privilege: PrivilegeAdministrator,
+	}
+	go r.serve()
+	return r, nil
+}
+
+// Addr returns the address the responder listens on.
+func (r *Responder) Addr() string {
+	return r.conn.LocalAddr().String()
+}
+
+// Port returns the port the responder listens on.
+func (r *Responder) Port() int {
+	_, port, _ := net.SplitHostPort(r.Addr())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+func (r *Responder) Close() error {
+	return r.conn.Close()
+}
+
+// SetCipherSuites sets the cipher suite entries of the responder and enables them for
+// administrators. Entries may include cipher suites this package does not implement.
+func (r *Responder) SetCipherSuites(ids ...int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.suites = cipherSuitePrivileges(ids)
+}
+
+// SetInsecureReplies makes the responder answer requests within sessions without integrity and
+// confidentiality, like a forged reply would.
+func (r *Responder) SetInsecureReplies(insecure bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.insecure = insecure
+}
+
+// CipherSuites returns the cipher suite entries of the responder.
+func (r *Responder) CipherSuites() []CipherSuitePrivilege {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return slices.Clone(r.suites)
+}
+
+func cipherSuitePrivileges(ids []int) []CipherSuitePrivilege {
+	csps := make([]CipherSuitePrivilege, 0, len(ids))
+	for _, id := range ids {
+		csps = append(csps, CipherSuitePrivilege{ID: id, MaxPrivilege: PrivilegeAdministrator})
+	}
+	return csps
+}
+
+// SetSystemGUID sets the raw system GUID.
+func (r *Responder) SetSystemGUID(guid [16]byte) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.guid = guid[:]
+}
+
+func (r *Responder) SetDeviceID(id DeviceID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.deviceID = id
+}
+
+func (r *Responder) SetFRU(fru FRU) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.fru = encodeFRU(fru)
+}
+
+func (r *Responder) SetPower(on bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.powerOn = on
+}
+
+func (r *Responder) Power() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.powerOn
+}
+
+// SetIdentifySupported controls whether the responder reports the chassis identify state.
+func (r *Responder) SetIdentifySupported(supported bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.noIdent = !supported
+}
+
+// Identify returns the chassis identify state.
+func (r *Responder) Identify() IdentifyState {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.identify
+}
+
+func (r *Responder) SetSOL(enabled bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.sol = enabled
+}
+
+// Users returns the user slots, starting with user ID 1.
+func (r *Responder) Users() []ResponderUser {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return slices.Clone(r.users[:])
+}
+
+// Requests returns the requests received within sessions.
+func (r *Responder) Requests() []ResponderRequest {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return slices.Clone(r.requests)
+}
+
+func (r *Responder) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		resp := r.handle(bytes.Clone(buf[:n]))
+		if resp != nil {
+			_, _ = r.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+func (r *Responder) handle(b []byte) []byte {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	p, err := decodePacket(b, func(sid uint32) *keys {
+		s, ok := r.sessions[sid]
+		if !ok {
+			return nil
+		}
+		return s.keys
+	})
+	if err != nil {
+		return nil
+	}
+
+	if p.authType == authTypeNone {
+		m, err := decodeMessage(p.payload)
+		if err != nil || m.netFn != NetFnApp {
+			return nil
+		}
+		var data []byte
+		switch m.cmd {
+		case CmdGetChannelAuthCapabilities:
+			data = r.channelAuthCapabilities()
+		case CmdGetChannelCipherSuites:
+			data = r.channelCipherSuites(m.data)
+		default:
+			return nil
+		}
+		resp := &packet{
+			authType: authTypeNone,
+			payload:  r.response(m, data).encode(),
+		}
+		out, _ := resp.encode(nil)
+		return out
+	}
+
+	switch p.payloadType {
+	case payloadTypeOpenSessionRequest:
+		return r.openSession(p.payload)
+	case payloadTypeRAKP1:
+		return r.rakp1(p.payload)
+	case payloadTypeRAKP3:
+		return r.rakp3(p.payload)
+	case payloadTypeIPMI:
+		return r.ipmi(p)
+	default:
+		return nil
+	}
+}
+
+func (r *Responder) reply(s *responderSession, payloadType byte, payload []byte) []byte {
+	p := &packet{
+		authType:    authTypeRMCPP,
+		payloadType: payloadType,
+		payload:     payload,
+	}
+	var k *keys
+	if payloadType == payloadTypeIPMI {
+		s.seq++
+		p.sessionID = s.consoleSID
+		p.seq = s.seq
+		if !r.insecure {
+			k = s.keys
+		}
+	}
+	out, err := p.encode(k)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func (r *Responder) openSession(b []byte) []byte {
+	if len(b) < 32 {
+		return nil
+	}
+	auth, integrity, confidentiality, err := parseAlgorithmPayloads(b[8:32])
+	if err != nil {
+		return nil
+	}
+
+	resp := []byte{b[0], 0x00, byte(PrivilegeAdministrator), 0x00}
+	resp = append(resp, b[4:8]...)
+	i := slices.IndexFunc(cipherSuites, func(s CipherSuite) bool {
+		return s.auth == auth && s.integrity == integrity && s.confidentiality == confidentiality
+	})
+	if i < 0 || !slices.ContainsFunc(r.suites, func(csp CipherSuitePrivilege) bool {
+		return csp.ID == cipherSuites[i].ID && csp.MaxPrivilege != PrivilegeUnspecified
+	}) {
+		resp[1] = 0x11
+		return r.reply(nil, payloadTypeOpenSessionResponse, resp)
+	}
+
+	sid, err := randomBytes(4)
+	if err != nil {
+		return nil
+	}
+	s := &responderSession{
+		consoleSID: binary.LittleEndian.Uint32(b[4:8]),
+		managedSID: binary.LittleEndian.Uint32(sid) | 1,
+		suite:      cipherSuites[i],
+	}
+	r.sessions[s.managedSID] = s
+
+	resp = append(resp, le32(s.managedSID)...)
+	resp = append(resp, s.suite.algorithmPayloads()...)
+	return r.reply(s, payloadTypeOpenSessionResponse, resp)
+}
+
+func (r *Responder) rakp1(b []byte) []byte {
+	if len(b) < 28 || len(b) < 28+int(b[27]) {
+		return nil
+	}
+	s, ok := r.sessions[binary.LittleEndian.Uint32(b[4:8])]
+	if !ok {
+		return nil
+	}
+
+	resp := []byte{b[0], 0x00, 0x00, 0x00}
+	resp = append(resp, le32(s.consoleSID)...)
+	username := string(b[28 : 28+int(b[27])])
+	s.user = slices.IndexFunc(r.users[:], func(u ResponderUser) bool {
+		return u.Enabled && u.Name == username
+	})
+	if s.user < 0 {
+		resp[1] = 0x0d
+		return r.reply(s, payloadTypeRAKP2, resp)
+	}
+
+	managedRandom, err := randomBytes(16)
+	if err != nil {
+		return nil
+	}
+	s.rakp = &rakp{
+		suite:         s.suite,
+		consoleSID:    s.consoleSID,
+		managedSID:    s.managedSID,
+		consoleRandom: slices.Clone(b[8:24]),
+		managedRandom: managedRandom,
+		managedGUID:   slices.Clone(r.guid),
+		role:          b[24],
+		username:      []byte(username),
+		password:      []byte(r.users[s.user].Password),
+	}
+	resp = append(resp, s.rakp.managedRandom...)
+	resp = append(resp, s.rakp.managedGUID...)
+	resp = append(resp, s.rakp.rakp2AuthCode()...)
+	return r.reply(s, payloadTypeRAKP2, resp)
+}
+
+func (r *Responder) rakp3(b []byte) []byte {
+	if len(b) < 8 {
+		return nil
+	}
+	s, ok := r.sessions[binary.LittleEndian.Uint32(b[4:8])]
+	if !ok || s.rakp == nil {
+		return nil
+	}
+
+	resp := []byte{b[0], 0x00, 0x00, 0x00}
+	resp = append(resp, le32(s.consoleSID)...)
+	if !hmac.Equal(s.rakp.rakp3AuthCode(), b[8:]) {
+		resp[1] = 0x0f
+		delete(r.sessions, s.managedSID)
+		return r.reply(s, payloadTypeRAKP4, resp)
+	}
+
+	resp = append(resp, s.rakp.rakp4ICV()...)
+	out := r.reply(s, payloadTypeRAKP4, resp)
+	s.keys = s.rakp.keys()
+	return out
+}
+
+func (r *Responder) ipmi(p *packet) []byte {
+	s, ok := r.sessions[p.sessionID]
+	if !ok || s.keys == nil {
+		return nil
+	}
+	m, err := decodeMessage(p.payload)
+	if err != nil {
+		return nil
+	}
+	r.requests = append(r.requests, ResponderRequest{
+		NetFn: m.netFn,
+		Cmd:   m.cmd,
+		Data:  slices.Clone(m.data),
+	})
+
+	data := r.command(s, m)
+	out := r.reply(s, payloadTypeIPMI, r.response(m, data).encode())
+	if m.netFn == NetFnApp && m.cmd == CmdCloseSession {
+		delete(r.sessions, s.managedSID)
+	}
+	return out
+}
+
+func (r *Responder) response(m *message, data []byte) *message {
+	return &message{
+		rsAddr: m.rqAddr,
+		netFn:  m.netFn + 1,
+		rqAddr: m.rsAddr,
+		rqSeq:  m.rqSeq,
+		cmd:    m.cmd,
+		data:   data,
+	}
+}
+
+func (r *Responder) channelAuthCapabilities() []byte {
+	// Channel 1, IPMI v2.0 extended data, no v1.5 authentication types, IPMI v2.0 connections
+	// supported.
+	return []byte{completionCodeOK, 0x01, 0x80, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00}
+}
+
+func (r *Responder) channelCipherSuites(req []byte) []byte {
+	if len(req) < 3 || req[1] != payloadTypeIPMI || req[2]&0x80 == 0 {
+		return []byte{completionCodeInvalidData}
+	}
+	var records []byte
+	for _, csp := range r.suites {
+		records = append(records, cipherSuiteRecord, byte(csp.ID))
+		if s, err := cipherSuiteByID(csp.ID); err == nil {
+			records = append(records, byte(s.auth), 0x40|byte(s.integrity), 0x80|byte(s.confidentiality))
+		}
+	}
+	offset := int(req[2]&0x3f) * 16
+	end := min(offset+16, len(records))
+	resp := []byte{completionCodeOK, 0x01}
+	if offset < end {
+		resp = append(resp, records[offset:end]...)
+	}
+	return resp
+}
+
+// command executes a request and returns the completion code and response data.
+func (r *Responder) command(s *responderSession, m *message) []byte {
+	cc := func(code byte) []byte {
+		return []byte{code}
+	}
+	ok := func(data ...byte) []byte {
+		return append([]byte{completionCodeOK}, data...)
+	}
+	user := func(id byte) *ResponderUser {
+		id &= 0x3f
+		if id < 1 || int(id) > len(r.users) {
+			return nil
+		}
+		return &r.users[id-1]
+	}
+
+	switch {
+	case m.netFn == NetFnApp && m.cmd == CmdGetDeviceID:
+		d := r.deviceID
+		return ok(d.DeviceID, d.DeviceRevision, d.FirmwareMajor&0x7f, d.FirmwareMinor, d.IPMIVersion, d.AdditionalDevice,
+			byte(d.ManufacturerID), byte(d.ManufacturerID>>8), byte(d.ManufacturerID>>16),
+			byte(d.ProductID), byte(d.ProductID>>8))
+	case m.netFn == NetFnApp && m.cmd == CmdColdReset:
+		return ok()
+	case m.netFn == NetFnApp && m.cmd == CmdGetSystemGUID:
+		return ok(r.guid...)
+	case m.netFn == NetFnApp && m.cmd == CmdGetChannelAuthCapabilities:
+		return r.channelAuthCapabilities()
+	case m.netFn == NetFnApp && m.cmd == CmdSetSessionPrivilegeLevel:
+		if len(m.data) < 1 || PrivilegeLevel(m.data[0]) > r.users[s.user].Privilege {
+			return cc(completionCodeInvalidData)
+		}
+		return ok(m.data[0])
+	case m.netFn == NetFnApp && m.cmd == CmdCloseSession:
+		return ok()
+	case m.netFn == NetFnApp && m.cmd == CmdGetUserAccess:
+		if len(m.data) < 2 {
+			return cc(completionCodeInvalidData)
+		}
+		u := user(m.data[1])
+		if u == nil {
+			return cc(completionCodeOutOfRange)
+		}
+		var enabled byte
+		status := byte(0x02)
+		for _, o := range r.users {
+			if o.Enabled {
+				enabled++
+			}
+		}
+		if u.Enabled {
+			status = 0x01
+		}
+		return ok(byte(len(r.users)), status<<6|enabled, 0x01, 0x30|byte(u.Privilege))
+	case m.netFn == NetFnApp && m.cmd == CmdSetUserAccess:
+		if len(m.data) < 3 {
+			return cc(completionCodeInvalidData)
+		}
+		u := user(m.data[1])
+		if u == nil {
+			return cc(completionCodeOutOfRange)
+		}
+		u.Privilege = PrivilegeLevel(m.data[2] & 0x0f)
+		return ok()
+	case m.netFn == NetFnApp && m.cmd == CmdGetUserName:
+		if len(m.data) < 1 {
+			return cc(completionCodeInvalidData)
+		}
+		u := user(m.data[0])
+		if u == nil {
+			return cc(completionCodeOutOfRange)
+		}
+		name := make([]byte, maxUsername)
+		copy(name, u.Name)
+		return ok(name...)
+	case m.netFn == NetFnApp && m.cmd == CmdSetUserName:
+		if len(m.data) != 1+maxUsername {
+			return cc(completionCodeInvalidData)
+		}
+		u := user(m.data[0])
+		if u == nil || m.data[0] == 1 {
+			return cc(completionCodeOutOfRange)
+		}
+		u.Name = string(bytes.TrimRight(m.data[1:], "\x00"))
+		return ok()
+	case m.netFn == NetFnApp && m.cmd == CmdSetUserPassword:
+		if len(m.data) < 2 {
+			return cc(completionCodeInvalidData)
+		}
+		u := user(m.data[0])
+		if u == nil {
+			return cc(completionCodeOutOfRange)
+		}
+		switch m.data[1] & 0x03 {
+		case passwordOpDisable:
+			u.Enabled = false
+		case passwordOpEnable:
+			u.Enabled = true
+		case passwordOpSetPassword:
+			u.Password = string(bytes.TrimRight(m.data[2:], "\x00"))
+		default:
+			return cc(completionCodeInvalidData)
+		}
+		return ok()
+	case m.netFn == NetFnChassis && m.cmd == CmdGetChassisStatus:
+		var power byte
+		if r.powerOn {
+			power = 0x01
+		}
+		var misc byte
+		if !r.noIdent {
+			misc = 0x40 | byte(r.identify)<<4
+		}
+		return ok(power, 0x00, misc)
+	case m.netFn == NetFnChassis && m.cmd == CmdChassisControl:
+		if len(m.data) < 1 {
+			return cc(completionCodeInvalidData)
+		}
+		switch ChassisControl(m.data[0]) {
+		case ChassisPowerDown, ChassisSoftShutdown:
+			r.powerOn = false
+		case ChassisPowerUp, ChassisPowerCycle, ChassisHardReset:
+			r.powerOn = true
+		default:
+			return cc(completionCodeInvalidData)
+		}
+		return ok()
+	case m.netFn == NetFnChassis && m.cmd == CmdChassisIdentify:
+		if r.noIdent {
+			return cc(completionCodeInvalidCommand)
+		}
+		switch {
+		case len(m.data) > 1 && m.data[1]&0x01 != 0:
+			r.identify = IdentifyIndefinite
+		case len(m.data) > 0 && m.data[0] == 0:
+			r.identify = IdentifyOff
+		default:
+			r.identify = IdentifyTemporary
+		}
+		return ok()
+	case m.netFn == NetFnTransport && m.cmd == CmdGetSOLConfigurationParameters:
+		if len(m.data) < 4 || m.data[1] != solParamEnable {
+			return cc(completionCodeOutOfRange)
+		}
+		var enabled byte
+		if r.sol {
+			enabled = 0x01
+		}
+		return ok(0x11, enabled)
+	case m.netFn == NetFnTransport && m.cmd == CmdGetLANConfigurationParameters:
+		if len(m.data) < 4 {
+			return cc(completionCodeInvalidData)
+		}
+		switch m.data[1] {
+		case lanParamCipherSuiteEntryCount:
+			return ok(0x11, byte(len(r.suites)))
+		case lanParamCipherSuiteEntries:
+			data := []byte{0x11, 0x00}
+			for _, csp := range r.suites {
+				data = append(data, byte(csp.ID))
+			}
+			return ok(data...)
+		case lanParamCipherSuitePrivileges:
+			data := make([]byte, 2+maxCipherSuiteEntries/2)
+			data[0] = 0x11
+			for i, csp := range r.suites {
+				data[2+i/2] |= byte(csp.MaxPrivilege) << (4 * (i % 2))
+			}
+			return ok(data...)
+		default:
+			return cc(completionCodeParameterNotSupported)
+		}
+	case m.netFn == NetFnTransport && m.cmd == CmdSetLANConfigurationParameters:
+		if len(m.data) < 2 {
+			return cc(completionCodeInvalidData)
+		}
+		if m.data[1] != lanParamCipherSuitePrivileges {
+			return cc(completionCodeParameterNotSupported)
+		}
+		if len(m.data) < 2+1+maxCipherSuiteEntries/2 {
+			return cc(completionCodeInvalidData)
+		}
+		for i := range r.suites {
+			r.suites[i].MaxPrivilege = PrivilegeLevel(m.data[3+i/2] >> (4 * (i % 2)) & 0x0f)
+		}
+		return ok()
+	case m.netFn == NetFnStorage && m.cmd == CmdGetFRUInventoryAreaInfo:
+		return ok(byte(len(r.fru)), byte(len(r.fru)>>8), 0x00)
+	case m.netFn == NetFnStorage && m.cmd == CmdReadFRUData:
+		if len(m.data) < 4 {
+			return cc(completionCodeInvalidData)
+		}
+		offset := int(binary.LittleEndian.Uint16(m.data[1:3]))
+		if offset >= len(r.fru) {
+			return cc(completionCodeOutOfRange)
+		}
+		end := min(offset+int(m.data[3]), len(r.fru))
+		return ok(append([]byte{byte(end - offset)}, r.fru[offset:end]...)...)
+	default:
+		return cc(completionCodeInvalidCommand)
+	}
+}
@@ -0,0 +1,511 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"slices"
+)
+
+const (
+	rmcpVersion   byte = 0x06
+	rmcpClassIPMI byte = 0x07
+
+	authTypeNone  byte = 0x00
+	authTypeRMCPP byte = 0x06
+
+	payloadTypeIPMI                byte = 0x00
+	payloadTypeOpenSessionRequest  byte = 0x10
+	payloadTypeOpenSessionResponse byte = 0x11
+	payloadTypeRAKP1               byte = 0x12
+	payloadTypeRAKP2               byte = 0x13
+	payloadTypeRAKP3               byte = 0x14
+	payloadTypeRAKP4               byte = 0x15
+
+	payloadEncrypted     byte = 0x80
+	payloadAuthenticated byte = 0x40
+	payloadTypeMask      byte = 0x3f
+)
+
+type authAlgorithm byte
+
+const (
+	authRAKPNone       authAlgorithm = 0x00
+	authRAKPHMACSHA1   authAlgorithm = 0x01
+	authRAKPHMACSHA256 authAlgorithm = 0x03
+)
+
+type integrityAlgorithm byte
+
+const (
+	integrityNone          integrityAlgorithm = 0x00
+	integrityHMACSHA1_96   integrityAlgorithm = 0x01
+	integrityHMACSHA256128 integrityAlgorithm = 0x04
+)
+
+type confidentialityAlgorithm byte
+
+const (
+	confidentialityNone      confidentialityAlgorithm = 0x00
+	confidentialityAESCBC128 confidentialityAlgorithm = 0x01
+)
+
+// CipherSuite is a combination of authentication, integrity and confidentiality algorithms as
+// defined in the IPMI v2.0 specification, table 22-20.
+type CipherSuite struct {
+	ID              int
+	auth            authAlgorithm
+	integrity       integrityAlgorithm
+	confidentiality confidentialityAlgorithm
+}
+
+// cipherSuites are the cipher suites this package implements.
+var cipherSuites = []CipherSuite{
+	{ID: 1, auth: authRAKPHMACSHA1, integrity: integrityNone, confidentiality: confidentialityNone},
+	{ID: 2, auth: authRAKPHMACSHA1, integrity: integrityHMACSHA1_96, confidentiality: confidentialityNone},
+	{ID: 3, auth: authRAKPHMACSHA1, integrity: integrityHMACSHA1_96, confidentiality: confidentialityAESCBC128},
+	{ID: 15, auth: authRAKPHMACSHA256, integrity: integrityNone, confidentiality: confidentialityNone},
+	{ID: 16, auth: authRAKPHMACSHA256, integrity: integrityHMACSHA256128, confidentiality: confidentialityNone},
+	{ID: 17, auth: authRAKPHMACSHA256, integrity: integrityHMACSHA256128, confidentiality: confidentialityAESCBC128},
+}
+
+// DefaultCipherSuite is RAKP-HMAC-SHA1, HMAC-SHA1-96 and AES-CBC-128, which nearly all BMCs support.
+const DefaultCipherSuite = 3
+
+// SupportedCipherSuites returns the IDs of the cipher suites this package implements.
+func SupportedCipherSuites() []int {
+	ids := make([]int, 0, len(cipherSuites))
+	for _, s := range cipherSuites {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// cipherSuitePreference orders the implemented cipher suites from the strongest to the weakest.
+var cipherSuitePreference = []int{17, 3, 16, 2, 15, 1}
+
+// StrongestCipherSuite returns the strongest implemented cipher suite of ids.
+func StrongestCipherSuite(ids []int) (int, error) {
+	for _, id := range cipherSuitePreference {
+		if slices.Contains(ids, id) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("none of the cipher suites %v is supported", ids)
+}
+
+// WeakCipherSuite reports whether a cipher suite lacks integrity protection or AES encryption, or
+// relies on MD2, MD5 or RC4. Only cipher suites 3 and 17 are considered secure.
+func WeakCipherSuite(id int) bool {
+	return id != 3 && id != 17
+}
+
+const (
+	cipherSuiteRecord    byte = 0xc0
+	cipherSuiteRecordOEM byte = 0xc1
+)
+
+// parseCipherSuiteRecords returns the cipher suite IDs of the records returned by Get Channel Cipher
+// Suites. Algorithm tags between the records are skipped.
+func parseCipherSuiteRecords(b []byte) ([]int, error) {
+	var ids []int
+	for i := 0; i < len(b); {
+		switch b[i] {
+		case cipherSuiteRecord:
+			if i+1 >= len(b) {
+				return nil, fmt.Errorf("cipher suite record is truncated")
+			}
+			ids = append(ids, int(b[i+1]))
+			i += 2
+		case cipherSuiteRecordOEM:
+			// The ID is followed by the IANA enterprise number.
+			if i+4 >= len(b) {
+				return nil, fmt.Errorf("cipher suite record is truncated")
+			}
+			ids = append(ids, int(b[i+1]))
+			i += 5
+		default:
+			i++
+		}
+	}
+	return ids, nil
+}
+
+func cipherSuiteByID(id int) (CipherSuite, error) {
+	i := slices.IndexFunc(cipherSuites, func(s CipherSuite) bool {
+		return s.ID == id
+	})
+	if i < 0 {
+		return CipherSuite{}, fmt.Errorf("cipher suite %d is not supported", id)
+	}
+	return cipherSuites[i], nil
+}
+
+func (a authAlgorithm) hash() func() hash.Hash {
+	switch a {
+	case authRAKPHMACSHA1:
+		return sha1.New
+	case authRAKPHMACSHA256:
+		return sha256.New
+	default:
+		return nil
+	}
+}
+
+// icvLength is the length of the integrity check value of RAKP message 4.
+func (a authAlgorithm) icvLength() int {
+	switch a {
+	case authRAKPHMACSHA1:
+		return 12
+	case authRAKPHMACSHA256:
+		return 16
+	default:
+		return 0
+	}
+}
+
+func (a integrityAlgorithm) authCodeLength() int {
+	switch a {
+	case integrityHMACSHA1_96:
+		return 12
+	case integrityHMACSHA256128:
+		return 16
+	default:
+		return 0
+	}
+}
+
+func (a integrityAlgorithm) hash() func() hash.Hash {
+	switch a {
+	case integrityHMACSHA1_96:
+		return sha1.New
+	case integrityHMACSHA256128:
+		return sha256.New
+	default:
+		return nil
+	}
+}
+
+func hmacSum(h func() hash.Hash, key []byte, data ...[]byte) []byte {
+	m := hmac.New(h, key)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// keys hold the session keys derived during the RAKP exchange.
+type keys struct {
+	suite CipherSuite
+	k1    []byte
+	k2    []byte
+}
+
+func newKeys(suite CipherSuite, sik []byte) *keys {
+	k := &keys{suite: suite}
+	h := suite.auth.hash()
+	if h == nil {
+		return k
+	}
+	size := h().Size()
+	k.k1 = hmacSum(h, sik, bytes.Repeat([]byte{0x01}, size))
+	k.k2 = hmacSum(h, sik, bytes.Repeat([]byte{0x02}, size))
+	return k
+}
+
+func (k *keys) authenticated() bool {
+	return k != nil && k.suite.integrity != integrityNone
+}
+
+func (k *keys) encrypted() bool {
+	return k != nil && k.suite.confidentiality != confidentialityNone
+}
+
+func (k *keys) authCode(b []byte) []byte {
+	return hmacSum(k.suite.integrity.hash(), k.k1, b)[:k.suite.integrity.authCodeLength()]
+}
+
+func (k *keys) seal(payload []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k.k2[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	n := aes.BlockSize - (len(payload)+1)%aes.BlockSize
+	if n == aes.BlockSize {
+		n = 0
+	}
+	data := slices.Clone(payload)
+	for i := 1; i <= n; i++ {
+		data = append(data, byte(i))
+	}
+	data = append(data, byte(n))
+
+	out := make([]byte, aes.BlockSize+len(data))
+	_, err = rand.Read(out[:aes.BlockSize])
+	if err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], data)
+	return out, nil
+}
+
+func (k *keys) open(payload []byte) ([]byte, error) {
+	if len(payload) < 2*aes.BlockSize || len(payload)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted payload has an invalid length: %d bytes", len(payload))
+	}
+	block, err := aes.NewCipher(k.k2[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, len(payload)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, payload[:aes.BlockSize]).CryptBlocks(data, payload[aes.BlockSize:])
+	n := int(data[len(data)-1])
+	if n >= len(data) {
+		return nil, fmt.Errorf("encrypted payload has an invalid pad length: %d", n)
+	}
+	return data[:len(data)-n-1], nil
+}
+
+// packet is an RMCP packet carrying either an IPMI v1.5 session-less message or an RMCP+ payload.
+type packet struct {
+	authType    byte
+	payloadType byte
+	sessionID   uint32
+	seq         uint32
+	payload     []byte
+	// authenticated and encrypted are set by decodePacket if the authentication code was verified
+	// and the payload was decrypted.
+	authenticated bool
+	encrypted     bool
+}
+
+func (p *packet) encode(k *keys) ([]byte, error) {
+	b := []byte{rmcpVersion, 0x00, 0xff, rmcpClassIPMI}
+
+	if p.authType == authTypeNone {
+		b = append(b, authTypeNone)
+		b = binary.LittleEndian.AppendUint32(b, p.seq)
+		b = binary.LittleEndian.AppendUint32(b, p.sessionID)
+		b = append(b, byte(len(p.payload)))
+		return append(b, p.payload...), nil
+	}
+
+	payloadType := p.payloadType
+	payload := p.payload
+	if p.payloadType == payloadTypeIPMI && k.encrypted() {
+		var err error
+		payload, err = k.seal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encrypt payload: %w", err)
+		}
+		payloadType |= payloadEncrypted
+	}
+	authenticated := p.payloadType == payloadTypeIPMI && k.authenticated()
+	if authenticated {
+		payloadType |= payloadAuthenticated
+	}
+
+	b = append(b, authTypeRMCPP, payloadType)
+	b = binary.LittleEndian.AppendUint32(b, p.sessionID)
+	b = binary.LittleEndian.AppendUint32(b, p.seq)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(payload)))
+	b = append(b, payload...)
+	if authenticated {
+		// The trailer pads the packet to a multiple of 4 bytes, not counting the RMCP header.
+		pad := (4 - (len(b)-4+2)%4) % 4
+		b = append(b, bytes.Repeat([]byte{0xff}, pad)...)
+		b = append(b, byte(pad), rmcpClassIPMI)
+		b = append(b, k.authCode(b[4:])...)
+	}
+	return b, nil
+}
+
+// decodePacket parses a packet. The keys are looked up by session ID to check and decrypt RMCP+
+// payloads of established sessions.
+func decodePacket(b []byte, keysFor func(sessionID uint32) *keys) (*packet, error) {
+	if len(b) < 5 || b[0] != rmcpVersion || b[3] != rmcpClassIPMI {
+		return nil, fmt.Errorf("packet is not an IPMI message")
+	}
+
+	p := &packet{authType: b[4]}
+	switch p.authType {
+	case authTypeNone:
+		if len(b) < 14 || len(b) < 14+int(b[13]) {
+			return nil, fmt.Errorf("packet is too short: %d bytes", len(b))
+		}
+		p.seq = binary.LittleEndian.Uint32(b[5:9])
+		p.sessionID = binary.LittleEndian.Uint32(b[9:13])
+		p.payload = b[14 : 14+int(b[13])]
+		return p, nil
+	case authTypeRMCPP:
+	default:
+		return nil, fmt.Errorf("packet has an unsupported authentication type: 0x%02x", p.authType)
+	}
+
+	if len(b) < 16 {
+		return nil, fmt.Errorf("packet is too short: %d bytes", len(b))
+	}
+	payloadType := b[5]
+	p.payloadType = payloadType & payloadTypeMask
+	p.sessionID = binary.LittleEndian.Uint32(b[6:10])
+	p.seq = binary.LittleEndian.Uint32(b[10:14])
+	n := int(binary.LittleEndian.Uint16(b[14:16]))
+	if len(b) < 16+n {
+		return nil, fmt.Errorf("packet is too short: %d bytes", len(b))
+	}
+	p.payload = b[16 : 16+n]
+
+	k := keysFor(p.sessionID)
+	if p.payloadType == payloadTypeIPMI {
+		// A session with integrity or confidentiality only accepts packets that use them, since
+		// anyone who can send UDP packets to the console could forge others.
+		if k.authenticated() && payloadType&payloadAuthenticated == 0 {
+			return nil, fmt.Errorf("packet is not authenticated, but the session has an integrity algorithm")
+		}
+		if k.encrypted() && payloadType&payloadEncrypted == 0 {
+			return nil, fmt.Errorf("packet is not encrypted, but the session has a confidentiality algorithm")
+		}
+	}
+	if payloadType&(payloadAuthenticated|payloadEncrypted) == 0 {
+		return p, nil
+	}
+	if k == nil {
+		return nil, fmt.Errorf("packet belongs to an unknown session: 0x%08x", p.sessionID)
+	}
+
+	if payloadType&payloadAuthenticated != 0 {
+		if !k.authenticated() {
+			return nil, fmt.Errorf("packet is authenticated, but the session has no integrity algorithm")
+		}
+		l := k.suite.integrity.authCodeLength()
+		if len(b) < 16+n+2+l {
+			return nil, fmt.Errorf("packet is too short: %d bytes", len(b))
+		}
+		signed := b[4 : len(b)-l]
+		if !hmac.Equal(k.authCode(signed), b[len(b)-l:]) {
+			return nil, fmt.Errorf("packet has an invalid authentication code")
+		}
+		p.authenticated = true
+	}
+
+	if payloadType&payloadEncrypted != 0 {
+		if !k.encrypted() {
+			return nil, fmt.Errorf("packet is encrypted, but the session has no confidentiality algorithm")
+		}
+		var err error
+		p.payload, err = k.open(p.payload)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt payload: %w", err)
+		}
+		p.encrypted = true
+	}
+	return p, nil
+}
+
+// rakp holds the values both sides of a session exchange in RAKP messages 1 to 4.
+type rakp struct {
+	suite         CipherSuite
+	consoleSID    uint32
+	managedSID    uint32
+	consoleRandom []byte
+	managedRandom []byte
+	managedGUID   []byte
+	role          byte
+	username      []byte
+	password      []byte
+}
+
+func le32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+func (r *rakp) nameFields() []byte {
+	return append([]byte{r.role, byte(len(r.username))}, r.username...)
+}
+
+// rakp2AuthCode is the key exchange authentication code of RAKP message 2.
+func (r *rakp) rakp2AuthCode() []byte {
+	return hmacSum(r.suite.auth.hash(), r.password, le32(r.consoleSID), le32(r.managedSID), r.consoleRandom, r.managedRandom, r.managedGUID, r.nameFields())
+}
+
+// rakp3AuthCode is the key exchange authentication code of RAKP message 3.
+func (r *rakp) rakp3AuthCode() []byte {
+	return hmacSum(r.suite.auth.hash(), r.password, r.managedRandom, le32(r.consoleSID), r.nameFields())
+}
+
+// sik is the session integrity key. Without a BMC key, the password is used to generate it.
+func (r *rakp) sik() []byte {
+	return hmacSum(r.suite.auth.hash(), r.password, r.consoleRandom, r.managedRandom, r.nameFields())
+}
+
+// rakp4ICV is the integrity check value of RAKP message 4.
+func (r *rakp) rakp4ICV() []byte {
+	return hmacSum(r.suite.auth.hash(), r.sik(), r.consoleRandom, le32(r.managedSID), r.managedGUID)[:r.suite.auth.icvLength()]
+}
+
+func (r *rakp) keys() *keys {
+	return newKeys(r.suite, r.sik())
+}
+
+// algorithmPayloads encodes the authentication, integrity and confidentiality payloads of the open
+// session messages.
+func (s CipherSuite) algorithmPayloads() []byte {
+	var b []byte
+	for i, alg := range []byte{byte(s.auth), byte(s.integrity), byte(s.confidentiality)} {
+		b = append(b, byte(i), 0x00, 0x00, 0x08, alg, 0x00, 0x00, 0x00)
+	}
+	return b
+}
+
+func parseAlgorithmPayloads(b []byte) (authAlgorithm, integrityAlgorithm, confidentialityAlgorithm, error) {
+	if len(b) < 24 || b[0] != 0x00 || b[8] != 0x01 || b[16] != 0x02 {
+		return 0, 0, 0, fmt.Errorf("invalid algorithm payloads")
+	}
+	return authAlgorithm(b[4] & 0x3f), integrityAlgorithm(b[12] & 0x3f), confidentialityAlgorithm(b[20] & 0x3f), nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	return b, err
+}
+
+// rmcpStatus describes the status codes of the RMCP+ open session and RAKP messages.
+func rmcpStatus(code byte) string {
+	switch code {
+	case 0x01:
+		return "insufficient resources to create a session"
+	case 0x02:
+		return "invalid session ID"
+	case 0x04:
+		return "invalid authentication algorithm"
+	case 0x05:
+		return "invalid integrity algorithm"
+	case 0x09:
+		return "invalid role"
+	case 0x0a:
+		return "unauthorized role or privilege level requested"
+	case 0x0d:
+		return "unauthorized name"
+	case 0x0f:
+		return "invalid integrity check value"
+	case 0x10:
+		return "invalid confidentiality algorithm"
+	case 0x11:
+		return "no cipher suite match with proposed security algorithms"
+	default:
+		return fmt.Sprintf("status 0x%02x", code)
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cipher suites", func() {
+	It("should parse standard and OEM cipher suite records", func() {
+		Expect(parseCipherSuiteRecords([]byte{
+			0xc0, 0x00, 0x00, 0x40, 0x80,
+			0xc0, 0x11, 0x03, 0x44, 0x81,
+			0xc1, 0x80, 0xa2, 0x02, 0x00, 0x01, 0x41, 0x81,
+		})).To(Equal([]int{0, 17, 128}))
+
+		_, err := parseCipherSuiteRecords([]byte{0xc0, 0x03, 0x01, 0x41, 0x81, 0xc0})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should prefer encrypted cipher suites", func() {
+		Expect(StrongestCipherSuite([]int{1, 2, 16})).To(Equal(16))
+		Expect(StrongestCipherSuite([]int{0, 16, 3})).To(Equal(3))
+		_, err := StrongestCipherSuite([]int{0, 6})
+		Expect(err).To(HaveOccurred())
+
+		Expect(WeakCipherSuite(0)).To(BeTrue())
+		Expect(WeakCipherSuite(16)).To(BeTrue())
+		Expect(WeakCipherSuite(17)).To(BeFalse())
+	})
+})
@@ -0,0 +1,424 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	defaultTimeout = 2 * time.Second
+	defaultRetries = 2
+	maxUsername    = 16
+	// lookupNameOnly selects the user by name only, not by name and privilege level, during RAKP.
+	lookupNameOnly byte = 0x10
+)
+
+// Config configures a session.
+type Config struct {
+	Username string
+	Password string
+	// CipherSuite defaults to DefaultCipherSuite.
+	CipherSuite int
+	// Privilege defaults to PrivilegeAdministrator.
+	Privilege PrivilegeLevel
+	// Timeout applies to each attempt of a request and defaults to 2 seconds.
+	Timeout time.Duration
+	// Retries is the number of times a request is resent without an answer.
+	Retries int
+}
+
+// Session is an authenticated RMCP+ session with a BMC. It is not safe for concurrent use.
+type Session struct {
+	conn       net.Conn
+	cfg        Config
+	consoleSID uint32
+	managedSID uint32
+	keys       *keys
+	seq        uint32
+	rqSeq      byte
+}
+
+// Open establishes an RMCP+ session with the BMC at addr and raises it to the configured privilege
+// level.
+func Open(ctx context.Context, addr string, cfg Config) (*Session, error) {
+	if cfg.CipherSuite == 0 {
+		cfg.CipherSuite = DefaultCipherSuite
+	}
+	if cfg.Privilege == 0 {
+		cfg.Privilege = PrivilegeAdministrator
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = defaultRetries
+	}
+	if len(cfg.Username) > maxUsername {
+		return nil, fmt.Errorf("username is longer than %d bytes", maxUsername)
+	}
+
+	suite, err := cipherSuiteByID(cfg.CipherSuite)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect: %w", err)
+	}
+
+	s := &Session{
+		conn: conn,
+		cfg:  cfg,
+	}
+	err = s.open(ctx, suite)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if cfg.Privilege > PrivilegeUser {
+		_, err = s.Send(ctx, NetFnApp, CmdSetSessionPrivilegeLevel, []byte{byte(cfg.Privilege)})
+		if err != nil {
+			_ = s.Close(ctx)
+			return nil, fmt.Errorf("cannot set session privilege level: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Session) open(ctx context.Context, suite CipherSuite) error {
+	sid, err := randomBytes(4)
+	if err != nil {
+		return err
+	}
+	s.consoleSID = binary.LittleEndian.Uint32(sid) | 1
+
+	req := []byte{0x00, 0x00, 0x00, 0x00}
+	req = append(req, le32(s.consoleSID)...)
+	req = append(req, suite.algorithmPayloads()...)
+	resp, err := s.exchange(ctx, payloadTypeOpenSessionRequest, req, payloadTypeOpenSessionResponse)
+	if err != nil {
+		return fmt.Errorf("cannot open session: %w", err)
+	}
+	if len(resp) < 2 {
+		return fmt.Errorf("cannot open session: response is too short")
+	}
+	if resp[1] != 0x00 {
+		return fmt.Errorf("cannot open session: %s", rmcpStatus(resp[1]))
+	}
+	if len(resp) < 36 || binary.LittleEndian.Uint32(resp[4:8]) != s.consoleSID {
+		return fmt.Errorf("cannot open session: invalid response")
+	}
+	s.managedSID = binary.LittleEndian.Uint32(resp[8:12])
+	auth, integrity, confidentiality, err := parseAlgorithmPayloads(resp[12:36])
+	if err != nil {
+		return fmt.Errorf("cannot open session: %w", err)
+	}
+	if auth != suite.auth || integrity != suite.integrity || confidentiality != suite.confidentiality {
+		return fmt.Errorf("cannot open session: BMC selected different algorithms than cipher suite %d", suite.ID)
+	}
+
+	r := &rakp{
+		suite:      suite,
+		consoleSID: s.consoleSID,
+		managedSID: s.managedSID,
+		role:       lookupNameOnly | byte(s.cfg.Privilege),
+		username:   []byte(s.cfg.Username),
+		password:   []byte(s.cfg.Password),
+	}
+	r.consoleRandom, err = randomBytes(16)
+	if err != nil {
+		return err
+	}
+
+	req = []byte{0x00, 0x00, 0x00, 0x00}
+	req = append(req, le32(s.managedSID)...)
+	req = append(req, r.consoleRandom...)
+	req = append(req, r.role, 0x00, 0x00, byte(len(r.username)))
+	req = append(req, r.username...)
+	resp, err = s.exchange(ctx, payloadTypeRAKP1, req, payloadTypeRAKP2)
+	if err != nil {
+		return fmt.Errorf("cannot send RAKP message 1: %w", err)
+	}
+	if len(resp) < 2 {
+		return fmt.Errorf("RAKP message 2 is too short")
+	}
+	switch resp[1] {
+	case 0x00:
+	case 0x0d:
+		return fmt.Errorf("%w: %s", ErrAuthentication, rmcpStatus(resp[1]))
+	default:
+		return fmt.Errorf("RAKP message 2 reports %s", rmcpStatus(resp[1]))
+	}
+	h := suite.auth.hash()
+	if len(resp) < 40+h().Size() || binary.LittleEndian.Uint32(resp[4:8]) != s.consoleSID {
+		return fmt.Errorf("RAKP message 2 is invalid")
+	}
+	r.managedRandom = resp[8:24]
+	r.managedGUID = resp[24:40]
+	if !hmac.Equal(r.rakp2AuthCode(), resp[40:40+h().Size()]) {
+		return fmt.Errorf("%w: RAKP message 2 has an invalid authentication code", ErrAuthentication)
+	}
+
+	req = []byte{0x00, 0x00, 0x00, 0x00}
+	req = append(req, le32(s.managedSID)...)
+	req = append(req, r.rakp3AuthCode()...)
+	resp, err = s.exchange(ctx, payloadTypeRAKP3, req, payloadTypeRAKP4)
+	if err != nil {
+		return fmt.Errorf("cannot send RAKP message 3: %w", err)
+	}
+	if len(resp) < 2 {
+		return fmt.Errorf("RAKP message 4 is too short")
+	}
+	switch resp[1] {
+	case 0x00:
+	case 0x0f:
+		return fmt.Errorf("%w: %s", ErrAuthentication, rmcpStatus(resp[1]))
+	default:
+		return fmt.Errorf("RAKP message 4 reports %s", rmcpStatus(resp[1]))
+	}
+	l := suite.auth.icvLength()
+	if len(resp) < 8+l || !hmac.Equal(r.rakp4ICV(), resp[8:8+l]) {
+		return fmt.Errorf("RAKP message 4 has an invalid integrity check value")
+	}
+
+	s.keys = r.keys()
+	return nil
+}
+
+// exchange sends a session setup payload and waits for the matching response.
+func (s *Session) exchange(ctx context.Context, payloadType byte, payload []byte, respType byte) ([]byte, error) {
+	p := &packet{
+		authType:    authTypeRMCPP,
+		payloadType: payloadType,
+		payload:     payload,
+	}
+	resp, err := s.roundTrip(ctx, p, func(r *packet) bool {
+		return r.payloadType == respType && len(r.payload) > 0 && r.payload[0] == payload[0]
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.payload, nil
+}
+
+// Send sends a request and returns the response data without the completion code. A completion code
+// other than success is returned as a CompletionCodeError.
+func (s *Session) Send(ctx context.Context, netFn NetFn, cmd Command, data []byte) ([]byte, error) {
+	s.rqSeq = (s.rqSeq + 1) & 0x3f
+	s.seq++
+	req := &message{
+		rsAddr: bmcAddress,
+		netFn:  netFn,
+		rqAddr: remoteConsoleAddress,
+		rqSeq:  s.rqSeq,
+		cmd:    cmd,
+		data:   data,
+	}
+	p := &packet{
+		authType:    authTypeRMCPP,
+		payloadType: payloadTypeIPMI,
+		sessionID:   s.managedSID,
+		seq:         s.seq,
+		payload:     req.encode(),
+	}
+
+	var msg *message
+	_, err := s.roundTrip(ctx, p, func(r *packet) bool {
+		if r.payloadType != payloadTypeIPMI || r.sessionID != s.consoleSID {
+			return false
+		}
+		if s.keys.authenticated() && !r.authenticated || s.keys.encrypted() && !r.encrypted {
+			return false
+		}
+		m, err := decodeMessage(r.payload)
+		if err != nil || m.netFn != netFn+1 || m.cmd != cmd || m.rqSeq != req.rqSeq {
+			return false
+		}
+		msg = m
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot send IPMI command 0x%02x/0x%02x: %w", byte(netFn), byte(cmd), err)
+	}
+	if len(msg.data) < 1 {
+		return nil, fmt.Errorf("response to IPMI command 0x%02x/0x%02x has no completion code", byte(netFn), byte(cmd))
+	}
+	if msg.data[0] != completionCodeOK {
+		return nil, &CompletionCodeError{NetFn: netFn, Cmd: cmd, Code: msg.data[0]}
+	}
+	return msg.data[1:], nil
+}
+
+// Close closes the session on the BMC and releases the connection.
+func (s *Session) Close(ctx context.Context) error {
+	_, err := s.Send(ctx, NetFnApp, CmdCloseSession, le32(s.managedSID))
+	cerr := s.conn.Close()
+	if err != nil {
+		return fmt.Errorf("cannot close session: %w", err)
+	}
+	return cerr
+}
+
+// roundTrip sends a packet until a response is accepted or the retries are exhausted.
+func (s *Session) roundTrip(ctx context.Context, p *packet, accept func(*packet) bool) (*packet, error) {
+	b, err := p.encode(s.keys)
+	if err != nil {
+		return nil, err
+	}
+	return roundTrip(ctx, s.conn, b, s.cfg.Timeout, s.cfg.Retries, s.keysFor, accept)
+}
+
+func (s *Session) keysFor(sessionID uint32) *keys {
+	if sessionID != s.consoleSID {
+		return nil
+	}
+	return s.keys
+}
+
+func roundTrip(ctx context.Context, conn net.Conn, b []byte, timeout time.Duration, retries int, keysFor func(uint32) *keys, accept func(*packet) bool) (*packet, error) {
+	buf := make([]byte, 1024)
+	for attempt := 0; attempt <= retries; attempt++ {
+		_, err := conn.Write(b)
+		if err != nil {
+			return nil, fmt.Errorf("cannot send packet: %w", err)
+		}
+
+		deadline := time.Now().Add(timeout)
+		ctxDeadline, ok := ctx.Deadline()
+		if ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+		err = conn.SetReadDeadline(deadline)
+		if err != nil {
+			return nil, fmt.Errorf("cannot set deadline: %w", err)
+		}
+
+		for {
+			var n int
+			n, err = conn.Read(buf)
+			if err != nil {
+				break
+			}
+			var p *packet
+			p, err = decodePacket(bytes.Clone(buf[:n]), keysFor)
+			if err == nil && accept(p) {
+				return p, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, fmt.Errorf("cannot receive packet: %w", err)
+		}
+	}
+	return nil, ErrNoResponse
+}
+
+// Ping checks that the BMC at addr supports IPMI v2.0 by requesting its channel authentication
+// capabilities outside a session.
+func Ping(ctx context.Context, addr string, timeout time.Duration) error {
+	// Bit 7 requests IPMI v2.0 extended data.
+	data, err := sessionless(ctx, addr, timeout, 0, CmdGetChannelAuthCapabilities, []byte{0x80 | currentChannel, byte(PrivilegeAdministrator)})
+	if err != nil {
+		return err
+	}
+	if len(data) < 8 {
+		return fmt.Errorf("response is too short: %d bytes", len(data))
+	}
+	if data[1]&0x80 == 0 || data[3]&0x02 == 0 {
+		return fmt.Errorf("BMC does not support IPMI v2.0")
+	}
+	return nil
+}
+
+// maxCipherSuiteListIndex limits the number of 16 byte chunks of cipher suite records.
+const maxCipherSuiteListIndex = 0x40
+
+// GetChannelCipherSuites returns the IDs of the cipher suites the BMC at addr supports for IPMI
+// messages. It is requested outside a session, so that a cipher suite can be chosen before opening
+// one. A zero timeout defaults to 2 seconds.
+func GetChannelCipherSuites(ctx context.Context, addr string, timeout time.Duration) ([]int, error) {
+	var records []byte
+	for i := byte(0); i < maxCipherSuiteListIndex; i++ {
+		// Bit 7 lists the algorithms by cipher suite.
+		data, err := sessionless(ctx, addr, timeout, defaultRetries, CmdGetChannelCipherSuites, []byte{currentChannel, payloadTypeIPMI, 0x80 | i})
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < 1 {
+			return nil, fmt.Errorf("response is too short: %d bytes", len(data))
+		}
+		records = append(records, data[1:]...)
+		if len(data) < 17 {
+			break
+		}
+	}
+	return parseCipherSuiteRecords(records)
+}
+
+// sessionless sends a request outside of a session and returns the response data without the
+// completion code.
+func sessionless(ctx context.Context, addr string, timeout time.Duration, retries int, cmd Command, data []byte) ([]byte, error) {
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	req := &message{
+		rsAddr: bmcAddress,
+		netFn:  NetFnApp,
+		rqAddr: remoteConsoleAddress,
+		cmd:    cmd,
+		data:   data,
+	}
+	p := &packet{
+		authType: authTypeNone,
+		payload:  req.encode(),
+	}
+	b, err := p.encode(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *message
+	_, err = roundTrip(ctx, conn, b, timeout, retries, func(uint32) *keys { return nil }, func(r *packet) bool {
+		m, err := decodeMessage(r.payload)
+		if err != nil || m.cmd != cmd {
+			return false
+		}
+		msg = m
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.data) < 1 {
+		return nil, fmt.Errorf("response has no completion code")
+	}
+	if msg.data[0] != completionCodeOK {
+		return nil, &CompletionCodeError{NetFn: NetFnApp, Cmd: cmd, Code: msg.data[0]}
+	}
+	return msg.data[1:], nil
+}
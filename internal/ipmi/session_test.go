@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and IronCore contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package ipmi
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Session", func() {
+	var r *Responder
+
+	BeforeEach(func() {
+		var err error
+		r, err = NewResponder("admin", "password")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(r.Close)
+	})
+
+	DescribeTable("should open a session with every supported cipher suite",
+		func(ctx SpecContext, suite int) {
+			s, err := Open(ctx, r.Addr(), Config{
+				Username:    "admin",
+				Password:    "password",
+				CipherSuite: suite,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			id, err := s.GetDeviceID(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id.FirmwareRevision()).To(Equal("1.23"))
+			Expect(s.Close(ctx)).To(Succeed())
+		},
+		Entry("RAKP-HMAC-SHA1", 1),
+		Entry("RAKP-HMAC-SHA1, HMAC-SHA1-96", 2),
+		Entry("RAKP-HMAC-SHA1, HMAC-SHA1-96, AES-CBC-128", 3),
+		Entry("RAKP-HMAC-SHA256", 15),
+		Entry("RAKP-HMAC-SHA256, HMAC-SHA256-128", 16),
+		Entry("RAKP-HMAC-SHA256, HMAC-SHA256-128, AES-CBC-128", 17),
+	)
+
+	It("should reject wrong credentials", func(ctx SpecContext) {
+		_, err := Open(ctx, r.Addr(), Config{Username: "admin", Password: "wrong"})
+		Expect(err).To(MatchError(ErrAuthentication))
+
+		_, err = Open(ctx, r.Addr(), Config{Username: "nobody", Password: "password"})
+		Expect(err).To(MatchError(ErrAuthentication))
+	})
+
+	It("should fail if the BMC does not support the cipher suite", func(ctx SpecContext) {
+		r.SetCipherSuites(3)
+		_, err := Open(ctx, r.Addr(), Config{Username: "admin", Password: "password", CipherSuite: 17})
+		Expect(err).To(MatchError(ContainSubstring("no cipher suite match")))
+	})
+
+	It("should time out if the BMC does not answer", func(ctx SpecContext) {
+		c, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(c.Close)
+
+		_, err = Open(ctx, c.LocalAddr().String(), Config{
+			Username: "admin",
+			Password: "password",
+			Timeout:  50 * time.Millisecond,
+			Retries:  1,
+		})
+		Expect(err).To(MatchError(ErrNoResponse))
+	})
+
+	DescribeTable("should ignore replies without the integrity or confidentiality of the session",
+		func(ctx SpecContext, suite int) {
+			s, err := Open(ctx, r.Addr(), Config{
+				Username:    "admin",
+				Password:    "password",
+				CipherSuite: suite,
+				Timeout:     50 * time.Millisecond,
+				Retries:     1,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Injecting plaintext replies")
+			r.SetInsecureReplies(true)
+			_, err = s.GetDeviceID(ctx)
+			Expect(err).To(MatchError(ErrNoResponse))
+
+			r.SetInsecureReplies(false)
+			Expect(s.Close(ctx)).To(Succeed())
+		},
+		Entry("HMAC-SHA1-96", 2),
+		Entry("HMAC-SHA1-96, AES-CBC-128", 3),
+		Entry("HMAC-SHA256-128", 16),
+		Entry("HMAC-SHA256-128, AES-CBC-128", 17),
+	)
+
+	It("should read the GUID, FRU and chassis status", func(ctx SpecContext) {
+		r.SetFRU(FRU{
+			BoardManufacturer:   "Board Inc.",
+			ProductManufacturer: "Vendor Inc.",
+			ProductName:         "Server",
+			ProductPartNumber:   "SKU-1",
+			ProductSerialNumber: "SN-1",
+		})
+		r.SetPower(true)
+
+		s, err := Open(ctx, r.Addr(), Config{Username: "admin", Password: "password"})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(s.Close)
+
+		guid, err := s.GetSystemGUID(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(guid).To(Equal("01234567-89ab-cdef-0123-456789abcdef"))
+
+		b, err := s.ReadFRU(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+		fru, err := ParseFRU(b)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fru.BoardManufacturer).To(Equal("Board Inc."))
+		Expect(fru.ProductManufacturer).To(Equal("Vendor Inc."))
+		Expect(fru.ProductPartNumber).To(Equal("SKU-1"))
+		Expect(fru.ProductSerialNumber).To(Equal("SN-1"))
+
+		status, err := s.GetChassisStatus(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.PowerOn).To(BeTrue())
+
+		Expect(s.ChassisControl(ctx, ChassisPowerDown)).To(Succeed())
+		Expect(r.Power()).To(BeFalse())
+	})
+
+	It("should manage users", func(ctx SpecContext) {
+		s, err := Open(ctx, r.Addr(), Config{Username: "admin", Password: "password"})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(s.Close)
+
+		Expect(s.SetUserName(ctx, 3, "metal-abc")).To(Succeed())
+		Expect(s.SetUserPassword(ctx, 3, "a-password-of-20-byte")).NotTo(Succeed())
+		Expect(s.SetUserPassword(ctx, 3, "a-password-of-20-byt")).To(Succeed())
+		Expect(s.SetUserAccess(ctx, 3, PrivilegeAdministrator)).To(Succeed())
+		Expect(s.EnableUser(ctx, 3, true)).To(Succeed())
+
+		name, err := s.GetUserName(ctx, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("metal-abc"))
+		access, err := s.GetUserAccess(ctx, 3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(access).To(Equal(UserAccess{MaxUsers: 10, Enabled: true, Privilege: PrivilegeAdministrator}))
+
+		By("Opening a session as the new user")
+		ns, err := Open(ctx, r.Addr(), Config{Username: "metal-abc", Password: "a-password-of-20-byt"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns.Close(ctx)).To(Succeed())
+
+		By("Disabling the new user")
+		Expect(s.EnableUser(ctx, 3, false)).To(Succeed())
+		_, err = Open(ctx, r.Addr(), Config{Username: "metal-abc", Password: "a-password-of-20-byt"})
+		Expect(err).To(MatchError(ErrAuthentication))
+	})
+
+	It("should answer pings", func(ctx SpecContext) {
+		Expect(Ping(ctx, r.Addr(), time.Second)).To(Succeed())
+	})
+
+	It("should list and disable cipher suites", func(ctx SpecContext) {
+		By("Listing the cipher suites outside a session")
+		ids, err := GetChannelCipherSuites(ctx, r.Addr(), time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{1, 2, 3, 15, 16, 17}))
+		Expect(StrongestCipherSuite(ids)).To(Equal(17))
+
+		r.SetCipherSuites(0, 1, 2, 3)
+		ids, err = GetChannelCipherSuites(ctx, r.Addr(), time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]int{0, 1, 2, 3}))
+		Expect(StrongestCipherSuite(ids)).To(Equal(3))
+
+		By("Disabling the weak cipher suites")
+		s, err := Open(ctx, r.Addr(), Config{Username: "admin", Password: "password", CipherSuite: 3})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(s.Close)
+		csps, err := s.GetCipherSuitePrivileges(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(csps).To(HaveLen(4))
+		Expect(csps[1]).To(Equal(CipherSuitePrivilege{ID: 1, MaxPrivilege: PrivilegeAdministrator}))
+		for i := range csps {
+			if WeakCipherSuite(csps[i].ID) {
+				csps[i].MaxPrivilege = PrivilegeUnspecified
+			}
+		}
+		Expect(s.SetCipherSuitePrivileges(ctx, csps)).To(Succeed())
+		Expect(r.CipherSuites()).To(Equal(csps))
+
+		_, err = Open(ctx, r.Addr(), Config{Username: "admin", Password: "password", CipherSuite: 1})
+		Expect(err).To(MatchError(ContainSubstring("no cipher suite match")))
+	})
+})
@@ -66,10 +66,14 @@ func SetCondition(conds []metav1.Condition, cond metav1.Condition) ([]metav1.Con
 }
 
 func SetErrorCondition(conds []metav1.Condition, typ string, err error) ([]metav1.Condition, bool) {
+	return SetErrorConditionWithReason(conds, typ, "Error", err)
+}
+
+func SetErrorConditionWithReason(conds []metav1.Condition, typ, reason string, err error) ([]metav1.Condition, bool) {
 	return SetCondition(conds, metav1.Condition{
 		Type:    typ,
 		Status:  metav1.ConditionFalse,
-		Reason:  "Error",
+		Reason:  reason,
 		Message: err.Error(),
 	})
 }